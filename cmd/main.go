@@ -15,11 +15,17 @@ import (
 	"github.com/dinizgab/booking-mvp/internal/auth"
 	"github.com/dinizgab/booking-mvp/internal/config"
 	"github.com/dinizgab/booking-mvp/internal/database"
+	"github.com/dinizgab/booking-mvp/internal/entity"
+	"github.com/dinizgab/booking-mvp/internal/gateway/card"
+	"github.com/dinizgab/booking-mvp/internal/gateway/fake"
 	"github.com/dinizgab/booking-mvp/internal/gateway/openpix"
 	"github.com/dinizgab/booking-mvp/internal/gateway/openpix/webhooks"
 	"github.com/dinizgab/booking-mvp/internal/handlers"
+	"github.com/dinizgab/booking-mvp/internal/jobs"
+	"github.com/dinizgab/booking-mvp/internal/ports"
 	"github.com/dinizgab/booking-mvp/internal/repository"
 	"github.com/dinizgab/booking-mvp/internal/services/notification"
+	"github.com/dinizgab/booking-mvp/internal/services/pricing"
 	"github.com/dinizgab/booking-mvp/internal/usecase"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -52,24 +58,66 @@ func main() {
 	}
 
 	pixGatewayClient := openpix.NewOpenPixClient(cfg.OpenPix)
+	webhookVerifier, err := webhooks.NewSignatureVerifier(cfg.OpenPix)
+	if err != nil {
+		log.Fatalf("Failed to create webhook signature verifier: %v", err)
+	}
 	emailService := notification.NewEmailSender(emailRenderer, cfg.SMTP)
 	storageUploadService := storage.NewSupabaseStorageUploader(cfg.Storage, "court-photos")
+	pricingEngine := pricing.NewEngine()
+	feeCalculator := pricing.NewFeeCalculator()
 
 	companyRepository := repository.NewCompanyRepository(db)
 	courtRepository := repository.NewCourtRepository(db)
 	bookingRepository := repository.NewBookingRepository(db)
 	paymentRepository := repository.NewPaymentRepository(db)
+	webhookEventRepository := repository.NewWebhookEventRepository(db)
+	outboxRepository := repository.NewOutboxRepository(db)
+	ledgerRepository := repository.NewLedgerRepository(db)
+	commissionPlanRepository := repository.NewCommissionPlanRepository(db)
+	operatorRepository := repository.NewOperatorRepository(db)
+	userRepository := repository.NewUserRepository(db)
 
-	pixPaymentUsecase := usecase.NewPixGatewayService(
-		pixGatewayClient,
-		bookingRepository,
+	paymentGateways := []ports.PaymentGateway{
+		openpix.NewGateway(pixGatewayClient),
+		card.NewGateway(cfg.Card),
+	}
+	if cfg.API.FakePayments {
+		log.Println("Using in-memory payment gateways")
+		paymentGateways = []ports.PaymentGateway{
+			fake.NewGateway(entity.ProviderOpenPix),
+			fake.NewGateway(entity.ProviderCard),
+		}
+	}
+
+	paymentUsecase := usecase.NewPaymentUsecase(
+		paymentGateways,
 		bookingRepository,
 		paymentRepository,
-		emailService,
+		feeCalculator,
+		commissionPlanRepository,
+		pricing.NewRefundCalculator(),
 	)
-	courtUsecase := usecase.NewCourtUseCase(courtRepository, storageUploadService)
-	companyUsecase := usecase.NewCompanyUsecase(companyRepository, authService, pixPaymentUsecase)
-	bookingUsecase := usecase.NewBookingUsecase(bookingRepository, pixPaymentUsecase, companyUsecase, courtUsecase)
+	outboxUsecase := usecase.NewOutboxUsecase(outboxRepository, emailService)
+	courtUsecase := usecase.NewCourtUseCase(courtRepository, storageUploadService, pricingEngine, feeCalculator, commissionPlanRepository)
+	companyUsecase := usecase.NewCompanyUsecase(companyRepository, userRepository, authService, paymentUsecase)
+	userUsecase := usecase.NewUserUsecase(userRepository, companyRepository, authService, emailService)
+	webhookUsecase := usecase.NewWebhookUsecase(webhookEventRepository, paymentUsecase, paymentGateways)
+	bookingUsecase := usecase.NewBookingUsecase(bookingRepository, paymentUsecase, companyUsecase, courtUsecase, pricingEngine)
+	reconciliationUsecase := usecase.NewReconciliationUsecase(paymentGateways, paymentRepository, paymentUsecase)
+	statementUsecase := usecase.NewStatementUsecase(ledgerRepository)
+	commissionUsecase := usecase.NewCommissionUsecase(commissionPlanRepository)
+	operatorUsecase := usecase.NewOperatorUsecase(operatorRepository, authService)
+
+	jobRunner := jobs.NewRunner(db)
+	jobRunner.Register(jobs.NewReleaseHoldsJob(bookingUsecase))
+	jobRunner.Register(jobs.NewExpirePaymentsJob(paymentUsecase))
+	jobRunner.Register(jobs.NewDispatchOutboxJob(outboxUsecase))
+	jobRunner.Register(jobs.NewReconcileRefundsJob(paymentUsecase))
+	jobRunner.Register(jobs.NewReconcilePaymentsJob(reconciliationUsecase))
+	jobRunner.Register(jobs.NewReconcileDashboardRefundsJob(reconciliationUsecase))
+	jobRunner.Register(jobs.NewReconcileWithdrawalsJob(paymentUsecase))
+	jobRunner.Register(jobs.NewScheduledPayoutsJob(paymentUsecase))
 
 	router := gin.Default()
 
@@ -84,50 +132,130 @@ func main() {
 	}))
 
 	router.POST("/auth/signup", handlers.CreateNewCompany(companyUsecase))
-	router.POST("/auth/login", handlers.LoginCompany(companyUsecase))
+	router.POST("/auth/login", handlers.LoginUser(userUsecase))
+	router.POST("/auth/invitations/accept", handlers.AcceptInvitation(userUsecase))
+
+	manageBookings := auth.Require(entity.PermissionManageBookings)
+	manageCourts := auth.Require(entity.PermissionManageCourts)
+	viewFinances := auth.Require(entity.PermissionViewFinances)
+	withdraw := auth.Require(entity.PermissionWithdraw)
+	managePayments := auth.Require(entity.PermissionManagePayments)
+	manageCompany := auth.Require(entity.PermissionManageCompany)
+	manageUsers := auth.Require(entity.PermissionManageUsers)
 
 	protected := router.Group("/admin")
-	protected.Use(auth.Middleware(authService))
+	protected.Use(auth.Middleware(authService, companyUsecase, userUsecase), handlers.AuditOperatorActions(operatorUsecase))
 	{
-		protected.GET("/companies/:id/dashboard", handlers.GetCompanyDashboard(companyUsecase))
-		protected.GET("/companies/:id/balance", handlers.GetCompanyBalance(pixPaymentUsecase))
-
-		protected.POST("/companies/:id/withdraw", handlers.CreateWithdrawRequest(pixPaymentUsecase))
-
-		protected.POST("/courts", handlers.CreateCourt(courtUsecase))
+		protected.POST("/courts", manageCourts, handlers.CreateCourt(courtUsecase))
 		protected.GET("/courts/:id", handlers.FindCourtByID(courtUsecase))
 		protected.GET("/courts/:id/bookings", handlers.ListCourtBookingsByID(courtUsecase))
-		protected.PUT("/courts/:id", handlers.UpdateCourt(courtUsecase))
-		protected.PATCH("/courts/:id/status", handlers.ChangeCourtStatus(courtUsecase))
-		protected.DELETE("/courts/:id", handlers.DeleteCourt(courtUsecase))
+		protected.POST("/courts/:id/bookings", manageBookings, handlers.CreateManualBooking(bookingUsecase))
+		protected.POST("/courts/:id/bookings/cancel", manageBookings, handlers.CancelCourtBookings(bookingUsecase))
+		protected.PUT("/courts/:id", manageCourts, handlers.UpdateCourt(courtUsecase))
+		protected.PATCH("/courts/:id/status", manageCourts, handlers.ChangeCourtStatus(courtUsecase))
+		protected.DELETE("/courts/:id", manageCourts, handlers.DeleteCourt(courtUsecase))
+		protected.POST("/courts/:id/series", manageBookings, handlers.CreateBookingSeries(bookingUsecase))
+		protected.GET("/courts/:id/blackouts", handlers.ListCourtBlackouts(courtUsecase))
+		protected.POST("/courts/:id/blackouts", manageCourts, handlers.CreateCourtBlackout(courtUsecase))
+		protected.PUT("/courts/:id/blackouts/:blackout_id", manageCourts, handlers.UpdateCourtBlackout(courtUsecase))
+		protected.DELETE("/courts/:id/blackouts/:blackout_id", manageCourts, handlers.DeleteCourtBlackout(courtUsecase))
+		protected.GET("/courts/:id/pricing-rules", handlers.ListCourtPricingRules(courtUsecase))
+		protected.PUT("/courts/:id/pricing-rules", manageCourts, handlers.ReplaceCourtPricingRules(courtUsecase))
+
+		protected.GET("/webhook-events", viewFinances, handlers.ListWebhookEvents(webhookUsecase))
+		protected.GET("/webhook-events/:id", viewFinances, handlers.GetWebhookEvent(webhookUsecase))
+		protected.POST("/webhook-events/:id/replay", managePayments, handlers.ReplayWebhookEvent(webhookUsecase))
+		protected.GET("/payments/mismatches", viewFinances, handlers.ListPaymentMismatches(paymentUsecase))
+		protected.POST("/payments/:id/resolve", managePayments, handlers.ResolvePaymentMismatch(paymentUsecase))
+
+		protected.GET("/series/:id", handlers.FindBookingSeriesByID(bookingUsecase))
+		protected.DELETE("/series/:id", manageBookings, handlers.CancelBookingSeries(bookingUsecase))
+		protected.DELETE("/series/:id/bookings/:booking_id", manageBookings, handlers.CancelBookingSeriesOccurrence(bookingUsecase))
 
-		protected.GET("/companies/:id/courts", handlers.ListCourtsByCompany(courtUsecase))
-		protected.GET("/companies/:id", handlers.FindCompanyByID(companyUsecase))
-		protected.PUT("/companies/:id", handlers.UpdateCompanyInformations(companyUsecase))
 		protected.GET("/bookings", handlers.ListBookingsByCompany(bookingUsecase))
 		protected.GET("/bookings/:id", handlers.FindBookingByID(bookingUsecase))
+		protected.GET("/bookings/:id/notifications", handlers.ListBookingNotifications(outboxUsecase))
+		protected.POST("/bookings/:id/cancel", manageBookings, handlers.CancelBookingByCompany(bookingUsecase))
 		// TODO - (refactor) change this route name
-		protected.PATCH("/companies/:company_id/bookings/:booking_id/confirm", handlers.ConfirmBooking(bookingUsecase))
+		protected.PATCH("/companies/:company_id/bookings/:booking_id/confirm", auth.RequireCompany("company_id"), manageBookings, handlers.ConfirmBooking(bookingUsecase))
+
+		protected.GET("/users/me", handlers.GetCurrentUser(userUsecase))
+		protected.GET("/users", manageUsers, handlers.ListStaff(userUsecase))
+		protected.POST("/users/invitations", manageUsers, handlers.InviteUser(userUsecase))
+		protected.DELETE("/users/invitations/:id", manageUsers, handlers.RevokeInvitation(userUsecase))
+		protected.PUT("/users/:id/role", manageUsers, handlers.UpdateUserRole(userUsecase))
+		protected.DELETE("/users/:id", manageUsers, handlers.RemoveUser(userUsecase))
+	}
+
+	companies := protected.Group("/companies/:id")
+	companies.Use(auth.RequireCompany("id"))
+	{
+		companies.GET("/dashboard", viewFinances, handlers.GetCompanyDashboard(companyUsecase))
+		companies.GET("/balance", viewFinances, handlers.GetCompanyBalance(paymentUsecase))
+		companies.GET("/statement", viewFinances, handlers.GetCompanyStatement(statementUsecase))
+		companies.GET("/commission-plan", viewFinances, handlers.GetCompanyCommissionPlan(commissionUsecase))
+
+		companies.POST("/withdraw", withdraw, handlers.CreateWithdrawRequest(paymentUsecase))
+		companies.GET("/withdrawals", viewFinances, handlers.ListWithdrawals(paymentUsecase))
+		companies.GET("/payout-schedule", viewFinances, handlers.GetPayoutSchedule(paymentUsecase))
+		companies.PUT("/payout-schedule", withdraw, handlers.UpdatePayoutSchedule(paymentUsecase))
+
+		companies.GET("/courts", handlers.ListCourtsByCompany(courtUsecase))
+		companies.GET("/blackouts", handlers.ListCompanyBlackouts(courtUsecase))
+		companies.POST("/blackouts", manageCourts, handlers.CreateCompanyBlackout(courtUsecase))
+		companies.DELETE("/blackouts/:blackout_id", manageCourts, handlers.DeleteCompanyBlackout(courtUsecase))
+		companies.GET("", handlers.FindCompanyByID(companyUsecase))
+		companies.PUT("", manageCompany, handlers.UpdateCompanyInformations(companyUsecase))
+		companies.GET("/cancellation-policy", handlers.GetCancellationPolicy(companyUsecase))
+		companies.PUT("/cancellation-policy", manageCompany, handlers.UpdateCancellationPolicy(companyUsecase))
+	}
+
+	router.POST("/operator/login", handlers.LoginOperator(operatorUsecase))
+
+	operator := router.Group("/operator")
+	operator.Use(auth.OperatorMiddleware(authService), handlers.AuditOperatorActions(operatorUsecase))
+	{
+		operator.GET("/companies", handlers.ListPlatformCompanies(operatorUsecase))
+		operator.GET("/companies/:id", handlers.GetPlatformCompany(operatorUsecase))
+		operator.POST("/companies/:id/suspend", handlers.SuspendCompany(operatorUsecase))
+		operator.POST("/companies/:id/reactivate", handlers.ReactivateCompany(operatorUsecase))
+		operator.POST("/companies/:id/impersonate", handlers.ImpersonateCompany(operatorUsecase))
+		operator.GET("/revenue", handlers.GetPlatformRevenue(operatorUsecase))
+		operator.GET("/webhook-events", handlers.ListPlatformWebhookEvents(webhookUsecase))
+		operator.GET("/webhook-events/:id", handlers.GetPlatformWebhookEvent(webhookUsecase))
+		operator.POST("/webhook-events/:id/replay", handlers.ReplayPlatformWebhookEvent(webhookUsecase))
+		operator.GET("/audit-log", handlers.ListAuditLog(operatorUsecase))
+
+		operator.GET("/commission-plans", handlers.ListCommissionPlans(commissionUsecase))
+		operator.POST("/commission-plans", handlers.CreateCommissionPlan(commissionUsecase))
+		operator.PUT("/commission-plans/:id", handlers.UpdateCommissionPlan(commissionUsecase))
+		operator.PUT("/companies/:id/commission-plan", handlers.AssignCompanyCommissionPlan(commissionUsecase))
 	}
 
 	public := router.Group("/showcase")
 	{
 		public.GET("/companies/:id", handlers.FindCompanyByIDShowcase(companyUsecase))
 		public.GET("/companies/:id/courts", handlers.ListCompanyCourtShowcase(courtUsecase))
+		public.GET("/companies/:id/cancellation-policy", handlers.GetCancellationPolicyShowcase(companyUsecase))
 		public.GET("/courts/:id", handlers.FindCourtByIDShowcase(courtUsecase))
 		public.GET("/courts/:id/available-slots", handlers.ListAvailableBookingSlots(courtUsecase))
+		public.GET("/courts/:id/free-slots", handlers.ListFreeBookingSlots(courtUsecase))
+		public.POST("/courts/:id/quote", handlers.QuoteBooking(courtUsecase))
 		public.GET("/bookings", handlers.FindBookingByIDShowcase(bookingUsecase))
 		public.POST("/courts/:id/bookings", handlers.CreateNewBooking(bookingUsecase))
-		public.GET("/bookings/status", handlers.GetBookingPaymentStatus(pixPaymentUsecase))
-		public.GET("/bookings/:id/charge", handlers.GetBookingChargeInformation(pixPaymentUsecase))
+		public.GET("/bookings/status", handlers.GetBookingPaymentStatus(paymentUsecase))
+		public.GET("/bookings/:id/charge", handlers.GetBookingChargeInformation(paymentUsecase))
 	}
 
-	webhookRouter := router.Group("/webhooks")
+	webhookRouter := router.Group("/webhooks", webhooks.SignatureMiddleware(webhookVerifier))
 	{
-		webhookRouter.POST("/pix/confirmed", webhooks.ConfirmedPaymentWebhook(pixPaymentUsecase, bookingUsecase))
-		webhookRouter.POST("/pix/expired", webhooks.ExpiredPaymentWebhook(pixPaymentUsecase))
+		webhookRouter.POST("/pix/confirmed", webhooks.ConfirmedPaymentWebhook(webhookUsecase))
+		webhookRouter.POST("/pix/expired", webhooks.ExpiredPaymentWebhook(webhookUsecase))
+		webhookRouter.POST("/pix/refund", webhooks.RefundWebhook(webhookUsecase))
 	}
 
+	router.POST("/webhooks/card/charge", card.ChargeWebhook(cfg.Card.WebhookSecret, webhookUsecase))
+
 	router.POST("/bookings/cancel", handlers.CancelBooking(bookingUsecase))
 
 	srv := &http.Server{
@@ -141,6 +269,8 @@ func main() {
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
 
+	jobRunner.Start(ctx)
+
 	go func() {
 		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			log.Fatalf("cmd.main - Failed to start server: %v", err)
@@ -151,4 +281,5 @@ func main() {
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	_ = srv.Shutdown(shutdownCtx)
+	jobRunner.Wait()
 }
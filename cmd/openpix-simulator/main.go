@@ -0,0 +1,180 @@
+// Command openpix-simulator runs a local OpenPix API and drives its charges.
+//
+//	openpix-simulator serve [-addr :8081] [-webhook-url http://localhost:8080/webhooks]
+//	openpix-simulator pay <correlation id>
+//	openpix-simulator expire <correlation id>
+//	openpix-simulator charges
+//	openpix-simulator confirm-refund <payment id>
+//	openpix-simulator reject-refund <payment id>
+//
+// Point OPENPIX_BASE_URL at the simulator and give both processes the same
+// OPENPIX_WEBHOOK_SECRET so the API accepts the webhooks it sends.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/dinizgab/booking-mvp/internal/gateway/openpix/simulator"
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	// The simulator shares the API .env so both sides agree on the app id and
+	// webhook secret. A missing file is fine.
+	_ = godotenv.Load()
+
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "serve":
+		err = serve(os.Args[2:])
+	case "pay", "expire":
+		err = chargeAction(os.Args[1], os.Args[2:])
+	case "confirm-refund", "reject-refund":
+		err = refundAction(strings.TrimSuffix(os.Args[1], "-refund"), os.Args[2:])
+	case "charges":
+		err = listCharges(os.Args[2:])
+	default:
+		usage()
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: openpix-simulator serve|pay|expire|charges|confirm-refund|reject-refund [flags]")
+	os.Exit(2)
+}
+
+func serve(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := flags.String("addr", envOr("OPENPIX_SIMULATOR_ADDR", ":8081"), "listen address")
+	webhookURL := flags.String("webhook-url", envOr("OPENPIX_SIMULATOR_WEBHOOK_URL", "http://localhost:8080/webhooks"), "base URL of the API webhook routes, empty disables webhooks")
+	_ = flags.Parse(args)
+
+	baseURL := "http://localhost" + *addr
+	if !strings.HasPrefix(*addr, ":") {
+		baseURL = "http://" + *addr
+	}
+
+	server := simulator.New(simulator.Config{
+		AppID:         os.Getenv("OPENPIX_APP_ID"),
+		WebhookURL:    *webhookURL,
+		WebhookSecret: os.Getenv("OPENPIX_WEBHOOK_SECRET"),
+		BaseURL:       baseURL,
+	})
+
+	srv := &http.Server{
+		Addr:         *addr,
+		Handler:      server,
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 30 * time.Second,
+	}
+
+	go func() {
+		log.Printf("OpenPix simulator listening on %s, sending webhooks to %s", *addr, *webhookURL)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Failed to start simulator: %v", err)
+		}
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return srv.Shutdown(ctx)
+}
+
+func chargeAction(action string, args []string) error {
+	flags := flag.NewFlagSet(action, flag.ExitOnError)
+	url := flags.String("url", envOr("OPENPIX_BASE_URL", "http://localhost:8081"), "simulator URL")
+	_ = flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: openpix-simulator %s [-url URL] <correlation id>", action)
+	}
+
+	correlationId := flags.Arg(0)
+	if !strings.HasPrefix(correlationId, "booking-") {
+		correlationId = "booking-" + correlationId
+	}
+
+	return call(http.MethodPost, fmt.Sprintf("%s/simulator/charges/%s/%s", *url, correlationId, action))
+}
+
+func refundAction(action string, args []string) error {
+	flags := flag.NewFlagSet(action+"-refund", flag.ExitOnError)
+	url := flags.String("url", envOr("OPENPIX_BASE_URL", "http://localhost:8081"), "simulator URL")
+	_ = flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: openpix-simulator %s-refund [-url URL] <payment id>", action)
+	}
+
+	correlationId := flags.Arg(0)
+	if !strings.HasPrefix(correlationId, "refund-") {
+		correlationId = "refund-" + correlationId
+	}
+
+	return call(http.MethodPost, fmt.Sprintf("%s/simulator/refunds/%s/%s", *url, correlationId, action))
+}
+
+func listCharges(args []string) error {
+	flags := flag.NewFlagSet("charges", flag.ExitOnError)
+	url := flags.String("url", envOr("OPENPIX_BASE_URL", "http://localhost:8081"), "simulator URL")
+	_ = flags.Parse(args)
+
+	return call(http.MethodGet, *url+"/simulator/charges")
+}
+
+func call(method string, url string) error {
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("simulator answered %s: %s", res.Status, strings.TrimSpace(string(body)))
+	}
+
+	fmt.Print(string(body))
+	return nil
+}
+
+func envOr(key string, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+
+	return fallback
+}
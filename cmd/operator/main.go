@@ -0,0 +1,65 @@
+// Command operator manages the platform operators.
+//
+//	operator create -name "Ana Souza" -email ana@courtly.com.br
+//
+// The password is read from the OPERATOR_PASSWORD environment variable so it
+// does not end up in the shell history.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/dinizgab/booking-mvp/internal/auth"
+	"github.com/dinizgab/booking-mvp/internal/config"
+	"github.com/dinizgab/booking-mvp/internal/database"
+	"github.com/dinizgab/booking-mvp/internal/entity"
+	"github.com/dinizgab/booking-mvp/internal/repository"
+	"github.com/dinizgab/booking-mvp/internal/usecase"
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] != "create" {
+		fmt.Fprintln(os.Stderr, "usage: operator create -name <name> -email <email>")
+		os.Exit(2)
+	}
+
+	flags := flag.NewFlagSet("create", flag.ExitOnError)
+	name := flags.String("name", "", "operator name")
+	email := flags.String("email", "", "operator email, used to log in")
+	_ = flags.Parse(os.Args[2:])
+
+	// A missing .env is fine, the variables may come from the environment.
+	_ = godotenv.Load()
+
+	cfg, err := config.New()
+	if err != nil {
+		log.Fatalf("Error loading cfg: %v", err)
+	}
+
+	db, err := database.New(cfg.DB)
+	if err != nil {
+		log.Fatalf("Failed to connect to DB: %v", err)
+	}
+	defer db.Close()
+
+	operatorUsecase := usecase.NewOperatorUsecase(
+		repository.NewOperatorRepository(db),
+		auth.NewAuthService(cfg.API.JwtSecret),
+	)
+
+	operator, err := operatorUsecase.Create(context.Background(), entity.Operator{
+		Name:     *name,
+		Email:    *email,
+		Password: os.Getenv("OPERATOR_PASSWORD"),
+	})
+	if err != nil {
+		log.Fatalf("Failed to create operator: %v", err)
+	}
+
+	log.Printf("Created operator %s (%s)", operator.Email, operator.ID)
+}
@@ -0,0 +1,102 @@
+// Command reconcile compares the payments with the charges of every payment
+// gateway and prints the report as JSON.
+//
+//	reconcile [-since 24h] [-from 2025-01-01T00:00:00Z] [-to 2025-01-02T00:00:00Z]
+//
+// Charges paid on the gateway whose payment is still pending are confirmed;
+// every other difference is only reported.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"github.com/dinizgab/booking-mvp/internal/config"
+	"github.com/dinizgab/booking-mvp/internal/database"
+	"github.com/dinizgab/booking-mvp/internal/gateway/card"
+	"github.com/dinizgab/booking-mvp/internal/gateway/openpix"
+	"github.com/dinizgab/booking-mvp/internal/ports"
+	"github.com/dinizgab/booking-mvp/internal/repository"
+	"github.com/dinizgab/booking-mvp/internal/services/pricing"
+	"github.com/dinizgab/booking-mvp/internal/usecase"
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	since := flag.Duration("since", 24*time.Hour, "reconcile the charges created in the last period, ignored when -from is set")
+	fromFlag := flag.String("from", "", "start of the window (RFC 3339)")
+	toFlag := flag.String("to", "", "end of the window (RFC 3339), defaults to now")
+	flag.Parse()
+
+	to := time.Now()
+	if *toFlag != "" {
+		parsed, err := time.Parse(time.RFC3339, *toFlag)
+		if err != nil {
+			log.Fatalf("Invalid -to: %v", err)
+		}
+		to = parsed
+	}
+
+	from := to.Add(-*since)
+	if *fromFlag != "" {
+		parsed, err := time.Parse(time.RFC3339, *fromFlag)
+		if err != nil {
+			log.Fatalf("Invalid -from: %v", err)
+		}
+		from = parsed
+	}
+
+	if !from.Before(to) {
+		log.Fatal("The window start must be before its end")
+	}
+
+	// A missing .env is fine, the variables may come from the environment.
+	_ = godotenv.Load()
+
+	cfg, err := config.New()
+	if err != nil {
+		log.Fatalf("Error loading cfg: %v", err)
+	}
+
+	db, err := database.New(cfg.DB)
+	if err != nil {
+		log.Fatalf("Failed to connect to DB: %v", err)
+	}
+	defer db.Close()
+
+	paymentGateways := []ports.PaymentGateway{
+		openpix.NewGateway(openpix.NewOpenPixClient(cfg.OpenPix)),
+	}
+	if cfg.Card.BaseURL != "" {
+		paymentGateways = append(paymentGateways, card.NewGateway(cfg.Card))
+	}
+
+	bookingRepository := repository.NewBookingRepository(db)
+	paymentRepository := repository.NewPaymentRepository(db)
+	paymentUsecase := usecase.NewPaymentUsecase(
+		paymentGateways,
+		bookingRepository,
+		paymentRepository,
+		pricing.NewFeeCalculator(),
+		repository.NewCommissionPlanRepository(db),
+		pricing.NewRefundCalculator(),
+	)
+	reconciliationUsecase := usecase.NewReconciliationUsecase(paymentGateways, paymentRepository, paymentUsecase)
+
+	report, err := reconciliationUsecase.Reconcile(context.Background(), from, to)
+	if err != nil {
+		log.Fatalf("Failed to reconcile payments: %v", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("Checked %d charges, fixed %d, %d mismatches", report.Checked, report.Fixed, len(report.Mismatches()))
+}
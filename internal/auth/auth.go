@@ -3,17 +3,41 @@ package auth
 import (
 	"time"
 
+	"github.com/dinizgab/booking-mvp/internal/entity"
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// Token roles. Company tokens give access to the /admin routes of the
+// company in Sub, operator tokens to the /operator routes.
+const (
+	RoleCompany  = "authenticated"
+	RoleOperator = "operator"
+)
+
+// operatorTokenDuration is kept short as operator tokens reach every company.
+const operatorTokenDuration = 8 * time.Hour
+
+// CourtlyClaims keeps the company in Sub and RoleCompany in Role for company
+// tokens, as they are also sent to the storage API. The staff account is in
+// UserID and UserRole.
 type CourtlyClaims struct {
     Sub string `json:"sub"`
     Role string `json:"role"`
+    UserID string `json:"user_id,omitempty"`
+    UserRole entity.UserRole `json:"user_role,omitempty"`
+    // Act is the operator impersonating the company in Sub, if any.
+    Act string `json:"act,omitempty"`
     jwt.RegisteredClaims
 }
 
 type AuthService interface {
-	GenerateToken(companyID string) (string, error)
+	// GenerateToken signs a company token for the staff account.
+	GenerateToken(user entity.User) (string, error)
+	// GenerateOperatorToken signs an operator token for the /operator routes.
+	GenerateOperatorToken(operatorID string) (string, error)
+	// GenerateImpersonationToken signs a company token on behalf of an
+	// operator, with the support role and valid for the given duration.
+	GenerateImpersonationToken(companyID, operatorID string, duration time.Duration) (string, error)
 	GetSecretKey() []byte
 }
 
@@ -27,10 +51,12 @@ func NewAuthService(jwtSecret []byte) AuthService {
 	}
 }
 
-func (s *authServiceImpl) GenerateToken(companyID string) (string, error) {
+func (s *authServiceImpl) GenerateToken(user entity.User) (string, error) {
 	claims := CourtlyClaims{
-        Sub: companyID,
-        Role: "authenticated",
+        Sub: user.CompanyID,
+        Role: RoleCompany,
+        UserID: user.ID,
+        UserRole: user.Role,
         RegisteredClaims: jwt.RegisteredClaims{
             Issuer:    "courtly-api",
             IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -43,6 +69,40 @@ func (s *authServiceImpl) GenerateToken(companyID string) (string, error) {
 	return token.SignedString(s.jwtSecret)
 }
 
+func (s *authServiceImpl) GenerateOperatorToken(operatorID string) (string, error) {
+	return s.sign(CourtlyClaims{
+		Sub:              operatorID,
+		Role:             RoleOperator,
+		RegisteredClaims: registeredClaims(RoleOperator, operatorTokenDuration),
+	})
+}
+
+func (s *authServiceImpl) GenerateImpersonationToken(companyID, operatorID string, duration time.Duration) (string, error) {
+	return s.sign(CourtlyClaims{
+		Sub:              companyID,
+		Role:             RoleCompany,
+		UserRole:         entity.RoleSupport,
+		Act:              operatorID,
+		RegisteredClaims: registeredClaims(RoleCompany, duration),
+	})
+}
+
+func (s *authServiceImpl) sign(claims CourtlyClaims) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(s.jwtSecret)
+}
+
+func registeredClaims(audience string, duration time.Duration) jwt.RegisteredClaims {
+	now := time.Now()
+
+	return jwt.RegisteredClaims{
+		Issuer:    "courtly-api",
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(duration)),
+		Audience:  jwt.ClaimStrings{audience},
+	}
+}
+
 func (s *authServiceImpl) GetSecretKey() []byte {
 	return s.jwtSecret
 }
@@ -1,53 +1,182 @@
 package auth
 
 import (
+	"context"
+	"errors"
+	"log"
 	"strings"
 	"time"
 
+	"github.com/dinizgab/booking-mvp/internal/entity"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func Middleware(as AuthService) gin.HandlerFunc {
+// CompanyStatusChecker tells whether a company may still use the admin API.
+type CompanyStatusChecker interface {
+	CheckActive(ctx context.Context, companyID string) error
+}
+
+// UserRoleReader returns the current role of a staff account, or
+// entity.ErrUserNotFound once it was removed from the company.
+type UserRoleReader interface {
+	CurrentRole(ctx context.Context, companyID string, userID string) (entity.UserRole, error)
+}
+
+// Middleware lets through company tokens. Tokens of suspended companies and
+// removed users are refused unless an operator is impersonating the company.
+// The role is read again on every request so role changes apply right away.
+// Nil checkers are skipped.
+func Middleware(as AuthService, companies CompanyStatusChecker, users UserRoleReader) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
+		claims, tokenString, ok := parseToken(c, as)
+		if !ok {
+			return
+		}
+
+		if claims.Role != RoleCompany {
+			c.JSON(403, gin.H{"error": "forbidden"})
+			c.Abort()
+			return
+		}
+
+		// Tokens issued before staff accounts carry no user role.
+		if !claims.UserRole.IsValid() {
 			c.JSON(401, gin.H{"error": "unauthorized"})
 			c.Abort()
 			return
 		}
 
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+		role := claims.UserRole
+		if users != nil && claims.Act == "" {
+			current, err := users.CurrentRole(c.Request.Context(), claims.Sub, claims.UserID)
+			if err != nil {
+				if errors.Is(err, entity.ErrUserNotFound) {
+					c.JSON(401, gin.H{"error": "unauthorized"})
+				} else {
+					log.Println(err)
+					c.JSON(500, gin.H{"error": "Failed to check user"})
+				}
+				c.Abort()
+				return
+			}
+			role = current
+		}
 
-		token, err := jwt.ParseWithClaims(tokenString, &CourtlyClaims{}, func(token *jwt.Token) (interface{}, error) {
-			if token.Method != jwt.SigningMethodHS256 {
-				return nil, jwt.ErrTokenSignatureInvalid
+		if companies != nil && claims.Act == "" {
+			if err := companies.CheckActive(c.Request.Context(), claims.Sub); err != nil {
+				if errors.Is(err, entity.ErrCompanySuspended) {
+					c.JSON(403, gin.H{"error": "company suspended"})
+				} else {
+					log.Println(err)
+					c.JSON(500, gin.H{"error": "Failed to check company status"})
+				}
+				c.Abort()
+				return
 			}
+		}
 
-			return as.GetSecretKey(), nil
-		})
+		c.Set("company_id", claims.Sub)
+		c.Set("user_id", claims.UserID)
+		c.Set("user_role", string(role))
+		c.Set("jwt_token", tokenString)
+		if claims.Act != "" {
+			c.Set("operator_id", claims.Act)
+		}
 
-		if err != nil || !token.Valid {
-			c.JSON(401, gin.H{"error": "unauthorized"})
+		c.Next()
+	}
+}
+
+// Require lets through the users whose role has the permission. It runs
+// after Middleware.
+func Require(permission entity.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := entity.UserRole(c.GetString("user_role"))
+		if !role.Can(permission) {
+			c.JSON(403, gin.H{"error": "forbidden"})
 			c.Abort()
 			return
 		}
 
-		claims, ok := token.Claims.(*CourtlyClaims)
-		if !ok {
-			c.JSON(401, gin.H{"error": "unauthorized"})
+		c.Next()
+	}
+}
+
+// RequireCompany lets through the requests whose param is the company of the
+// token, so a company can't reach another one by changing the URL. It runs
+// after Middleware.
+func RequireCompany(param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Param(param) != c.GetString("company_id") {
+			c.JSON(403, gin.H{"error": "forbidden"})
 			c.Abort()
 			return
 		}
 
-        if claims.ExpiresAt.Time.Before(time.Now()) {
-			c.JSON(401, gin.H{"error": "token expired"})
-			c.Abort(); return
+		c.Next()
+	}
+}
+
+// OperatorMiddleware lets through operator tokens.
+func OperatorMiddleware(as AuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, tokenString, ok := parseToken(c, as)
+		if !ok {
+			return
+		}
+
+		if claims.Role != RoleOperator {
+			c.JSON(403, gin.H{"error": "forbidden"})
+			c.Abort()
+			return
 		}
 
-		c.Set("company_id", claims.Sub)
+		c.Set("operator_id", claims.Sub)
 		c.Set("jwt_token", tokenString)
 
 		c.Next()
 	}
 }
+
+// parseToken reads the bearer token of the request. It aborts the request
+// and returns false when the token is missing or invalid.
+func parseToken(c *gin.Context, as AuthService) (*CourtlyClaims, string, bool) {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
+		c.JSON(401, gin.H{"error": "unauthorized"})
+		c.Abort()
+		return nil, "", false
+	}
+
+	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+
+	token, err := jwt.ParseWithClaims(tokenString, &CourtlyClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if token.Method != jwt.SigningMethodHS256 {
+			return nil, jwt.ErrTokenSignatureInvalid
+		}
+
+		return as.GetSecretKey(), nil
+	})
+
+	if err != nil || !token.Valid {
+		c.JSON(401, gin.H{"error": "unauthorized"})
+		c.Abort()
+		return nil, "", false
+	}
+
+	claims, ok := token.Claims.(*CourtlyClaims)
+	if !ok {
+		c.JSON(401, gin.H{"error": "unauthorized"})
+		c.Abort()
+		return nil, "", false
+	}
+
+	if claims.ExpiresAt.Time.Before(time.Now()) {
+		c.JSON(401, gin.H{"error": "token expired"})
+		c.Abort()
+		return nil, "", false
+	}
+
+	return claims, tokenString, true
+}
@@ -11,12 +11,15 @@ type Config struct {
 	DB      *DBConfig
 	SMTP    *SMTPConfig
 	OpenPix *OpenPixConfig
+	Card    *CardConfig
 	Storage *StorageConfig
 }
 
 type APIConfig struct {
 	Port      string
 	JwtSecret []byte
+	// FakePayments swaps every payment gateway for an in-memory fake.
+	FakePayments bool
 }
 
 type DBConfig struct {
@@ -32,9 +35,17 @@ type SMTPConfig struct {
 }
 
 type OpenPixConfig struct {
-	BaseURL string
-	AppID   string
-	Timeout int
+	BaseURL          string
+	AppID            string
+	Timeout          int
+	WebhookSecret    string
+	WebhookPublicKey string
+}
+
+type CardConfig struct {
+	BaseURL       string
+	SecretKey     string
+	WebhookSecret string
 }
 
 type StorageConfig struct {
@@ -50,8 +61,9 @@ func New() (*Config, error) {
 
 	return &Config{
 		API: &APIConfig{
-			Port:      os.Getenv("API_PORT"),
-			JwtSecret: []byte(os.Getenv("JWT_SECRET")),
+			Port:         os.Getenv("API_PORT"),
+			JwtSecret:    []byte(os.Getenv("JWT_SECRET")),
+			FakePayments: os.Getenv("FAKE_PAYMENT_GATEWAY") == "true",
 		},
 		DB: &DBConfig{
 			DBUrl: os.Getenv("DATABASE_URL"),
@@ -64,8 +76,15 @@ func New() (*Config, error) {
 			Pass:  os.Getenv("SMTP_PASS"),
 		},
 		OpenPix: &OpenPixConfig{
-			BaseURL: os.Getenv("OPENPIX_BASE_URL"),
-			AppID:   os.Getenv("OPENPIX_APP_ID"),
+			BaseURL:          os.Getenv("OPENPIX_BASE_URL"),
+			AppID:            os.Getenv("OPENPIX_APP_ID"),
+			WebhookSecret:    os.Getenv("OPENPIX_WEBHOOK_SECRET"),
+			WebhookPublicKey: os.Getenv("OPENPIX_WEBHOOK_PUBLIC_KEY"),
+		},
+		Card: &CardConfig{
+			BaseURL:       os.Getenv("CARD_BASE_URL"),
+			SecretKey:     os.Getenv("CARD_SECRET_KEY"),
+			WebhookSecret: os.Getenv("CARD_WEBHOOK_SECRET"),
 		},
 		Storage: &StorageConfig{
 			ProjectURL: os.Getenv("STORAGE_PROJECT_URL"),
@@ -17,11 +17,33 @@ const (
 	StatusCancelled BookingStatus = "cancelled"
 )
 
+type PaymentMethod string
+
+const (
+	PaymentMethodPix  PaymentMethod = "pix"
+	PaymentMethodCash PaymentMethod = "cash"
+	PaymentMethodCard PaymentMethod = "card"
+	// PaymentMethodComp is a complimentary booking, nothing is charged.
+	PaymentMethodComp PaymentMethod = "comp"
+)
+
+// IsManual reports whether the method is settled at the front desk instead of
+// through a Pix charge.
+func (m PaymentMethod) IsManual() bool {
+	return m == PaymentMethodCash || m == PaymentMethodCard || m == PaymentMethodComp
+}
+
+// BookingHoldDuration is how long a pending booking reserves its slot while
+// waiting for the Pix payment. It matches the charge lifetime.
+const BookingHoldDuration = 30 * time.Minute
+
 var (
 	ErrInvalidVerificationCode = errors.New("invalid verification code")
 	ErrBookingAlreadyConfirmed = errors.New("booking already confirmed")
 	ErrInvalidCodeFormat       = errors.New("verification code must be 6 digits")
 	ErrBookingNotFound         = errors.New("booking not found")
+	ErrSlotUnavailable         = errors.New("slot is no longer available")
+	ErrInvalidPaymentMethod    = errors.New("invalid payment method")
 )
 
 type BookingFilter struct {
@@ -39,25 +61,40 @@ type BookingConfirmationInfo struct {
 	BookingDate      string `json:"booking_date"`
 	BookingInterval  string `json:"booking_interval"`
 	TotalPrice       string `json:"total_price"`
+	PlatformFee      string `json:"platform_fee,omitempty"`
 	VerificationCode string `json:"verification_code"`
 	CancelToken      string `json:"cancel_token"`
+	RefundAmount     string `json:"refund_amount,omitempty"`
+	RefundPercent    int    `json:"refund_percent,omitempty"`
+	RefundFailed     bool   `json:"refund_failed,omitempty"`
+	// CancellationReason is set when the club cancels the booking.
+	CancellationReason string `json:"cancellation_reason,omitempty"`
+	// ExpectedAmount, ReceivedAmount and AmountDelta describe a payment
+	// received with the wrong value.
+	ExpectedAmount string `json:"expected_amount,omitempty"`
+	ReceivedAmount string `json:"received_amount,omitempty"`
+	AmountDelta    string `json:"amount_delta,omitempty"`
 }
 
 type Booking struct {
-	ID                       string        `json:"id"`
-	CourtId                  string        `json:"court_id"`
-	StartTime                time.Time     `json:"start_time"`
-	EndTime                  time.Time     `json:"end_time"`
-	CreatedAt                time.Time     `json:"created_at"`
-	Status                   BookingStatus `json:"status"`
-	GuestName                string        `json:"guest_name"`
-	GuestPhone               string        `json:"guest_phone"`
-	GuestEmail               string        `json:"guest_email"`
-	VerificationCode         string        `json:"verification_code"`
-	TotalPrice               int64         `json:"total_price"`
-	CancelTokenHash          string        `json:"cancel_token_hash"`
-	CancelTokenHashExpiresAt time.Time     `json:"cancel_token_hash_expires_at"`
-	Court                    *Court        `json:"court,omitempty"`
+	ID               string        `json:"id"`
+	CourtId          string        `json:"court_id"`
+	StartTime        time.Time     `json:"start_time"`
+	EndTime          time.Time     `json:"end_time"`
+	CreatedAt        time.Time     `json:"created_at"`
+	Status           BookingStatus `json:"status"`
+	GuestName        string        `json:"guest_name"`
+	GuestPhone       string        `json:"guest_phone"`
+	GuestEmail       string        `json:"guest_email"`
+	VerificationCode string        `json:"verification_code"`
+	TotalPrice       int64         `json:"total_price"`
+	PlatformFee      int64         `json:"platform_fee,omitempty"`
+	CancelTokenHash  string        `json:"cancel_token_hash"`
+	HoldExpiresAt    time.Time     `json:"hold_expires_at"`
+	SeriesId         *string       `json:"series_id,omitempty"`
+	PaymentMethod    PaymentMethod `json:"payment_method"`
+	CreatedBy        string        `json:"created_by,omitempty"`
+	Court            *Court        `json:"court,omitempty"`
 }
 
 func (b Booking) DurationInHours() float64 {
@@ -71,6 +108,10 @@ func (b Booking) DurationInHours() float64 {
 	return duration
 }
 
+func (b Booking) Overlaps(start time.Time, end time.Time) bool {
+	return start.Before(b.EndTime) && b.StartTime.Before(end)
+}
+
 func GenerateVerificationCode() string {
 	const charset = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 	code := make([]byte, 6)
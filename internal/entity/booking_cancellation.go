@@ -0,0 +1,82 @@
+package entity
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrCancellationReasonRequired = errors.New("cancellation reason is required")
+	ErrInvalidCancellationRange   = errors.New("invalid cancellation range")
+	ErrBookingAlreadyCancelled    = errors.New("booking is already cancelled")
+)
+
+// Who cancelled a booking.
+const (
+	CancelledByGuest   = "guest"
+	CancelledByCompany = "company"
+)
+
+const (
+	maxCancellationReasonLength = 500
+	maxCancellationRange        = 31 * 24 * time.Hour
+)
+
+// CompanyCancellation is a cancellation made by the club. FullRefund returns
+// everything the guest paid, platform fee included, regardless of the
+// cancellation policy.
+type CompanyCancellation struct {
+	Reason     string `json:"reason"`
+	FullRefund bool   `json:"full_refund"`
+}
+
+func (c CompanyCancellation) Validate() error {
+	reason := strings.TrimSpace(c.Reason)
+	if reason == "" || len(reason) > maxCancellationReasonLength {
+		return ErrCancellationReasonRequired
+	}
+
+	return nil
+}
+
+// Policy returns the refund policy applied to the cancellation.
+func (c CompanyCancellation) Policy(companyPolicy CancellationPolicy) CancellationPolicy {
+	if !c.FullRefund {
+		return companyPolicy
+	}
+
+	return CancellationPolicy{
+		CompanyId:         companyPolicy.CompanyId,
+		Tiers:             []CancellationTier{{MinHoursBefore: 0, RefundPercent: 100}},
+		RefundPlatformFee: true,
+	}
+}
+
+// CourtCancellation cancels every upcoming booking of a court that starts in
+// the [From, To) range.
+type CourtCancellation struct {
+	CompanyCancellation
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+func (c CourtCancellation) Validate() error {
+	if err := c.CompanyCancellation.Validate(); err != nil {
+		return err
+	}
+
+	if c.From.IsZero() || !c.To.After(c.From) || c.To.Sub(c.From) > maxCancellationRange {
+		return ErrInvalidCancellationRange
+	}
+
+	return nil
+}
+
+// CancellationResult reports the outcome of cancelling one booking.
+type CancellationResult struct {
+	BookingId string          `json:"booking_id"`
+	Cancelled bool            `json:"cancelled"`
+	Refund    *RefundDecision `json:"refund,omitempty"`
+	Error     string          `json:"error,omitempty"`
+}
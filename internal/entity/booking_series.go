@@ -0,0 +1,136 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+type SeriesFrequency string
+
+const (
+	FrequencyWeekly   SeriesFrequency = "weekly"
+	FrequencyBiweekly SeriesFrequency = "biweekly"
+)
+
+type SeriesPaymentMode string
+
+const (
+	// PaymentPerOccurrence creates one Pix charge per occurrence, payable
+	// until the occurrence starts or for SeriesHoldDuration, whichever comes
+	// first.
+	PaymentPerOccurrence SeriesPaymentMode = "per_occurrence"
+	// PaymentUpfront creates a single Pix charge for every occurrence.
+	PaymentUpfront SeriesPaymentMode = "upfront"
+)
+
+type SeriesStatus string
+
+const (
+	SeriesActive    SeriesStatus = "active"
+	SeriesCancelled SeriesStatus = "cancelled"
+)
+
+// MaxSeriesOccurrences caps how many bookings a single series may generate.
+const MaxSeriesOccurrences = 52
+
+// SeriesHoldDuration caps how long an unpaid occurrence reserves its slot, so
+// slots months ahead are not blocked by charges nobody pays.
+const SeriesHoldDuration = 7 * 24 * time.Hour
+
+var (
+	ErrInvalidSeries          = errors.New("invalid booking series")
+	ErrSeriesNotFound         = errors.New("booking series not found")
+	ErrSeriesAlreadyCancelled = errors.New("booking series is already cancelled")
+	ErrSeriesPaymentPending   = errors.New("the series payment is still pending and can only be cancelled with the whole series")
+	ErrSeriesRefundPending    = errors.New("the previous refund of the series payment is still being processed")
+)
+
+type BookingSeries struct {
+	ID          string            `json:"id"`
+	CompanyId   string            `json:"company_id"`
+	CourtId     string            `json:"court_id"`
+	GuestName   string            `json:"guest_name"`
+	GuestPhone  string            `json:"guest_phone"`
+	GuestEmail  string            `json:"guest_email"`
+	Frequency   SeriesFrequency   `json:"frequency"`
+	StartTime   time.Time         `json:"start_time"`
+	EndTime     time.Time         `json:"end_time"`
+	Until       *time.Time        `json:"until,omitempty"`
+	Count       int               `json:"count,omitempty"`
+	PaymentMode SeriesPaymentMode `json:"payment_mode"`
+	Status      SeriesStatus      `json:"status"`
+	CreatedAt   time.Time         `json:"created_at"`
+	Bookings    []Booking         `json:"bookings,omitempty"`
+}
+
+// SeriesCreationResult lists the occurrences that were booked and the ones
+// that overlapped existing bookings.
+type SeriesCreationResult struct {
+	Series    BookingSeries `json:"series"`
+	Created   []Booking     `json:"created"`
+	Conflicts []Booking     `json:"conflicts"`
+}
+
+func (s BookingSeries) Validate(now time.Time) error {
+	if s.Frequency != FrequencyWeekly && s.Frequency != FrequencyBiweekly {
+		return ErrInvalidSeries
+	}
+
+	if s.PaymentMode != PaymentPerOccurrence && s.PaymentMode != PaymentUpfront {
+		return ErrInvalidSeries
+	}
+
+	if !s.EndTime.After(s.StartTime) || s.StartTime.Before(now) {
+		return ErrInvalidSeries
+	}
+
+	if s.Until == nil && s.Count <= 0 {
+		return ErrInvalidSeries
+	}
+
+	if s.Until != nil && s.Until.Before(s.StartTime) {
+		return ErrInvalidSeries
+	}
+
+	if s.Count > MaxSeriesOccurrences {
+		return ErrInvalidSeries
+	}
+
+	return nil
+}
+
+// Occurrences expands the series into its bookings, stopping at the count,
+// the until date or MaxSeriesOccurrences, whichever comes first.
+func (s BookingSeries) Occurrences() []Booking {
+	step := 7
+	if s.Frequency == FrequencyBiweekly {
+		step = 14
+	}
+
+	limit := MaxSeriesOccurrences
+	if s.Count > 0 && s.Count < limit {
+		limit = s.Count
+	}
+
+	start := s.StartTime.In(CourtLocation)
+	end := s.EndTime.In(CourtLocation)
+
+	occurrences := make([]Booking, 0, limit)
+	for i := 0; i < limit; i++ {
+		occurrenceStart := start.AddDate(0, 0, i*step)
+		if s.Until != nil && occurrenceStart.After(*s.Until) {
+			break
+		}
+
+		occurrences = append(occurrences, Booking{
+			CourtId:    s.CourtId,
+			StartTime:  occurrenceStart,
+			EndTime:    end.AddDate(0, 0, i*step),
+			GuestName:  s.GuestName,
+			GuestPhone: s.GuestPhone,
+			GuestEmail: s.GuestEmail,
+		})
+	}
+
+	return occurrences
+}
@@ -0,0 +1,62 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrCourtClosed         = errors.New("court is closed on the requested day")
+	ErrOutsideOpeningHours = errors.New("booking is outside the court opening hours")
+	ErrCourtInactive       = errors.New("court is not active")
+	ErrBookingInPast       = errors.New("booking cannot start in the past")
+	ErrInvalidDuration     = errors.New("booking must end after it starts")
+)
+
+// ValidateBooking checks that the court accepts bookings, that the requested
+// interval follows the court booking policy and that it fits inside one of its
+// opening windows. Windows from the previous day are considered so courts
+// closing after midnight are covered.
+func (c Court) ValidateBooking(booking Booking, now time.Time) error {
+	if !c.IsActive {
+		return ErrCourtInactive
+	}
+
+	if !booking.EndTime.After(booking.StartTime) {
+		return ErrInvalidDuration
+	}
+
+	if booking.StartTime.Before(now) {
+		return ErrBookingInPast
+	}
+
+	policy := c.Policy()
+	if err := policy.CheckDuration(booking.EndTime.Sub(booking.StartTime)); err != nil {
+		return err
+	}
+
+	if err := policy.CheckAlignment(booking.StartTime); err != nil {
+		return err
+	}
+
+	start := booking.StartTime.In(CourtLocation)
+	open := false
+	for _, day := range []time.Time{start, start.AddDate(0, 0, -1)} {
+		schedule, ok := c.ScheduleFor(day.Weekday())
+		if !ok || !schedule.IsOpen {
+			continue
+		}
+
+		open = open || day.Equal(start)
+		opening, closing := schedule.OpeningHours(day)
+		if !booking.StartTime.Before(opening) && !booking.EndTime.After(closing) {
+			return nil
+		}
+	}
+
+	if !open {
+		return ErrCourtClosed
+	}
+
+	return ErrOutsideOpeningHours
+}
@@ -0,0 +1,103 @@
+package entity
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+var (
+	ErrInvalidCancellationPolicy = errors.New("invalid cancellation policy")
+	ErrCancellationClosed        = errors.New("booking can no longer be cancelled")
+)
+
+// Refund reasons returned by the refund calculator.
+const (
+	RefundReasonFull    = "full_refund"
+	RefundReasonPartial = "partial_refund"
+	RefundReasonNone    = "no_refund"
+)
+
+const maxCancellationTiers = 10
+
+// CancellationTier refunds RefundPercent of the booking when it is cancelled
+// at least MinHoursBefore hours before it starts.
+type CancellationTier struct {
+	MinHoursBefore int `json:"min_hours_before"`
+	RefundPercent  int `json:"refund_percent"`
+}
+
+// CancellationPolicy is the refund schedule of a company. Cancellations that
+// match no tier are accepted without a refund. The platform fee is kept unless
+// RefundPlatformFee is set.
+type CancellationPolicy struct {
+	CompanyId         string             `json:"company_id"`
+	Tiers             []CancellationTier `json:"tiers"`
+	RefundPlatformFee bool               `json:"refund_platform_fee"`
+}
+
+// DefaultCancellationPolicy fully refunds bookings cancelled at least three
+// hours before they start, the rule used before policies were configurable.
+func DefaultCancellationPolicy(companyId string) CancellationPolicy {
+	return CancellationPolicy{
+		CompanyId: companyId,
+		Tiers: []CancellationTier{
+			{MinHoursBefore: 3, RefundPercent: 100},
+		},
+	}
+}
+
+func (p CancellationPolicy) Validate() error {
+	if len(p.Tiers) > maxCancellationTiers {
+		return ErrInvalidCancellationPolicy
+	}
+
+	seen := make(map[int]bool, len(p.Tiers))
+	for _, tier := range p.Tiers {
+		if tier.MinHoursBefore < 0 || tier.RefundPercent < 0 || tier.RefundPercent > 100 {
+			return ErrInvalidCancellationPolicy
+		}
+
+		if seen[tier.MinHoursBefore] {
+			return ErrInvalidCancellationPolicy
+		}
+		seen[tier.MinHoursBefore] = true
+	}
+
+	return nil
+}
+
+// Sorted returns the policy with its tiers ordered from the earliest
+// cancellation to the latest.
+func (p CancellationPolicy) Sorted() CancellationPolicy {
+	tiers := append([]CancellationTier(nil), p.Tiers...)
+	sort.Slice(tiers, func(i, j int) bool {
+		return tiers[i].MinHoursBefore > tiers[j].MinHoursBefore
+	})
+	p.Tiers = tiers
+
+	return p
+}
+
+// RefundPercent returns the refund percentage for a booking cancelled at now.
+func (p CancellationPolicy) RefundPercent(start time.Time, now time.Time) int {
+	notice := start.Sub(now)
+	for _, tier := range p.Sorted().Tiers {
+		if notice >= time.Duration(tier.MinHoursBefore)*time.Hour {
+			return tier.RefundPercent
+		}
+	}
+
+	return 0
+}
+
+// RefundDecision is the amount returned to the guest when a booking is
+// cancelled, with the reason shown to the guest and the company.
+type RefundDecision struct {
+	Amount  int64  `json:"amount"`
+	Percent int    `json:"percent"`
+	Reason  string `json:"reason"`
+	// PlatformAmount is the part of Amount taken from the platform
+	// commission, when the policy refunds it.
+	PlatformAmount int64 `json:"-"`
+}
@@ -0,0 +1,74 @@
+package entity
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Who pays the platform commission.
+const (
+	// CommissionPaidByGuest adds the commission to the court price.
+	CommissionPaidByGuest = "guest"
+	// CommissionPaidByCompany takes the commission out of the court price.
+	CommissionPaidByCompany = "company"
+)
+
+var (
+	ErrInvalidCommissionPlan  = errors.New("invalid commission plan")
+	ErrCommissionPlanNotFound = errors.New("commission plan not found")
+)
+
+// CommissionPlan is the platform commission charged on the bookings of the
+// companies attached to it: PercentBps basis points of the court price (500
+// is 5%) plus FixedFee cents. Companies without a plan use the default one.
+type CommissionPlan struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	PercentBps int64     `json:"percent_bps"`
+	FixedFee   int64     `json:"fixed_fee"`
+	PaidBy     string    `json:"paid_by"`
+	IsDefault  bool      `json:"is_default"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func (p CommissionPlan) Validate() error {
+	if strings.TrimSpace(p.Name) == "" {
+		return ErrInvalidCommissionPlan
+	}
+	if p.PercentBps < 0 || p.PercentBps > 10000 || p.FixedFee < 0 {
+		return ErrInvalidCommissionPlan
+	}
+	if p.PaidBy != CommissionPaidByGuest && p.PaidBy != CommissionPaidByCompany {
+		return ErrInvalidCommissionPlan
+	}
+
+	return nil
+}
+
+// Commission splits a booking payment: the guest pays Total, the platform
+// keeps Fee and the company gets the rest.
+type Commission struct {
+	Total  int64  `json:"total"`
+	Fee    int64  `json:"fee"`
+	PaidBy string `json:"paid_by"`
+}
+
+func (c Commission) CompanyValue() int64 {
+	return c.Total - c.Fee
+}
+
+// GuestFee is the part of the commission added to the court price.
+func (c Commission) GuestFee() int64 {
+	if c.PaidBy == CommissionPaidByCompany {
+		return 0
+	}
+
+	return c.Fee
+}
+
+// CompanyCommissionPlan assigns a plan to a company.
+type CompanyCommissionPlan struct {
+	PlanID string `json:"plan_id"`
+}
@@ -4,6 +4,7 @@ import "errors"
 
 var (
 	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrCompanyNotFound    = errors.New("company not found")
 )
 
 type Company struct {
@@ -19,12 +20,17 @@ type Company struct {
     PixKey string `json:"pix_key"`
     PixKeyType string `json:"pix_key_type"`
 
+	PaymentProvider PaymentProvider `json:"payment_provider,omitempty"`
+
 	Courts []Court `json:"courts"`
 }
 
 type CompanyDashboard struct {
 	TotalBookings   int     `json:"total_bookings"`
 	TotalEarnings   int64 `json:"total_earnings"`
+	// TotalCommission is the platform commission on the week payments, already
+	// deducted from TotalEarnings.
+	TotalCommission int64 `json:"total_commission"`
 	TotalClients    int     `json:"total_clients"`
 	TotalBookedHours float64     `json:"total_booked_hours"`
 }
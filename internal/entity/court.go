@@ -1,6 +1,18 @@
 package entity
 
-import "time"
+import (
+	"errors"
+	"time"
+)
+
+// CourtLocation is the timezone court schedules are expressed in.
+var CourtLocation = time.FixedZone("BRT", -3*3600)
+
+var (
+	ErrCourtNotFound      = errors.New("court not found")
+	ErrInvalidDate        = errors.New("invalid date")
+	ErrInvalidGranularity = errors.New("invalid slot granularity")
+)
 
 type Court struct {
 	ID            string          `json:"id"`
@@ -16,6 +28,8 @@ type Court struct {
 	Company       *Company        `json:"company,omitempty"`
 	Photos        []CourtPhoto    `json:"photos,omitempty"`
 	CourtSchedule []CourtSchedule `json:"court_schedule"`
+	BookingPolicy *BookingPolicy  `json:"booking_policy,omitempty"`
+	PricingRules  []PricingRule   `json:"pricing_rules,omitempty"`
 }
 
 type CourtPhoto struct {
@@ -34,3 +48,59 @@ type CourtSchedule struct {
 	OpeningTime time.Time `json:"opening_time"`
 	ClosingTime time.Time `json:"closing_time"`
 }
+
+// TimeSlot is a bookable interval computed from the court schedule.
+type TimeSlot struct {
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	Price     int64     `json:"price"`
+}
+
+// Policy returns the court booking policy, or an empty policy when none is
+// loaded.
+func (c Court) Policy() BookingPolicy {
+	if c.BookingPolicy == nil {
+		return BookingPolicy{}
+	}
+
+	return *c.BookingPolicy
+}
+
+// ScheduleFor returns the schedule configured for the given weekday.
+func (c Court) ScheduleFor(weekday time.Weekday) (CourtSchedule, bool) {
+	for _, s := range c.CourtSchedule {
+		if s.Weekday == int(weekday) {
+			return s, true
+		}
+	}
+
+	return CourtSchedule{}, false
+}
+
+// OpeningHours returns the opening and closing instants of the schedule on
+// the given day. A closing time before or equal to the opening time means the
+// court closes after midnight.
+func (s CourtSchedule) OpeningHours(day time.Time) (time.Time, time.Time) {
+	return dailyWindow(day, s.OpeningTime, s.ClosingTime)
+}
+
+// dailyWindow anchors the from/to times of day on the given day in the court
+// timezone, moving the end to the next day when it is not after the start.
+func dailyWindow(day time.Time, from time.Time, to time.Time) (time.Time, time.Time) {
+	day = day.In(CourtLocation)
+	midnight := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, CourtLocation)
+
+	start := midnight.Add(timeOfDay(from))
+	end := midnight.Add(timeOfDay(to))
+	if !end.After(start) {
+		end = end.Add(24 * time.Hour)
+	}
+
+	return start, end
+}
+
+func timeOfDay(t time.Time) time.Duration {
+	return time.Duration(t.Hour())*time.Hour +
+		time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second
+}
@@ -0,0 +1,55 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidBlackout  = errors.New("invalid blackout period")
+	ErrBlackoutNotFound = errors.New("blackout not found")
+	ErrCourtBlackedOut  = errors.New("court is closed during the requested period")
+)
+
+// CourtBlackout closes a court, or every court of a company when CourtId is
+// nil, for a period of time such as holidays, tournaments or maintenance.
+type CourtBlackout struct {
+	ID        string    `json:"id"`
+	CompanyId string    `json:"company_id"`
+	CourtId   *string   `json:"court_id,omitempty"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	AllDay    bool      `json:"all_day"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Normalize expands all day blackouts to cover whole days, from the midnight
+// of StartTime to the midnight after EndTime, and validates the period.
+func (b *CourtBlackout) Normalize() error {
+	if b.StartTime.IsZero() {
+		return ErrInvalidBlackout
+	}
+
+	if b.AllDay {
+		last := b.EndTime
+		if last.IsZero() {
+			last = b.StartTime
+		}
+
+		start := b.StartTime.In(CourtLocation)
+		last = last.In(CourtLocation)
+		b.StartTime = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, CourtLocation)
+		b.EndTime = time.Date(last.Year(), last.Month(), last.Day(), 0, 0, 0, 0, CourtLocation).AddDate(0, 0, 1)
+	}
+
+	if !b.EndTime.After(b.StartTime) {
+		return ErrInvalidBlackout
+	}
+
+	return nil
+}
+
+func (b CourtBlackout) Overlaps(start time.Time, end time.Time) bool {
+	return start.Before(b.EndTime) && b.StartTime.Before(end)
+}
@@ -0,0 +1,107 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidBookingPolicy = errors.New("invalid booking policy")
+	ErrDurationNotAllowed   = errors.New("booking duration is not allowed for this court")
+	ErrMisalignedStart      = errors.New("booking start time is not aligned to the court slot granularity")
+	ErrBookingTooSoon       = errors.New("booking starts too soon")
+	ErrBookingTooFarAhead   = errors.New("booking is too far in advance")
+)
+
+// DefaultSlotDuration is the slot length offered when the court has no
+// minimum duration configured.
+const DefaultSlotDuration = time.Hour
+
+// BookingPolicy holds the per-court booking rules. A zero value in any field
+// means the rule is not enforced.
+type BookingPolicy struct {
+	MinDurationMinutes     int `json:"min_duration_minutes"`
+	MaxDurationMinutes     int `json:"max_duration_minutes"`
+	SlotGranularityMinutes int `json:"slot_granularity_minutes"`
+	LeadTimeMinutes        int `json:"lead_time_minutes"`
+	MaxAdvanceDays         int `json:"max_advance_days"`
+}
+
+func (p BookingPolicy) Validate() error {
+	if p.MinDurationMinutes < 0 || p.MaxDurationMinutes < 0 || p.SlotGranularityMinutes < 0 ||
+		p.LeadTimeMinutes < 0 || p.MaxAdvanceDays < 0 {
+		return ErrInvalidBookingPolicy
+	}
+
+	if p.MaxDurationMinutes > 0 && p.MaxDurationMinutes < p.MinDurationMinutes {
+		return ErrInvalidBookingPolicy
+	}
+
+	if p.SlotGranularityMinutes > 24*60 {
+		return ErrInvalidBookingPolicy
+	}
+
+	return nil
+}
+
+func (p BookingPolicy) Granularity() time.Duration {
+	return time.Duration(p.SlotGranularityMinutes) * time.Minute
+}
+
+// SlotDuration is the default length of the slots offered for the court.
+func (p BookingPolicy) SlotDuration() time.Duration {
+	if p.MinDurationMinutes > 0 {
+		return time.Duration(p.MinDurationMinutes) * time.Minute
+	}
+
+	return DefaultSlotDuration
+}
+
+// CheckDuration validates the booking length against the minimum, maximum and
+// granularity rules.
+func (p BookingPolicy) CheckDuration(duration time.Duration) error {
+	if p.MinDurationMinutes > 0 && duration < time.Duration(p.MinDurationMinutes)*time.Minute {
+		return ErrDurationNotAllowed
+	}
+
+	if p.MaxDurationMinutes > 0 && duration > time.Duration(p.MaxDurationMinutes)*time.Minute {
+		return ErrDurationNotAllowed
+	}
+
+	if granularity := p.Granularity(); granularity > 0 && duration%granularity != 0 {
+		return ErrDurationNotAllowed
+	}
+
+	return nil
+}
+
+// CheckAlignment validates that the start time falls on a multiple of the
+// slot granularity counted from midnight in the court timezone.
+func (p BookingPolicy) CheckAlignment(start time.Time) error {
+	granularity := p.Granularity()
+	if granularity == 0 {
+		return nil
+	}
+
+	start = start.In(CourtLocation)
+	midnight := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, CourtLocation)
+	if start.Sub(midnight)%granularity != 0 {
+		return ErrMisalignedStart
+	}
+
+	return nil
+}
+
+// CheckWindow validates the lead time and how far in advance the booking is
+// made. It only applies to bookings made by customers.
+func (p BookingPolicy) CheckWindow(start time.Time, now time.Time) error {
+	if p.LeadTimeMinutes > 0 && start.Before(now.Add(time.Duration(p.LeadTimeMinutes)*time.Minute)) {
+		return ErrBookingTooSoon
+	}
+
+	if p.MaxAdvanceDays > 0 && start.After(now.AddDate(0, 0, p.MaxAdvanceDays)) {
+		return ErrBookingTooFarAhead
+	}
+
+	return nil
+}
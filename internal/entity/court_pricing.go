@@ -0,0 +1,85 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidPricingRule = errors.New("invalid pricing rule")
+	ErrInvalidQuote       = errors.New("invalid price quote request")
+)
+
+// PricingRule overrides the court hourly price inside a daily time range. A
+// nil Weekday applies the rule every day. When rules overlap the one with the
+// highest Priority wins, and weekday specific rules win over daily ones with
+// the same priority.
+type PricingRule struct {
+	ID          string    `json:"id"`
+	CourtId     string    `json:"court_id"`
+	Weekday     *int      `json:"weekday,omitempty"`
+	StartTime   time.Time `json:"start_time"`
+	EndTime     time.Time `json:"end_time"`
+	HourlyPrice int64     `json:"hourly_price"`
+	Priority    int       `json:"priority"`
+}
+
+func (r PricingRule) Validate() error {
+	if r.Weekday != nil && (*r.Weekday < 0 || *r.Weekday > 6) {
+		return ErrInvalidPricingRule
+	}
+
+	if r.HourlyPrice < 0 {
+		return ErrInvalidPricingRule
+	}
+
+	return nil
+}
+
+// Window returns the interval the rule covers when anchored on the given day.
+// An end time before or equal to the start time means the range goes past
+// midnight.
+func (r PricingRule) Window(day time.Time) (time.Time, time.Time) {
+	return dailyWindow(day, r.StartTime, r.EndTime)
+}
+
+// AppliesOn reports whether the rule is active for windows starting on day.
+func (r PricingRule) AppliesOn(day time.Time) bool {
+	return r.Weekday == nil || *r.Weekday == int(day.In(CourtLocation).Weekday())
+}
+
+// Outranks reports whether r takes precedence over other.
+func (r PricingRule) Outranks(other PricingRule) bool {
+	if r.Priority != other.Priority {
+		return r.Priority > other.Priority
+	}
+
+	return r.Weekday != nil && other.Weekday == nil
+}
+
+// PriceSegment is a part of a booking charged at a single hourly rate.
+type PriceSegment struct {
+	StartTime   time.Time `json:"start_time"`
+	EndTime     time.Time `json:"end_time"`
+	HourlyPrice int64     `json:"hourly_price"`
+	Price       int64     `json:"price"`
+	RuleId      *string   `json:"rule_id,omitempty"`
+}
+
+// PriceQuote is the price of a court booking broken down by pricing rule,
+// plus the platform fee charged to the guest on top of the court price.
+type PriceQuote struct {
+	CourtId     string         `json:"court_id"`
+	StartTime   time.Time      `json:"start_time"`
+	EndTime     time.Time      `json:"end_time"`
+	Segments    []PriceSegment `json:"segments"`
+	CourtPrice  int64          `json:"court_price"`
+	PlatformFee int64          `json:"platform_fee"`
+	Total       int64          `json:"total"`
+}
+
+// ApplyFee sets the platform fee and updates the total paid by the guest.
+func (q *PriceQuote) ApplyFee(fee int64) {
+	q.PlatformFee = fee
+	q.Total = q.CourtPrice + fee
+}
@@ -0,0 +1,153 @@
+package entity
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Company statuses operators filter by.
+const (
+	CompanyStatusActive    = "active"
+	CompanyStatusSuspended = "suspended"
+)
+
+var (
+	ErrCompanySuspended      = errors.New("company is suspended")
+	ErrInvalidOperator       = errors.New("operator name, email and password are required")
+	ErrInvalidCompanyFilter  = errors.New("invalid company filter")
+	ErrInvalidRevenuePeriod  = errors.New("invalid revenue period")
+	ErrInvalidAuditLogFilter = errors.New("invalid audit log filter")
+	ErrReasonRequired        = errors.New("a reason is required")
+)
+
+// ImpersonationDuration is how long the company token handed to an operator
+// for support stays valid.
+const ImpersonationDuration = time.Hour
+
+// Operator is a member of the platform team. Operators manage every company
+// and are not attached to any of them.
+type Operator struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Password  string    `json:"password,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CompanyFilter searches companies by name, email, slug or CNPJ.
+type CompanyFilter struct {
+	Query  string
+	Status string
+	Limit  int
+	Offset int
+}
+
+func (f CompanyFilter) Validate() error {
+	switch f.Status {
+	case "", CompanyStatusActive, CompanyStatusSuspended:
+	default:
+		return ErrInvalidCompanyFilter
+	}
+	if f.Limit < 1 || f.Limit > 100 || f.Offset < 0 {
+		return ErrInvalidCompanyFilter
+	}
+
+	return nil
+}
+
+// CompanyOverview is what operators see of a company.
+type CompanyOverview struct {
+	ID               string          `json:"id"`
+	Name             string          `json:"name"`
+	Slug             string          `json:"slug"`
+	Email            string          `json:"email"`
+	Phone            string          `json:"phone"`
+	CNPJ             string          `json:"cnpj"`
+	PaymentProvider  PaymentProvider `json:"payment_provider"`
+	CommissionPlanID string          `json:"commission_plan_id,omitempty"`
+	Status           string          `json:"status"`
+	SuspendedAt      *time.Time      `json:"suspended_at,omitempty"`
+	SuspensionReason string          `json:"suspension_reason,omitempty"`
+	Courts           int             `json:"courts"`
+	Bookings         int             `json:"bookings"`
+}
+
+// CompanyPage is a page of the company search with the total of matches.
+type CompanyPage struct {
+	Companies []CompanyOverview `json:"companies"`
+	Total     int               `json:"total"`
+}
+
+// ProviderRevenue sums the payments of one provider paid in a period, in
+// cents. Commission is net of the commission returned with the refunds of the
+// period.
+type ProviderRevenue struct {
+	Provider    PaymentProvider `json:"provider"`
+	Payments    int             `json:"payments"`
+	Volume      int64           `json:"volume"`
+	Commission  int64           `json:"commission"`
+	GatewayFees int64           `json:"gateway_fees"`
+	Refunded    int64           `json:"refunded"`
+}
+
+// PlatformRevenue is the platform income in [From, To). Net is the commission
+// left after the gateway fees.
+type PlatformRevenue struct {
+	From        time.Time         `json:"from"`
+	To          time.Time         `json:"to"`
+	Payments    int               `json:"payments"`
+	Volume      int64             `json:"volume"`
+	Commission  int64             `json:"commission"`
+	GatewayFees int64             `json:"gateway_fees"`
+	Refunded    int64             `json:"refunded"`
+	Net         int64             `json:"net"`
+	Providers   []ProviderRevenue `json:"providers"`
+}
+
+func NewPlatformRevenue(from, to time.Time, providers []ProviderRevenue) PlatformRevenue {
+	revenue := PlatformRevenue{
+		From:      from,
+		To:        to,
+		Providers: providers,
+	}
+	for _, p := range providers {
+		revenue.Payments += p.Payments
+		revenue.Volume += p.Volume
+		revenue.Commission += p.Commission
+		revenue.GatewayFees += p.GatewayFees
+		revenue.Refunded += p.Refunded
+	}
+	revenue.Net = revenue.Commission - revenue.GatewayFees
+
+	return revenue
+}
+
+// AuditLogEntry is a request made by an operator. CompanyID is the company
+// the request acted on, directly or by impersonating it.
+type AuditLogEntry struct {
+	ID         string          `json:"id"`
+	OperatorID string          `json:"operator_id"`
+	CompanyID  string          `json:"company_id,omitempty"`
+	Action     string          `json:"action"`
+	TargetID   string          `json:"target_id,omitempty"`
+	StatusCode int             `json:"status_code"`
+	Details    json.RawMessage `json:"details,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// AuditLogFilter selects the entries of an operator, a company or both.
+type AuditLogFilter struct {
+	OperatorID string
+	CompanyID  string
+	Limit      int
+	Offset     int
+}
+
+func (f AuditLogFilter) Validate() error {
+	if f.Limit < 1 || f.Limit > 500 || f.Offset < 0 {
+		return ErrInvalidAuditLogFilter
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type OutboxStatus string
+
+const (
+	OutboxPending OutboxStatus = "pending"
+	OutboxSent    OutboxStatus = "sent"
+	OutboxDead    OutboxStatus = "dead"
+)
+
+// MaxOutboxAttempts is the number of delivery attempts before a message is
+// dead-lettered.
+const MaxOutboxAttempts = 8
+
+const (
+	outboxBaseBackoff = 30 * time.Second
+	outboxMaxBackoff  = time.Hour
+)
+
+// OutboxMessage is an email written in the same transaction as the state
+// change that triggers it and delivered later by the outbox dispatcher.
+type OutboxMessage struct {
+	ID            string          `json:"id"`
+	BookingId     string          `json:"booking_id"`
+	Template      string          `json:"template"`
+	Subject       string          `json:"subject"`
+	Recipient     string          `json:"recipient"`
+	Payload       json.RawMessage `json:"-"`
+	Status        OutboxStatus    `json:"status"`
+	Attempts      int             `json:"attempts"`
+	LastError     string          `json:"last_error,omitempty"`
+	NextAttemptAt time.Time       `json:"next_attempt_at"`
+	SentAt        *time.Time      `json:"sent_at,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// NewBookingEmail builds an outbox message for a booking email template.
+func NewBookingEmail(template string, subject string, info BookingConfirmationInfo) (OutboxMessage, error) {
+	payload, err := json.Marshal(info)
+	if err != nil {
+		return OutboxMessage{}, err
+	}
+
+	return OutboxMessage{
+		BookingId: info.ID,
+		Template:  template,
+		Subject:   subject,
+		Recipient: info.GuestEmail,
+		Payload:   payload,
+		Status:    OutboxPending,
+	}, nil
+}
+
+// NewCompanyEmail builds an email about the booking addressed to the company
+// instead of the guest.
+func NewCompanyEmail(template string, subject string, recipient string, info BookingConfirmationInfo) (OutboxMessage, error) {
+	message, err := NewBookingEmail(template, subject, info)
+	if err != nil {
+		return OutboxMessage{}, err
+	}
+	message.Recipient = recipient
+
+	return message, nil
+}
+
+// OutboxBackoff returns the delay before the next attempt once the message
+// failed the given number of times, doubling from 30 seconds up to an hour.
+func OutboxBackoff(attempts int) time.Duration {
+	backoff := outboxBaseBackoff
+	for i := 1; i < attempts && backoff < outboxMaxBackoff; i++ {
+		backoff *= 2
+	}
+
+	return min(backoff, outboxMaxBackoff)
+}
@@ -1,6 +1,20 @@
 package entity
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
+
+// Payment statuses stored in payments.status.
+const (
+	PaymentStatusPending      = "pending"
+	PaymentStatusPaid         = "paid"
+	PaymentStatusExpired      = "expired"
+	PaymentStatusMismatch     = "mismatch"
+	PaymentStatusRefunding    = "refunding"
+	PaymentStatusRefunded     = "refunded"
+	PaymentStatusRefundFailed = "refund_failed"
+)
 
 type Payment struct {
 	ID                string    `json:"id"`
@@ -14,15 +28,33 @@ type Payment struct {
 	ValueTotal        int64     `json:"value_total"`
 	ValueCommission   int64     `json:"value_commission"`
 	ValueCompany      int64     `json:"value_company"`
+	CommissionPaidBy  string    `json:"commission_paid_by,omitempty"`
+	ValueReceived     int64     `json:"value_received,omitempty"`
+	ValueDelta        int64     `json:"value_delta,omitempty"`
+	Provider          PaymentProvider `json:"provider"`
 	Status            string    `json:"status"`
 	ExpiresAt         time.Time `json:"expires_at"`
 	PaidAt            time.Time `json:"paid_at,omitempty"`
 	RefundedAt        time.Time `json:"refunded_at,omitempty"`
 	RefundRequestedAt time.Time `json:"refund_requested_at,omitempty"`
 	RefundEndToEndID  string    `json:"refund_end_to_end_id,omitempty"`
+	RefundedValue     int64     `json:"refunded_value,omitempty"`
+	RefundedPlatformValue int64 `json:"refunded_platform_value,omitempty"`
+	RefundCorrelationID string  `json:"refund_correlation_id,omitempty"`
+	RefundFailureReason string  `json:"refund_failure_reason,omitempty"`
 	CreatedAt         time.Time `json:"created_at"`
 }
 
+// NextRefundCorrelationID names the next refund of the payment. Each partial
+// refund gets its own id, while retrying the same refund keeps it.
+func (p Payment) NextRefundCorrelationID() string {
+	if p.RefundedValue == 0 {
+		return "refund-" + p.ID
+	}
+
+	return fmt.Sprintf("refund-%s-%d", p.ID, p.RefundedValue)
+}
+
 type Subaccount struct {
 	ID           string    `json:"id"`
 	CompanyID    string    `json:"company_id"`
@@ -0,0 +1,90 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// PaymentProvider identifies the gateway that processes a company payments.
+type PaymentProvider string
+
+const (
+	ProviderOpenPix PaymentProvider = "openpix"
+	ProviderCard    PaymentProvider = "card"
+)
+
+// Charge statuses reported by the gateway adapters.
+const (
+	ChargeActive    = "active"
+	ChargeCompleted = "completed"
+	ChargeExpired   = "expired"
+)
+
+// Refund statuses reported by the gateway adapters.
+const (
+	RefundPending   = "pending"
+	RefundCompleted = "completed"
+	RefundFailed    = "failed"
+)
+
+var (
+	ErrUnsupportedProvider    = errors.New("unsupported payment provider")
+	ErrPaymentAccountNotFound = errors.New("payment account not found")
+)
+
+func (p PaymentProvider) IsValid() bool {
+	return p == ProviderOpenPix || p == ProviderCard
+}
+
+// Charge is a payment request created on a gateway for a booking. Status is
+// one of the Charge* statuses, or the raw provider status when it has no
+// equivalent.
+type Charge struct {
+	Provider       PaymentProvider `json:"provider"`
+	ChargeID       string          `json:"charge_id"`
+	CorrelationID  string          `json:"correlation_id"`
+	Status         string          `json:"status"`
+	Value          int64           `json:"value"`
+	Fee            int64           `json:"fee"`
+	PaymentLinkURL string          `json:"payment_link_url"`
+	QrCodeImage    string          `json:"qr_code_image,omitempty"`
+	BrCode         string          `json:"brcode,omitempty"`
+	ExpiresAt      time.Time       `json:"expires_at"`
+	PaidAt         time.Time       `json:"paid_at,omitempty"`
+	// GatewayFee is what the provider kept for processing the payment, when
+	// it reports it.
+	GatewayFee int64 `json:"gateway_fee,omitempty"`
+	// ValueReceived is what the payer actually sent, which may differ from
+	// the requested Value. Zero means the provider didn't report it.
+	ValueReceived int64 `json:"value_received,omitempty"`
+	// ValueRefunded is what the provider already returned to the payer,
+	// including refunds made outside the platform. Only filled by ListCharges.
+	ValueRefunded int64 `json:"value_refunded,omitempty"`
+}
+
+// Refund is a gateway refund of a paid charge. Status is one of the Refund*
+// statuses.
+type Refund struct {
+	ID            string    `json:"id"`
+	CorrelationID string    `json:"correlation_id"`
+	EndToEndID    string    `json:"end_to_end_id"`
+	Value         int64     `json:"value"`
+	Status        string    `json:"status"`
+	FailureReason string    `json:"failure_reason,omitempty"`
+	RefundedAt    time.Time `json:"refunded_at"`
+	// PlatformValue is the part of Value returned out of the platform
+	// commission rather than the company share.
+	PlatformValue int64 `json:"platform_value,omitempty"`
+}
+
+// PaymentStatus returns the payment status matching the refund status.
+func (r Refund) PaymentStatus() string {
+	switch r.Status {
+	case RefundCompleted:
+		return PaymentStatusRefunded
+	case RefundFailed:
+		return PaymentStatusRefundFailed
+	default:
+		return PaymentStatusRefunding
+	}
+}
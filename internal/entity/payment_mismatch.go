@@ -0,0 +1,66 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrPaymentMismatchNotFound   = errors.New("payment mismatch not found")
+	ErrInvalidMismatchResolution = errors.New("invalid payment mismatch resolution")
+	ErrPaymentMismatchUnresolved = errors.New("the payment was received with the wrong value and must be resolved by the company first")
+	ErrChargeValueUnknown        = errors.New("the gateway did not report the value received")
+)
+
+// Ways a company can settle a payment received with the wrong value.
+const (
+	// MismatchAccept keeps the amount received and confirms the booking.
+	MismatchAccept = "accept"
+	// MismatchRefund gives the amount received back and cancels the booking.
+	MismatchRefund = "refund"
+)
+
+// PaymentMismatch is a payment whose received value differs from the booking
+// total. ValueDelta is negative for underpayments.
+type PaymentMismatch struct {
+	PaymentID       string          `json:"payment_id"`
+	BookingID       string          `json:"booking_id"`
+	CorrelationID   string          `json:"correlation_id"`
+	Provider        PaymentProvider `json:"provider"`
+	GuestName       string          `json:"guest_name"`
+	GuestEmail      string          `json:"guest_email"`
+	CourtName       string          `json:"court_name"`
+	StartTime       time.Time       `json:"start_time"`
+	EndTime         time.Time       `json:"end_time"`
+	ValueTotal      int64           `json:"value_total"`
+	ValueCommission int64           `json:"value_commission"`
+	ValueReceived   int64           `json:"value_received"`
+	ValueDelta      int64           `json:"value_delta"`
+	PaidAt          time.Time       `json:"paid_at"`
+}
+
+// Payment returns the payment fields the gateways need to refund it.
+func (m PaymentMismatch) Payment() Payment {
+	return Payment{
+		ID:              m.PaymentID,
+		BookingID:       m.BookingID,
+		CorrelationID:   m.CorrelationID,
+		Provider:        m.Provider,
+		ValueTotal:      m.ValueTotal,
+		ValueCommission: m.ValueCommission,
+		ValueReceived:   m.ValueReceived,
+		Status:          PaymentStatusMismatch,
+	}
+}
+
+type MismatchResolution struct {
+	Action string `json:"action"`
+}
+
+func (r MismatchResolution) Validate() error {
+	if r.Action != MismatchAccept && r.Action != MismatchRefund {
+		return ErrInvalidMismatchResolution
+	}
+
+	return nil
+}
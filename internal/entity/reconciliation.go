@@ -0,0 +1,81 @@
+package entity
+
+import "time"
+
+// Issues found when comparing payments with the gateway charges.
+const (
+	// ReconciliationPaidRemotely is a charge paid on the gateway whose payment
+	// is still pending. It is fixed by confirming the payment.
+	ReconciliationPaidRemotely = "paid_remotely"
+	// ReconciliationValueMismatch is a charge paid with a value different from
+	// the one expected. Pending payments are moved to the mismatch status.
+	ReconciliationValueMismatch = "value_mismatch"
+	// ReconciliationValueUnknown is a charge paid on the gateway that didn't
+	// report the value received. It is left for a manual check.
+	ReconciliationValueUnknown = "value_unknown"
+	// ReconciliationPaidAfterExpiry is a charge paid after its payment
+	// expired. It is fixed by refunding the payment.
+	ReconciliationPaidAfterExpiry = "paid_after_expiry"
+	// ReconciliationRefundedRemotely is a charge refunded on the gateway, for
+	// example from its dashboard, whose payment is still paid. It is left for
+	// a manual check.
+	ReconciliationRefundedRemotely = "refunded_remotely"
+	ReconciliationNotPaidRemotely  = "not_paid_remotely"
+	ReconciliationExpiredRemotely  = "expired_remotely"
+	ReconciliationMissingLocally   = "missing_locally"
+	ReconciliationMissingRemotely  = "missing_remotely"
+)
+
+// Actions taken by the reconciliation on a payment.
+const (
+	ReconciliationActionConfirmed      = "confirmed"
+	ReconciliationActionMarkedMismatch = "marked_mismatch"
+	ReconciliationActionRefunded       = "refunded"
+)
+
+// ReconciliationItem is a payment whose state differs from the gateway.
+// LocalValue is the booking total, RemoteValue what the gateway received and
+// RemoteRefunded what it already returned to the payer.
+type ReconciliationItem struct {
+	Provider       PaymentProvider `json:"provider"`
+	CorrelationID  string          `json:"correlation_id"`
+	PaymentID      string          `json:"payment_id,omitempty"`
+	Issue          string          `json:"issue"`
+	LocalStatus    string          `json:"local_status,omitempty"`
+	RemoteStatus   string          `json:"remote_status,omitempty"`
+	LocalValue     int64           `json:"local_value"`
+	RemoteValue    int64           `json:"remote_value"`
+	RemoteRefunded int64           `json:"remote_refunded,omitempty"`
+	Action         string          `json:"action,omitempty"`
+	Error          string          `json:"error,omitempty"`
+}
+
+// Fixed reports whether the reconciliation brought the payment in line with
+// the gateway.
+func (i ReconciliationItem) Fixed() bool {
+	return i.Action == ReconciliationActionConfirmed
+}
+
+// ReconciliationReport lists the differences found between the payments and
+// the gateway charges created in the window.
+type ReconciliationReport struct {
+	From    time.Time            `json:"from"`
+	To      time.Time            `json:"to"`
+	Checked int                  `json:"checked"`
+	Fixed   int                  `json:"fixed"`
+	Items   []ReconciliationItem `json:"items"`
+	// Errors lists the providers that could not be reconciled.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// Mismatches returns the items that still need someone to look at them.
+func (r ReconciliationReport) Mismatches() []ReconciliationItem {
+	var mismatches []ReconciliationItem
+	for _, item := range r.Items {
+		if !item.Fixed() {
+			mismatches = append(mismatches, item)
+		}
+	}
+
+	return mismatches
+}
@@ -0,0 +1,117 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// Ledger entry types.
+const (
+	LedgerCharge           = "charge"
+	LedgerCommission       = "commission"
+	LedgerGatewayFee       = "gateway_fee"
+	LedgerRefund           = "refund"
+	LedgerCommissionRefund = "commission_refund"
+	LedgerWithdrawal       = "withdrawal"
+)
+
+// Ledger accounts money moves between. Only the company account is kept per
+// company; the others are the counterparts of its entries.
+const (
+	LedgerAccountGuest    = "guest"
+	LedgerAccountCompany  = "company"
+	LedgerAccountPlatform = "platform"
+	LedgerAccountGateway  = "gateway"
+	LedgerAccountBank     = "bank"
+)
+
+var ErrInvalidStatementPeriod = errors.New("invalid statement period")
+
+// MaxStatementPeriod bounds a single statement to keep exports small.
+const MaxStatementPeriod = 366 * 24 * time.Hour
+
+// LedgerEntry moves Amount cents from the Debit account to the Credit
+// account.
+type LedgerEntry struct {
+	ID          string    `json:"id"`
+	OccurredAt  time.Time `json:"occurred_at"`
+	Type        string    `json:"type"`
+	ReferenceID string    `json:"reference_id"`
+	BookingID   string    `json:"booking_id,omitempty"`
+	Debit       string    `json:"debit"`
+	Credit      string    `json:"credit"`
+	Amount      int64     `json:"amount"`
+	// Balance is the company balance after the entry.
+	Balance int64 `json:"balance"`
+}
+
+// CompanyEffect is how much the entry adds to, or takes from, the company
+// balance.
+func (e LedgerEntry) CompanyEffect() int64 {
+	switch {
+	case e.Credit == LedgerAccountCompany:
+		return e.Amount
+	case e.Debit == LedgerAccountCompany:
+		return -e.Amount
+	}
+
+	return 0
+}
+
+// StatementFilter selects the entries that occurred in [From, To).
+type StatementFilter struct {
+	From time.Time
+	To   time.Time
+}
+
+func (f StatementFilter) Validate() error {
+	if f.From.IsZero() || f.To.IsZero() || !f.From.Before(f.To) {
+		return ErrInvalidStatementPeriod
+	}
+	if f.To.Sub(f.From) > MaxStatementPeriod {
+		return ErrInvalidStatementPeriod
+	}
+
+	return nil
+}
+
+// Statement is the company ledger for a period, with the balance carried
+// over from before it.
+type Statement struct {
+	CompanyID      string        `json:"company_id"`
+	CompanyName    string        `json:"company_name"`
+	From           time.Time     `json:"from"`
+	To             time.Time     `json:"to"`
+	OpeningBalance int64         `json:"opening_balance"`
+	ClosingBalance int64         `json:"closing_balance"`
+	Credits        int64         `json:"credits"`
+	Debits         int64         `json:"debits"`
+	Entries        []LedgerEntry `json:"entries"`
+}
+
+// NewStatement fills the running balances and totals of entries, which must be
+// sorted by OccurredAt.
+func NewStatement(companyId string, filter StatementFilter, openingBalance int64, entries []LedgerEntry) Statement {
+	statement := Statement{
+		CompanyID:      companyId,
+		From:           filter.From,
+		To:             filter.To,
+		OpeningBalance: openingBalance,
+		Entries:        entries,
+	}
+
+	balance := openingBalance
+	for i := range statement.Entries {
+		effect := statement.Entries[i].CompanyEffect()
+		if effect > 0 {
+			statement.Credits += effect
+		} else {
+			statement.Debits -= effect
+		}
+		balance += effect
+		statement.Entries[i].Balance = balance
+	}
+	statement.ClosingBalance = balance
+
+	return statement
+}
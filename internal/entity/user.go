@@ -0,0 +1,180 @@
+package entity
+
+import (
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+)
+
+// UserRole is the role of a staff account in its company.
+type UserRole string
+
+const (
+	RoleOwner     UserRole = "owner"
+	RoleManager   UserRole = "manager"
+	RoleFrontDesk UserRole = "front_desk"
+	// RoleSupport is the role of the tokens platform operators get when they
+	// impersonate a company. It can't withdraw the company money and isn't
+	// given to staff accounts.
+	RoleSupport UserRole = "support"
+)
+
+// Permission is an action on the admin API that depends on the user role.
+type Permission string
+
+const (
+	// PermissionManageBookings covers creating, confirming and cancelling
+	// bookings.
+	PermissionManageBookings Permission = "bookings:manage"
+	// PermissionManageCourts covers courts, blackouts and pricing rules.
+	PermissionManageCourts Permission = "courts:manage"
+	// PermissionViewFinances covers the dashboard, balance, statement and
+	// payment issues.
+	PermissionViewFinances Permission = "finances:view"
+	// PermissionWithdraw covers withdrawals and payout schedules.
+	PermissionWithdraw Permission = "finances:withdraw"
+	// PermissionManagePayments covers payment mismatch resolutions, webhook
+	// replays and full refunds beyond the cancellation policy, which move
+	// money or change payment statuses.
+	PermissionManagePayments Permission = "payments:manage"
+	// PermissionManageCompany covers the company information and
+	// cancellation policy.
+	PermissionManageCompany Permission = "company:manage"
+	// PermissionManageUsers covers inviting and removing staff.
+	PermissionManageUsers Permission = "users:manage"
+)
+
+var rolePermissions = map[UserRole][]Permission{
+	RoleOwner: {
+		PermissionManageBookings,
+		PermissionManageCourts,
+		PermissionViewFinances,
+		PermissionWithdraw,
+		PermissionManagePayments,
+		PermissionManageCompany,
+		PermissionManageUsers,
+	},
+	RoleManager: {
+		PermissionManageBookings,
+		PermissionManageCourts,
+		PermissionViewFinances,
+	},
+	RoleFrontDesk: {
+		PermissionManageBookings,
+	},
+	RoleSupport: {
+		PermissionManageBookings,
+		PermissionManageCourts,
+		PermissionViewFinances,
+		PermissionManagePayments,
+		PermissionManageCompany,
+		PermissionManageUsers,
+	},
+}
+
+func (r UserRole) IsValid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+// IsStaff reports whether the role can be given to a staff account.
+func (r UserRole) IsStaff() bool {
+	return r.IsValid() && r != RoleSupport
+}
+
+func (r UserRole) Can(permission Permission) bool {
+	return slices.Contains(rolePermissions[r], permission)
+}
+
+func (r UserRole) Permissions() []Permission {
+	return rolePermissions[r]
+}
+
+// Label is the role name shown to users.
+func (r UserRole) Label() string {
+	switch r {
+	case RoleOwner:
+		return "Proprietário"
+	case RoleManager:
+		return "Gerente"
+	case RoleFrontDesk:
+		return "Recepção"
+	case RoleSupport:
+		return "Suporte"
+	}
+
+	return string(r)
+}
+
+var (
+	ErrUserNotFound       = errors.New("user not found")
+	ErrInvalidUser        = errors.New("name, email and password are required")
+	ErrInvalidUserRole    = errors.New("invalid user role")
+	ErrEmailAlreadyInUse  = errors.New("email already in use")
+	ErrInvitationNotFound = errors.New("invitation not found or expired")
+	ErrLastOwner          = errors.New("the company must keep at least one owner")
+)
+
+// InvitationDuration is how long an invitation can be accepted.
+const InvitationDuration = 7 * 24 * time.Hour
+
+// User is a staff account of a company. Bookings made from the admin API
+// record the user that created them.
+type User struct {
+	ID        string    `json:"id"`
+	CompanyID string    `json:"company_id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Password  string    `json:"password,omitempty"`
+	Role      UserRole  `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Invitation lets the owner of Email join the company with Role. Only the
+// hash of the token sent by email is stored.
+type Invitation struct {
+	ID         string     `json:"id"`
+	CompanyID  string     `json:"company_id"`
+	Email      string     `json:"email"`
+	Role       UserRole   `json:"role"`
+	InvitedBy  string     `json:"invited_by,omitempty"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	TokenHash  string     `json:"-"`
+}
+
+// InvitationEmailInfo is the data of the invitation email.
+type InvitationEmailInfo struct {
+	CompanyName string
+	Role        string
+	Token       string
+	ExpiresAt   string
+}
+
+// Staff lists the users of a company and the invitations still pending.
+type Staff struct {
+	Users       []User       `json:"users"`
+	Invitations []Invitation `json:"invitations"`
+}
+
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func GenerateInvitationToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := crand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func HashInvitationToken(token string) string {
+	h := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(h[:])
+}
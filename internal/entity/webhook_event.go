@@ -0,0 +1,50 @@
+package entity
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+type WebhookEventStatus string
+
+const (
+	WebhookEventPending    WebhookEventStatus = "pending"
+	WebhookEventProcessing WebhookEventStatus = "processing"
+	WebhookEventProcessed  WebhookEventStatus = "processed"
+	WebhookEventFailed     WebhookEventStatus = "failed"
+)
+
+// Webhook event types handled by the API. Each provider adapter maps its own
+// event names to these.
+const (
+	WebhookChargeCompleted = "CHARGE_COMPLETED"
+	WebhookChargeExpired   = "CHARGE_EXPIRED"
+	WebhookRefundCompleted = "REFUND_COMPLETED"
+	WebhookRefundFailed    = "REFUND_FAILED"
+)
+
+var (
+	ErrWebhookEventNotFound        = errors.New("webhook event not found")
+	ErrWebhookEventNotReplayable   = errors.New("webhook event is not in a replayable state")
+	ErrUnsupportedWebhookEventType = errors.New("unsupported webhook event type")
+	ErrInvalidWebhookPayload       = errors.New("invalid webhook payload")
+)
+
+// WebhookEvent is a gateway callback recorded before processing. Events are
+// unique by Provider, EventKey and EventType so deliveries retried by the gateway are
+// acknowledged without running their side effects again.
+type WebhookEvent struct {
+	ID          string             `json:"id"`
+	CompanyId   *string            `json:"company_id,omitempty"`
+	Provider    PaymentProvider    `json:"provider"`
+	EventKey    string             `json:"event_key"`
+	EventType   string             `json:"event_type"`
+	Payload     json.RawMessage    `json:"payload"`
+	Status      WebhookEventStatus `json:"status"`
+	Attempts    int                `json:"attempts"`
+	LastError   string             `json:"last_error,omitempty"`
+	ReceivedAt  time.Time          `json:"received_at"`
+	ProcessedAt *time.Time         `json:"processed_at,omitempty"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+}
@@ -0,0 +1,76 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// MinWithdrawalAmount is the smallest amount, in cents, a company can
+// withdraw. Smaller transfers cost more in gateway fees than they move.
+const MinWithdrawalAmount int64 = 1000
+
+// Withdrawal statuses reported by the gateway adapters.
+const (
+	WithdrawalPending   = "pending"
+	WithdrawalCompleted = "completed"
+	WithdrawalFailed    = "failed"
+)
+
+// How a withdrawal was requested.
+const (
+	WithdrawalManual    = "manual"
+	WithdrawalAutomatic = "automatic"
+)
+
+var (
+	ErrWithdrawalBelowMinimum = errors.New("withdrawal amount is below the minimum")
+	ErrInsufficientBalance    = errors.New("withdrawal amount is above the available balance")
+	ErrInvalidPayoutSchedule  = errors.New("invalid payout schedule")
+)
+
+// Withdrawal moves the company balance on the gateway to its bank account.
+type Withdrawal struct {
+	ID            string          `json:"id"`
+	CompanyID     string          `json:"company_id"`
+	Provider      PaymentProvider `json:"provider"`
+	CorrelationID string          `json:"correlation_id"`
+	Value         int64           `json:"value"`
+	Status        string          `json:"status"`
+	FailureReason string          `json:"failure_reason,omitempty"`
+	Trigger       string          `json:"trigger"`
+	CreatedAt     time.Time       `json:"created_at"`
+	CompletedAt   time.Time       `json:"completed_at,omitempty"`
+	// AccountID is the gateway account the withdrawal was made from.
+	AccountID string `json:"-"`
+}
+
+// WithdrawalRequest asks for Amount cents of the balance. A zero amount
+// withdraws the whole balance.
+type WithdrawalRequest struct {
+	Amount int64 `json:"amount"`
+}
+
+func (r WithdrawalRequest) Validate() error {
+	if r.Amount < 0 || (r.Amount > 0 && r.Amount < MinWithdrawalAmount) {
+		return ErrWithdrawalBelowMinimum
+	}
+
+	return nil
+}
+
+// PayoutSchedule withdraws the whole balance every week on Weekday, in the
+// court time zone, when it reaches the minimum withdrawal amount.
+type PayoutSchedule struct {
+	CompanyId    string       `json:"company_id"`
+	Enabled      bool         `json:"enabled"`
+	Weekday      time.Weekday `json:"weekday"`
+	LastPayoutAt time.Time    `json:"last_payout_at,omitempty"`
+}
+
+func (s PayoutSchedule) Validate() error {
+	if s.Weekday < time.Sunday || s.Weekday > time.Saturday {
+		return ErrInvalidPayoutSchedule
+	}
+
+	return nil
+}
@@ -0,0 +1,359 @@
+package card
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/dinizgab/booking-mvp/internal/config"
+	"github.com/dinizgab/booking-mvp/internal/entity"
+	"github.com/dinizgab/booking-mvp/internal/ports"
+)
+
+// gateway talks to a card acquirer with a Stripe / Mercado Pago style REST
+// API: companies are connected accounts, bookings are paid through hosted
+// checkout sessions and the platform fee is kept as an application fee.
+type gateway struct {
+	baseURL    string
+	secretKey  string
+	httpClient *http.Client
+}
+
+func NewGateway(cfg *config.CardConfig) ports.PaymentGateway {
+	return &gateway{
+		baseURL:    cfg.BaseURL,
+		secretKey:  cfg.SecretKey,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+type account struct {
+	ID       string `json:"id,omitempty"`
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Document string `json:"document"`
+}
+
+type checkoutSession struct {
+	ID             string   `json:"id,omitempty"`
+	Reference      string   `json:"reference"`
+	Amount         int64    `json:"amount"`
+	ApplicationFee int64    `json:"application_fee"`
+	ProcessingFee  int64    `json:"processing_fee,omitempty"`
+	AmountRefunded int64    `json:"amount_refunded,omitempty"`
+	Currency       string   `json:"currency"`
+	Destination    string   `json:"destination"`
+	Customer       customer `json:"customer"`
+	ExpiresAt      int64    `json:"expires_at"`
+	Status         string   `json:"status,omitempty"`
+	URL            string   `json:"url,omitempty"`
+	PaidAt         int64    `json:"paid_at,omitempty"`
+	Created        int64    `json:"created,omitempty"`
+}
+
+type sessionList struct {
+	Data    []checkoutSession `json:"data"`
+	HasMore bool              `json:"has_more"`
+}
+
+type customer struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Phone string `json:"phone"`
+}
+
+type balance struct {
+	Available int64 `json:"available"`
+}
+
+type payout struct {
+	ID            string `json:"id,omitempty"`
+	Amount        int64  `json:"amount,omitempty"`
+	Status        string `json:"status,omitempty"`
+	FailureReason string `json:"failure_reason,omitempty"`
+	ArrivedAt     int64  `json:"arrived_at,omitempty"`
+}
+
+type refund struct {
+	ID            string `json:"id,omitempty"`
+	Reference     string `json:"reference"`
+	Amount        int64  `json:"amount"`
+	Status        string `json:"status,omitempty"`
+	FailureReason string `json:"failure_reason,omitempty"`
+	CreatedAt     int64  `json:"created_at,omitempty"`
+}
+
+func (g *gateway) Provider() entity.PaymentProvider {
+	return entity.ProviderCard
+}
+
+func (g *gateway) CreateAccount(ctx context.Context, company entity.Company) (string, error) {
+	var out account
+	err := g.do(ctx, http.MethodPost, "/v1/accounts", account{
+		Name:     company.Name,
+		Email:    company.Email,
+		Document: company.CNPJ,
+	}, &out)
+	if err != nil {
+		return "", fmt.Errorf("CardGateway.CreateAccount: %w", err)
+	}
+
+	return out.ID, nil
+}
+
+func (g *gateway) CreateCharge(ctx context.Context, accountId string, booking entity.Booking, commission entity.Commission) (entity.Charge, error) {
+	expiresAt := booking.HoldExpiresAt
+	if expiresAt.IsZero() {
+		expiresAt = time.Now().Add(entity.BookingHoldDuration)
+	}
+
+	var out checkoutSession
+	err := g.do(ctx, http.MethodPost, "/v1/checkout/sessions", checkoutSession{
+		Reference:      fmt.Sprintf("booking-%s", booking.ID),
+		Amount:         commission.Total,
+		ApplicationFee: commission.Fee,
+		Currency:       "BRL",
+		Destination:    accountId,
+		Customer: customer{
+			Name:  booking.GuestName,
+			Email: booking.GuestEmail,
+			Phone: booking.GuestPhone,
+		},
+		ExpiresAt: expiresAt.Unix(),
+	}, &out)
+	if err != nil {
+		return entity.Charge{}, fmt.Errorf("CardGateway.CreateCharge: %w", err)
+	}
+
+	charge := toCharge(out)
+	charge.Fee = commission.Fee
+
+	return charge, nil
+}
+
+func (g *gateway) CancelCharge(ctx context.Context, payment entity.Payment) error {
+	var out checkoutSession
+	if err := g.do(ctx, http.MethodPost, fmt.Sprintf("/v1/checkout/sessions/%s/expire", payment.ChargeID), nil, &out); err != nil {
+		return fmt.Errorf("CardGateway.CancelCharge: %w", err)
+	}
+
+	return nil
+}
+
+func (g *gateway) ListCharges(ctx context.Context, from time.Time, to time.Time) ([]entity.Charge, error) {
+	var charges []entity.Charge
+	var after string
+	for {
+		query := url.Values{}
+		query.Set("created_gte", strconv.FormatInt(from.Unix(), 10))
+		query.Set("created_lt", strconv.FormatInt(to.Unix(), 10))
+		query.Set("limit", "100")
+		if after != "" {
+			query.Set("starting_after", after)
+		}
+
+		var out sessionList
+		if err := g.do(ctx, http.MethodGet, "/v1/checkout/sessions?"+query.Encode(), nil, &out); err != nil {
+			return nil, fmt.Errorf("CardGateway.ListCharges: %w", err)
+		}
+
+		for _, session := range out.Data {
+			charges = append(charges, toCharge(session))
+		}
+
+		if !out.HasMore || len(out.Data) == 0 {
+			return charges, nil
+		}
+		after = out.Data[len(out.Data)-1].ID
+	}
+}
+
+func (g *gateway) GetBalance(ctx context.Context, accountId string) (int64, error) {
+	var out balance
+	if err := g.do(ctx, http.MethodGet, fmt.Sprintf("/v1/accounts/%s/balance", accountId), nil, &out); err != nil {
+		return 0, fmt.Errorf("CardGateway.GetBalance: %w", err)
+	}
+
+	return out.Available, nil
+}
+
+func (g *gateway) Withdraw(ctx context.Context, accountId string, amount int64) (entity.Withdrawal, error) {
+	var out payout
+	if err := g.do(ctx, http.MethodPost, fmt.Sprintf("/v1/accounts/%s/payouts", accountId), payout{Amount: amount}, &out); err != nil {
+		return entity.Withdrawal{}, fmt.Errorf("CardGateway.Withdraw: %w", err)
+	}
+
+	return toWithdrawal(out), nil
+}
+
+func (g *gateway) GetWithdrawal(ctx context.Context, withdrawal entity.Withdrawal) (entity.Withdrawal, error) {
+	var out payout
+	if err := g.do(ctx, http.MethodGet, fmt.Sprintf("/v1/accounts/%s/payouts/%s", withdrawal.AccountID, withdrawal.CorrelationID), nil, &out); err != nil {
+		return entity.Withdrawal{}, fmt.Errorf("CardGateway.GetWithdrawal: %w", err)
+	}
+
+	return toWithdrawal(out), nil
+}
+
+func (g *gateway) RefundCharge(ctx context.Context, payment entity.Payment, amount int64) (entity.Refund, error) {
+	var out refund
+	err := g.do(ctx, http.MethodPost, "/v1/refunds", refund{
+		Reference: payment.CorrelationID,
+		Amount:    amount,
+	}, &out)
+	if err != nil {
+		return entity.Refund{}, fmt.Errorf("CardGateway.RefundCharge: %w", err)
+	}
+
+	return toRefund(out), nil
+}
+
+func (g *gateway) GetRefund(ctx context.Context, payment entity.Payment) (entity.Refund, error) {
+	var out refund
+	if err := g.do(ctx, http.MethodGet, fmt.Sprintf("/v1/refunds/%s", payment.RefundCorrelationID), nil, &out); err != nil {
+		return entity.Refund{}, fmt.Errorf("CardGateway.GetRefund: %w", err)
+	}
+
+	return toRefund(out), nil
+}
+
+func (g *gateway) DecodeChargeEvent(payload []byte) (entity.Charge, error) {
+	var in Event
+	if err := json.Unmarshal(payload, &in); err != nil {
+		return entity.Charge{}, fmt.Errorf("CardGateway.DecodeChargeEvent: %w", entity.ErrInvalidWebhookPayload)
+	}
+
+	return toCharge(in.Data), nil
+}
+
+func (g *gateway) DecodeRefundEvent(payload []byte) (entity.Refund, error) {
+	var in RefundEvent
+	if err := json.Unmarshal(payload, &in); err != nil {
+		return entity.Refund{}, fmt.Errorf("CardGateway.DecodeRefundEvent: %w", entity.ErrInvalidWebhookPayload)
+	}
+
+	return toRefund(in.Data), nil
+}
+
+func (g *gateway) do(ctx context.Context, method string, path string, in any, out any) error {
+	var body bytes.Buffer
+	if in != nil {
+		if err := json.NewEncoder(&body).Encode(in); err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, g.baseURL+path, &body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+g.secretKey)
+
+	res, err := g.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("request failed with status: %s", res.Status)
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
+
+func toCharge(session checkoutSession) entity.Charge {
+	// Card payments capture exactly the session amount.
+	var received int64
+	if session.Status == "complete" {
+		received = session.Amount
+	}
+
+	return entity.Charge{
+		Provider:       entity.ProviderCard,
+		ChargeID:       session.ID,
+		CorrelationID:  session.Reference,
+		Status:         sessionStatus(session.Status),
+		Value:          session.Amount,
+		Fee:            session.ApplicationFee,
+		GatewayFee:     session.ProcessingFee,
+		PaymentLinkURL: session.URL,
+		ExpiresAt:      unixTime(session.ExpiresAt),
+		PaidAt:         unixTime(session.PaidAt),
+		ValueReceived:  received,
+		ValueRefunded:  session.AmountRefunded,
+	}
+}
+
+func sessionStatus(status string) string {
+	switch status {
+	case "open":
+		return entity.ChargeActive
+	case "complete":
+		return entity.ChargeCompleted
+	case "expired":
+		return entity.ChargeExpired
+	default:
+		return status
+	}
+}
+
+func toWithdrawal(out payout) entity.Withdrawal {
+	status := entity.WithdrawalPending
+	switch out.Status {
+	case "paid":
+		status = entity.WithdrawalCompleted
+	case "failed", "canceled":
+		status = entity.WithdrawalFailed
+	}
+
+	return entity.Withdrawal{
+		Provider:      entity.ProviderCard,
+		CorrelationID: out.ID,
+		Value:         out.Amount,
+		Status:        status,
+		FailureReason: out.FailureReason,
+		CompletedAt:   unixTime(out.ArrivedAt),
+	}
+}
+
+// toRefund keys the refund by the acquirer refund id, which is what refund
+// events and lookups carry.
+func toRefund(out refund) entity.Refund {
+	status := entity.RefundPending
+	switch out.Status {
+	case "succeeded":
+		status = entity.RefundCompleted
+	case "failed", "canceled":
+		status = entity.RefundFailed
+	}
+
+	return entity.Refund{
+		ID:            out.ID,
+		CorrelationID: out.ID,
+		EndToEndID:    out.ID,
+		Value:         out.Amount,
+		Status:        status,
+		FailureReason: out.FailureReason,
+		RefundedAt:    unixTime(out.CreatedAt),
+	}
+}
+
+func unixTime(seconds int64) time.Time {
+	if seconds == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(seconds, 0)
+}
@@ -0,0 +1,149 @@
+package card
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dinizgab/booking-mvp/internal/entity"
+	"github.com/dinizgab/booking-mvp/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// SignatureHeader carries "t=<unix>,v1=<hex hmac>" where the HMAC-SHA256 is
+// computed over "<unix>.<raw body>" with the webhook secret.
+const SignatureHeader = "x-card-signature"
+
+// signatureTolerance bounds the age of a signed event, rejecting replays of
+// old deliveries.
+const signatureTolerance = 5 * time.Minute
+
+const (
+	eventCheckoutCompleted = "checkout.session.completed"
+	eventCheckoutExpired   = "checkout.session.expired"
+	eventRefundSucceeded   = "refund.succeeded"
+	eventRefundFailed      = "refund.failed"
+)
+
+var (
+	ErrMissingSignature = errors.New("missing webhook signature")
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrExpiredSignature = errors.New("webhook signature timestamp is outside the tolerance")
+)
+
+// Event is a card provider webhook delivery.
+type Event struct {
+	ID   string          `json:"id"`
+	Type string          `json:"type"`
+	Data checkoutSession `json:"data"`
+}
+
+// RefundEvent is a card provider webhook delivery about a refund.
+type RefundEvent struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Data refund `json:"data"`
+}
+
+// VerifySignature checks the signature header against the raw payload. An
+// empty secret rejects every event.
+func VerifySignature(secret string, payload []byte, header string, now time.Time) error {
+	if secret == "" {
+		return ErrInvalidSignature
+	}
+
+	if header == "" {
+		return ErrMissingSignature
+	}
+
+	var timestamp, signature string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signature = value
+		}
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || signature == "" {
+		return ErrInvalidSignature
+	}
+
+	if age := now.Sub(time.Unix(unix, 0)); age > signatureTolerance || age < -signatureTolerance {
+		return ErrExpiredSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+
+	decoded, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(decoded, mac.Sum(nil)) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// ChargeWebhook verifies and records card checkout and refund events. Event
+// types that do not change a payment are acknowledged and ignored.
+func ChargeWebhook(secret string, uc usecase.WebhookUsecase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		payload, err := c.GetRawData()
+		if err != nil {
+			log.Println("Error reading webhook body:", err)
+			c.JSON(400, gin.H{"status": "error", "message": "Invalid request data"})
+			return
+		}
+
+		if err := VerifySignature(secret, payload, c.GetHeader(SignatureHeader), time.Now()); err != nil {
+			log.Println("Rejected card webhook:", err)
+			c.JSON(401, gin.H{"status": "error", "message": "Invalid signature"})
+			return
+		}
+
+		var event Event
+		if err := json.Unmarshal(payload, &event); err != nil {
+			log.Println("Error decoding card webhook:", err)
+			c.JSON(400, gin.H{"status": "error", "message": "Invalid request data"})
+			return
+		}
+
+		var eventType string
+		switch event.Type {
+		case eventCheckoutCompleted:
+			eventType = entity.WebhookChargeCompleted
+		case eventCheckoutExpired:
+			eventType = entity.WebhookChargeExpired
+		case eventRefundSucceeded, eventRefundFailed:
+			if err := uc.ProcessRefundEvent(c.Request.Context(), entity.ProviderCard, payload); err != nil {
+				log.Printf("Error processing card %s webhook: %v", event.Type, err)
+				c.JSON(500, gin.H{"status": "error", "message": "Failed to process event"})
+				return
+			}
+
+			c.JSON(200, gin.H{"status": "success", "message": "Event processed"})
+			return
+		default:
+			c.JSON(200, gin.H{"status": "success", "message": "Event ignored"})
+			return
+		}
+
+		if err := uc.ProcessChargeEvent(c.Request.Context(), entity.ProviderCard, eventType, payload); err != nil {
+			log.Printf("Error processing card %s webhook: %v", eventType, err)
+			c.JSON(500, gin.H{"status": "error", "message": "Failed to process event"})
+			return
+		}
+
+		c.JSON(200, gin.H{"status": "success", "message": "Event processed"})
+	}
+}
@@ -0,0 +1,310 @@
+// Package fake provides an in-memory payment gateway used to run the API and
+// its tests without reaching a real provider.
+package fake
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dinizgab/booking-mvp/internal/entity"
+	"github.com/dinizgab/booking-mvp/internal/ports"
+	"github.com/google/uuid"
+)
+
+// Gateway records every operation and lets callers inject failures.
+type Gateway struct {
+	mu       sync.Mutex
+	provider entity.PaymentProvider
+	balances map[string]int64
+	charges  map[string]entity.Charge
+	refunds  []entity.Refund
+	payouts  []entity.Withdrawal
+	failNext error
+}
+
+var _ ports.PaymentGateway = (*Gateway)(nil)
+
+func NewGateway(provider entity.PaymentProvider) *Gateway {
+	return &Gateway{
+		provider: provider,
+		balances: make(map[string]int64),
+		charges:  make(map[string]entity.Charge),
+	}
+}
+
+// FailNext makes the next gateway call return err.
+func (g *Gateway) FailNext(err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.failNext = err
+}
+
+// Charges returns the charges created so far keyed by correlation id.
+func (g *Gateway) Charges() map[string]entity.Charge {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	charges := make(map[string]entity.Charge, len(g.charges))
+	for k, v := range g.charges {
+		charges[k] = v
+	}
+
+	return charges
+}
+
+// Refunds returns the refunds issued so far.
+func (g *Gateway) Refunds() []entity.Refund {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return append([]entity.Refund(nil), g.refunds...)
+}
+
+// Pay marks the charge paid, credits the account balance and returns the
+// webhook payload the provider would send.
+func (g *Gateway) Pay(correlationId string, accountId string) ([]byte, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	charge, ok := g.charges[correlationId]
+	if !ok {
+		return nil, fmt.Errorf("fake gateway: charge %s not found", correlationId)
+	}
+
+	charge.Status = entity.ChargeCompleted
+	charge.PaidAt = time.Now()
+	charge.ValueReceived = charge.Value
+	g.charges[correlationId] = charge
+	g.balances[accountId] += charge.Value - charge.Fee
+
+	return json.Marshal(charge)
+}
+
+func (g *Gateway) Provider() entity.PaymentProvider {
+	return g.provider
+}
+
+func (g *Gateway) CreateAccount(ctx context.Context, company entity.Company) (string, error) {
+	if err := g.takeFailure(); err != nil {
+		return "", err
+	}
+
+	if g.provider == entity.ProviderOpenPix && company.PixKey != "" {
+		return company.PixKey, nil
+	}
+
+	return "acct_" + uuid.NewString(), nil
+}
+
+func (g *Gateway) CreateCharge(ctx context.Context, accountId string, booking entity.Booking, commission entity.Commission) (entity.Charge, error) {
+	if err := g.takeFailure(); err != nil {
+		return entity.Charge{}, err
+	}
+
+	expiresAt := booking.HoldExpiresAt
+	if expiresAt.IsZero() {
+		expiresAt = time.Now().Add(entity.BookingHoldDuration)
+	}
+
+	id := uuid.NewString()
+	charge := entity.Charge{
+		Provider:       g.provider,
+		ChargeID:       id,
+		CorrelationID:  fmt.Sprintf("booking-%s", booking.ID),
+		Status:         entity.ChargeActive,
+		Value:          commission.Total,
+		Fee:            commission.Fee,
+		PaymentLinkURL: "https://fake.gateway/pay/" + id,
+		BrCode:         "fake-brcode-" + id,
+		ExpiresAt:      expiresAt,
+	}
+
+	g.mu.Lock()
+	g.charges[charge.CorrelationID] = charge
+	g.mu.Unlock()
+
+	return charge, nil
+}
+
+func (g *Gateway) CancelCharge(ctx context.Context, payment entity.Payment) error {
+	if err := g.takeFailure(); err != nil {
+		return err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	charge, ok := g.charges[payment.CorrelationID]
+	if !ok {
+		return fmt.Errorf("fake gateway: charge %s not found", payment.CorrelationID)
+	}
+	charge.Status = entity.ChargeExpired
+	g.charges[payment.CorrelationID] = charge
+
+	return nil
+}
+
+// ListCharges returns every charge created so far; the fake does not record
+// creation times.
+func (g *Gateway) ListCharges(ctx context.Context, from time.Time, to time.Time) ([]entity.Charge, error) {
+	if err := g.takeFailure(); err != nil {
+		return nil, err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	charges := make([]entity.Charge, 0, len(g.charges))
+	for _, charge := range g.charges {
+		charges = append(charges, charge)
+	}
+
+	return charges, nil
+}
+
+func (g *Gateway) GetBalance(ctx context.Context, accountId string) (int64, error) {
+	if err := g.takeFailure(); err != nil {
+		return 0, err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.balances[accountId], nil
+}
+
+func (g *Gateway) Withdraw(ctx context.Context, accountId string, amount int64) (entity.Withdrawal, error) {
+	if err := g.takeFailure(); err != nil {
+		return entity.Withdrawal{}, err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	balance := g.balances[accountId]
+	if amount <= 0 || amount > balance {
+		amount = balance
+	}
+	g.balances[accountId] = balance - amount
+
+	withdrawal := entity.Withdrawal{
+		Provider:      g.provider,
+		CorrelationID: "withdraw-" + uuid.NewString(),
+		Value:         amount,
+		Status:        entity.WithdrawalCompleted,
+		CompletedAt:   time.Now(),
+	}
+	g.payouts = append(g.payouts, withdrawal)
+
+	return withdrawal, nil
+}
+
+func (g *Gateway) GetWithdrawal(ctx context.Context, withdrawal entity.Withdrawal) (entity.Withdrawal, error) {
+	if err := g.takeFailure(); err != nil {
+		return entity.Withdrawal{}, err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, payout := range g.payouts {
+		if payout.CorrelationID == withdrawal.CorrelationID {
+			return payout, nil
+		}
+	}
+
+	return entity.Withdrawal{}, fmt.Errorf("fake gateway: withdrawal %s not found", withdrawal.CorrelationID)
+}
+
+func (g *Gateway) RefundCharge(ctx context.Context, payment entity.Payment, amount int64) (entity.Refund, error) {
+	if err := g.takeFailure(); err != nil {
+		return entity.Refund{}, err
+	}
+
+	refund := entity.Refund{
+		ID:            uuid.NewString(),
+		CorrelationID: payment.NextRefundCorrelationID(),
+		EndToEndID:    uuid.NewString(),
+		Value:         amount,
+		Status:        entity.RefundCompleted,
+		RefundedAt:    time.Now(),
+	}
+
+	g.mu.Lock()
+	g.refunds = append(g.refunds, refund)
+	if charge, ok := g.charges[payment.CorrelationID]; ok {
+		charge.ValueRefunded += amount
+		g.charges[payment.CorrelationID] = charge
+	}
+	g.mu.Unlock()
+
+	return refund, nil
+}
+
+// RefundOutside refunds the charge without going through the platform, like
+// a manual refund in the provider dashboard.
+func (g *Gateway) RefundOutside(correlationId string, amount int64) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	charge, ok := g.charges[correlationId]
+	if !ok {
+		return fmt.Errorf("fake gateway: charge %s not found", correlationId)
+	}
+
+	charge.ValueRefunded += amount
+	g.charges[correlationId] = charge
+
+	return nil
+}
+
+func (g *Gateway) GetRefund(ctx context.Context, payment entity.Payment) (entity.Refund, error) {
+	if err := g.takeFailure(); err != nil {
+		return entity.Refund{}, err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, refund := range g.refunds {
+		if refund.CorrelationID == payment.RefundCorrelationID {
+			return refund, nil
+		}
+	}
+
+	return entity.Refund{}, fmt.Errorf("fake gateway: refund %s not found", payment.RefundCorrelationID)
+}
+
+// DecodeChargeEvent reads payloads produced by Pay.
+func (g *Gateway) DecodeChargeEvent(payload []byte) (entity.Charge, error) {
+	var charge entity.Charge
+	if err := json.Unmarshal(payload, &charge); err != nil {
+		return entity.Charge{}, fmt.Errorf("FakeGateway.DecodeChargeEvent: %w", entity.ErrInvalidWebhookPayload)
+	}
+
+	return charge, nil
+}
+
+// DecodeRefundEvent reads refunds marshalled as JSON.
+func (g *Gateway) DecodeRefundEvent(payload []byte) (entity.Refund, error) {
+	var refund entity.Refund
+	if err := json.Unmarshal(payload, &refund); err != nil {
+		return entity.Refund{}, fmt.Errorf("FakeGateway.DecodeRefundEvent: %w", entity.ErrInvalidWebhookPayload)
+	}
+
+	return refund, nil
+}
+
+func (g *Gateway) takeFailure() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	err := g.failNext
+	g.failNext = nil
+
+	return err
+}
@@ -29,7 +29,8 @@ type CreateChargeResponse struct {
 type Charge struct {
 	Status         string `json:"status"`
 	Value          int64  `json:"value"`
-    GasPrice      int64  `json:"gasPrice"`
+	GasPrice       int64  `json:"gasPrice"`
+	Fee            int64  `json:"fee"`
 	CorrelationID  string `json:"correlationID"`
 	PaymentLinkID  string `json:"paymentLinkID"`
 	PaymentLinkURL string `json:"paymentLinkUrl"`
@@ -44,7 +45,55 @@ type Charge struct {
 	//Payer Payer `json:"payer"`
 }
 
+// Charge statuses used by OpenPix.
+const (
+	ChargeActive    = "ACTIVE"
+	ChargeCompleted = "COMPLETED"
+	ChargeExpired   = "EXPIRED"
+)
+
+type PageInfo struct {
+	Skip        int  `json:"skip"`
+	Limit       int  `json:"limit"`
+	TotalCount  int  `json:"totalCount"`
+	HasNextPage bool `json:"hasNextPage"`
+}
+
+type ListChargesResponse struct {
+	Charges  []Charge `json:"charges"`
+	PageInfo PageInfo `json:"pageInfo"`
+}
+
+// Pix is the Pix transfer that paid a charge. Value is what the payer sent,
+// which may differ from the charge value.
+type Pix struct {
+	Value      int64  `json:"value"`
+	EndToEndID string `json:"endToEndId"`
+	Time       string `json:"time"`
+}
+
 type ChargeWebhookEvent struct {
-	WebhookEvent  string `json:"event"`
-	Charge Charge `json:"charge"`
+	WebhookEvent string `json:"event"`
+	Charge       Charge `json:"charge"`
+	Pix          Pix    `json:"pix"`
+}
+
+// Transaction is a movement of a charge, such as the Pix that paid it or a
+// refund.
+type Transaction struct {
+	Type       string `json:"type"`
+	Value      int64  `json:"value"`
+	EndToEndID string `json:"endToEndId"`
+	Time       string `json:"time"`
+}
+
+// Transaction types used by OpenPix.
+const (
+	TransactionPayment = "PAYMENT"
+	TransactionRefund  = "REFUND"
+)
+
+type ListTransactionsResponse struct {
+	Transactions []Transaction `json:"transactions"`
+	PageInfo     PageInfo      `json:"pageInfo"`
 }
@@ -6,17 +6,28 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/dinizgab/booking-mvp/internal/config"
 	"github.com/dinizgab/booking-mvp/internal/entity"
 )
 
+// listChargesPageSize is the number of charges asked per page when listing.
+const listChargesPageSize = 100
+
 type OpenPixClient interface {
 	CreateSubaccount(ctx context.Context, subaccount Subaccount) (Subaccount, error)
-	CreateCharge(ctx context.Context, subaccountKey string, booking entity.Booking) (Charge, error)
+	CreateCharge(ctx context.Context, subaccountKey string, booking entity.Booking, commission entity.Commission) (Charge, error)
 	GetCompanyBalance(ctx context.Context, pixKey string) (int64, error)
-	WithdrawSubaccount(ctx context.Context, pixKey string) (Withdraw, error)
-	RefundCharge(ctx context.Context, payment entity.Payment) (Refund, error)
+	WithdrawSubaccount(ctx context.Context, pixKey string, value int64) (Withdraw, error)
+	GetWithdraw(ctx context.Context, correlationId string) (Withdraw, error)
+	RefundCharge(ctx context.Context, payment entity.Payment, value int64) (Refund, error)
+	GetRefund(ctx context.Context, correlationId string) (Refund, error)
+	DeleteCharge(ctx context.Context, correlationId string) error
+	ListCharges(ctx context.Context, start time.Time, end time.Time) ([]Charge, error)
+	ListChargeTransactions(ctx context.Context, correlationId string) ([]Transaction, error)
 }
 
 type openPixClientImpl struct {
@@ -69,24 +80,28 @@ func (c *openPixClientImpl) CreateSubaccount(ctx context.Context, subaccount Sub
 
 // TODO - Check charges with a large amounts of money, its giving an error with split
 // {"error":"O valor total do split de pagamento não pode ser igual ou maior que o valor da cobrança menos a taxa esperada"}
-func (c *openPixClientImpl) CreateCharge(ctx context.Context, subaccountKey string, booking entity.Booking) (Charge, error) {
+// CreateCharge charges the commission total, splitting the company value to
+// the company subaccount.
+func (c *openPixClientImpl) CreateCharge(ctx context.Context, subaccountKey string, booking entity.Booking, commission entity.Commission) (Charge, error) {
 	correlationId := fmt.Sprintf("booking-%s", booking.ID)
-    // gasPrice := 5% + 0.85
-	gasPrice := ((booking.TotalPrice * 5 + 50) / 100) + 85
+	expiresIn := int64(entity.BookingHoldDuration.Seconds())
+	if !booking.HoldExpiresAt.IsZero() {
+		expiresIn = int64(time.Until(booking.HoldExpiresAt).Seconds())
+	}
 	in := CreateChargeRequest{
 		CorrelationID: correlationId,
-		Value:         booking.TotalPrice + gasPrice,
+		Value:         commission.Total,
 		Customer: Customer{
 			Name:  booking.GuestName,
 			Email: booking.GuestEmail,
 			Phone: booking.GuestPhone,
 		},
 		Splits: []Split{{
-			Value:     booking.TotalPrice,
+			Value:     commission.CompanyValue(),
 			PixKey:    subaccountKey,
 			SplitType: "SPLIT_SUB_ACCOUNT",
 		}},
-		ExpiresIn: 1800,
+		ExpiresIn: expiresIn,
 	}
 
 	body, err := json.Marshal(in)
@@ -118,7 +133,7 @@ func (c *openPixClientImpl) CreateCharge(ctx context.Context, subaccountKey stri
 	if err != nil {
 		return Charge{}, fmt.Errorf("OpenPixClient.CreateCharge - failed to decode response: %w", err)
 	}
-    out.Charge.GasPrice = gasPrice
+    out.Charge.GasPrice = commission.Fee
 
 	return out.Charge, nil
 }
@@ -152,10 +167,17 @@ func (c *openPixClientImpl) GetCompanyBalance(ctx context.Context, pixKey string
 	return out.Subaccount.Balance, nil
 }
 
-func (c *openPixClientImpl) WithdrawSubaccount(ctx context.Context, pixKey string) (Withdraw, error) {
+// WithdrawSubaccount withdraws value cents from the subaccount, or the whole
+// balance when value is zero.
+func (c *openPixClientImpl) WithdrawSubaccount(ctx context.Context, pixKey string, value int64) (Withdraw, error) {
+	body, err := json.Marshal(WithdrawRequest{Value: value})
+	if err != nil {
+		return Withdraw{}, fmt.Errorf("OpenPixClient.WithdrawSubaccount - failed to marshal request: %w", err)
+	}
+
 	url := fmt.Sprintf("%s/api/v1/subaccount/%s/withdraw", c.baseURL, pixKey)
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
 	if err != nil {
 		return Withdraw{}, fmt.Errorf("OpenPixClient.WithdrawSubaccount - failed to create request: %w", err)
 	}
@@ -184,12 +206,12 @@ func (c *openPixClientImpl) WithdrawSubaccount(ctx context.Context, pixKey strin
 	return out.Withdraw, nil
 }
 
-func (c *openPixClientImpl) RefundCharge(ctx context.Context, payment entity.Payment) (Refund, error) {
-	refundCorrelationID := fmt.Sprintf("refund-%s", payment.ID)
+func (c *openPixClientImpl) RefundCharge(ctx context.Context, payment entity.Payment, value int64) (Refund, error) {
+	refundCorrelationID := payment.NextRefundCorrelationID()
 	in := Refund{
 		EndToEndID:    payment.ID,
 		CorrelationID: refundCorrelationID,
-        Value:         payment.ValueTotal,
+        Value:         value,
 	}
 
 	body, err := json.Marshal(in)
@@ -227,3 +249,164 @@ func (c *openPixClientImpl) RefundCharge(ctx context.Context, payment entity.Pay
 
 	return out.Refund, nil
 }
+
+func (c *openPixClientImpl) GetRefund(ctx context.Context, correlationId string) (Refund, error) {
+	url := fmt.Sprintf("%s/api/v1/refund/%s", c.baseURL, correlationId)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return Refund{}, fmt.Errorf("OpenPixClient.GetRefund - failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", c.appId)
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return Refund{}, fmt.Errorf("OpenPixClient.GetRefund - failed to send request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return Refund{}, fmt.Errorf("OpenPixClient.GetRefund - failed to get refund with status: %s", res.Status)
+	}
+
+	var out struct {
+		Refund Refund `json:"pixTransactionRefund"`
+	}
+	err = json.NewDecoder(res.Body).Decode(&out)
+	if err != nil {
+		return Refund{}, fmt.Errorf("OpenPixClient.GetRefund - failed to decode response: %w", err)
+	}
+
+	return out.Refund, nil
+}
+
+// DeleteCharge removes the charge, so its Pix code can no longer be paid.
+func (c *openPixClientImpl) DeleteCharge(ctx context.Context, correlationId string) error {
+	url := fmt.Sprintf("%s/api/v1/charge/%s", c.baseURL, correlationId)
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+	if err != nil {
+		return fmt.Errorf("OpenPixClient.DeleteCharge - failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", c.appId)
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("OpenPixClient.DeleteCharge - failed to send request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("OpenPixClient.DeleteCharge - failed to delete charge with status: %s", res.Status)
+	}
+
+	return nil
+}
+
+// ListCharges pages through the charges created between start and end.
+func (c *openPixClientImpl) ListCharges(ctx context.Context, start time.Time, end time.Time) ([]Charge, error) {
+	var charges []Charge
+	for skip := 0; ; {
+		query := url.Values{}
+		query.Set("start", start.UTC().Format(time.RFC3339))
+		query.Set("end", end.UTC().Format(time.RFC3339))
+		query.Set("skip", strconv.Itoa(skip))
+		query.Set("limit", strconv.Itoa(listChargesPageSize))
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/api/v1/charge?%s", c.baseURL, query.Encode()), nil)
+		if err != nil {
+			return nil, fmt.Errorf("OpenPixClient.ListCharges - failed to create request: %w", err)
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", c.appId)
+
+		res, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("OpenPixClient.ListCharges - failed to send request: %w", err)
+		}
+
+		if res.StatusCode != http.StatusOK {
+			res.Body.Close()
+			return nil, fmt.Errorf("OpenPixClient.ListCharges - failed to list charges with status: %s", res.Status)
+		}
+
+		var out ListChargesResponse
+		err = json.NewDecoder(res.Body).Decode(&out)
+		res.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("OpenPixClient.ListCharges - failed to decode response: %w", err)
+		}
+
+		charges = append(charges, out.Charges...)
+		if !out.PageInfo.HasNextPage || len(out.Charges) == 0 {
+			return charges, nil
+		}
+		skip += len(out.Charges)
+	}
+}
+
+// ListChargeTransactions returns the Pix payments and refunds of the charge.
+func (c *openPixClientImpl) ListChargeTransactions(ctx context.Context, correlationId string) ([]Transaction, error) {
+	query := url.Values{}
+	query.Set("charge", correlationId)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/api/v1/transaction?%s", c.baseURL, query.Encode()), nil)
+	if err != nil {
+		return nil, fmt.Errorf("OpenPixClient.ListChargeTransactions - failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", c.appId)
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("OpenPixClient.ListChargeTransactions - failed to send request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("OpenPixClient.ListChargeTransactions - failed to list transactions with status: %s", res.Status)
+	}
+
+	var out ListTransactionsResponse
+	err = json.NewDecoder(res.Body).Decode(&out)
+	if err != nil {
+		return nil, fmt.Errorf("OpenPixClient.ListChargeTransactions - failed to decode response: %w", err)
+	}
+
+	return out.Transactions, nil
+}
+
+func (c *openPixClientImpl) GetWithdraw(ctx context.Context, correlationId string) (Withdraw, error) {
+	url := fmt.Sprintf("%s/api/v1/transaction/%s", c.baseURL, correlationId)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return Withdraw{}, fmt.Errorf("OpenPixClient.GetWithdraw - failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", c.appId)
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return Withdraw{}, fmt.Errorf("OpenPixClient.GetWithdraw - failed to send request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return Withdraw{}, fmt.Errorf("OpenPixClient.GetWithdraw - failed to get withdraw with status: %s", res.Status)
+	}
+
+	var out struct {
+		Withdraw Withdraw `json:"transaction"`
+	}
+	err = json.NewDecoder(res.Body).Decode(&out)
+	if err != nil {
+		return Withdraw{}, fmt.Errorf("OpenPixClient.GetWithdraw - failed to decode response: %w", err)
+	}
+
+	return out.Withdraw, nil
+}
@@ -0,0 +1,240 @@
+package openpix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/dinizgab/booking-mvp/internal/entity"
+	"github.com/dinizgab/booking-mvp/internal/ports"
+)
+
+// gateway adapts the OpenPix client to the provider neutral payment port.
+type gateway struct {
+	client OpenPixClient
+}
+
+func NewGateway(client OpenPixClient) ports.PaymentGateway {
+	return &gateway{
+		client: client,
+	}
+}
+
+func (g *gateway) Provider() entity.PaymentProvider {
+	return entity.ProviderOpenPix
+}
+
+func (g *gateway) CreateAccount(ctx context.Context, company entity.Company) (string, error) {
+	subaccount, err := g.client.CreateSubaccount(ctx, Subaccount{
+		Name:   company.Slug,
+		PixKey: company.PixKey,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if subaccount.PixKey == "" {
+		return company.PixKey, nil
+	}
+
+	return subaccount.PixKey, nil
+}
+
+func (g *gateway) CreateCharge(ctx context.Context, accountId string, booking entity.Booking, commission entity.Commission) (entity.Charge, error) {
+	charge, err := g.client.CreateCharge(ctx, accountId, booking, commission)
+	if err != nil {
+		return entity.Charge{}, err
+	}
+
+	return ToCharge(charge), nil
+}
+
+func (g *gateway) CancelCharge(ctx context.Context, payment entity.Payment) error {
+	return g.client.DeleteCharge(ctx, payment.CorrelationID)
+}
+
+func (g *gateway) ListCharges(ctx context.Context, from time.Time, to time.Time) ([]entity.Charge, error) {
+	charges, err := g.client.ListCharges(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]entity.Charge, 0, len(charges))
+	for _, charge := range charges {
+		converted := ToCharge(charge)
+		// Listed charges carry the value requested; the values received and
+		// refunded come from the Pix transactions of the charge.
+		if charge.Status == ChargeCompleted {
+			transactions, err := g.client.ListChargeTransactions(ctx, charge.CorrelationID)
+			if err != nil {
+				return nil, err
+			}
+			converted.ValueReceived = sumTransactions(transactions, TransactionPayment)
+			converted.ValueRefunded = sumTransactions(transactions, TransactionRefund)
+		}
+		out = append(out, converted)
+	}
+
+	return out, nil
+}
+
+// sumTransactions adds up the values of the transactions of the type.
+func sumTransactions(transactions []Transaction, kind string) int64 {
+	var total int64
+	for _, transaction := range transactions {
+		if transaction.Type == kind {
+			total += transaction.Value
+		}
+	}
+
+	return total
+}
+
+func (g *gateway) GetBalance(ctx context.Context, accountId string) (int64, error) {
+	return g.client.GetCompanyBalance(ctx, accountId)
+}
+
+func (g *gateway) Withdraw(ctx context.Context, accountId string, amount int64) (entity.Withdrawal, error) {
+	withdraw, err := g.client.WithdrawSubaccount(ctx, accountId, amount)
+	if err != nil {
+		return entity.Withdrawal{}, err
+	}
+
+	return toWithdrawal(withdraw), nil
+}
+
+func (g *gateway) GetWithdrawal(ctx context.Context, withdrawal entity.Withdrawal) (entity.Withdrawal, error) {
+	withdraw, err := g.client.GetWithdraw(ctx, withdrawal.CorrelationID)
+	if err != nil {
+		return entity.Withdrawal{}, err
+	}
+
+	return toWithdrawal(withdraw), nil
+}
+
+func toWithdrawal(withdraw Withdraw) entity.Withdrawal {
+	status := entity.WithdrawalPending
+	switch withdraw.Status {
+	case WithdrawConfirmed:
+		status = entity.WithdrawalCompleted
+	case WithdrawFailed:
+		status = entity.WithdrawalFailed
+	}
+
+	return entity.Withdrawal{
+		Provider:      entity.ProviderOpenPix,
+		CorrelationID: withdraw.CorrelationId,
+		Value:         withdraw.Value,
+		Status:        status,
+	}
+}
+
+func (g *gateway) RefundCharge(ctx context.Context, payment entity.Payment, amount int64) (entity.Refund, error) {
+	refund, err := g.client.RefundCharge(ctx, payment, amount)
+	if err != nil {
+		return entity.Refund{}, err
+	}
+
+	return ToRefund(refund), nil
+}
+
+func (g *gateway) GetRefund(ctx context.Context, payment entity.Payment) (entity.Refund, error) {
+	refund, err := g.client.GetRefund(ctx, payment.RefundCorrelationID)
+	if err != nil {
+		return entity.Refund{}, err
+	}
+
+	return ToRefund(refund), nil
+}
+
+func (g *gateway) DecodeChargeEvent(payload []byte) (entity.Charge, error) {
+	var in ChargeWebhookEvent
+	if err := json.Unmarshal(payload, &in); err != nil {
+		return entity.Charge{}, fmt.Errorf("OpenPixGateway.DecodeChargeEvent: %w", entity.ErrInvalidWebhookPayload)
+	}
+
+	charge := ToCharge(in.Charge)
+	charge.ValueReceived = in.Pix.Value
+
+	return charge, nil
+}
+
+func (g *gateway) DecodeRefundEvent(payload []byte) (entity.Refund, error) {
+	var in RefundWebhookEvent
+	if err := json.Unmarshal(payload, &in); err != nil {
+		return entity.Refund{}, fmt.Errorf("OpenPixGateway.DecodeRefundEvent: %w", entity.ErrInvalidWebhookPayload)
+	}
+
+	switch in.WebhookEvent {
+	case EventRefundConfirmed:
+		in.Refund.Status = RefundConfirmed
+	case EventRefundRejected:
+		in.Refund.Status = RefundRejected
+	}
+
+	return ToRefund(in.Refund), nil
+}
+
+// ToRefund converts an OpenPix refund to the domain refund.
+func ToRefund(refund Refund) entity.Refund {
+	status := entity.RefundPending
+	switch refund.Status {
+	case RefundConfirmed:
+		status = entity.RefundCompleted
+	case RefundRejected:
+		status = entity.RefundFailed
+	}
+
+	return entity.Refund{
+		ID:            refund.ID,
+		CorrelationID: refund.CorrelationID,
+		EndToEndID:    refund.EndToEndID,
+		Value:         refund.Value,
+		Status:        status,
+		FailureReason: refund.Comment,
+		RefundedAt:    parseTime(refund.RefundedAt),
+	}
+}
+
+// ToCharge converts an OpenPix charge to the domain charge.
+func ToCharge(charge Charge) entity.Charge {
+	return entity.Charge{
+		Provider:       entity.ProviderOpenPix,
+		ChargeID:       charge.PaymentLinkID,
+		CorrelationID:  charge.CorrelationID,
+		Status:         chargeStatus(charge.Status),
+		Value:          charge.Value,
+		Fee:            charge.GasPrice,
+		GatewayFee:     charge.Fee,
+		PaymentLinkURL: charge.PaymentLinkURL,
+		QrCodeImage:    charge.QrCodeImage,
+		BrCode:         charge.Brcode,
+		ExpiresAt:      parseTime(charge.ExpiresDate),
+		PaidAt:         parseTime(charge.PaidAt),
+	}
+}
+
+func chargeStatus(status string) string {
+	switch status {
+	case ChargeActive:
+		return entity.ChargeActive
+	case ChargeCompleted:
+		return entity.ChargeCompleted
+	case ChargeExpired:
+		return entity.ChargeExpired
+	default:
+		return status
+	}
+}
+
+// parseTime reads the ISO 8601 dates sent by OpenPix, returning the zero time
+// when the field is empty or malformed.
+func parseTime(value string) time.Time {
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return parsed
+}
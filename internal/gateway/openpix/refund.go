@@ -7,4 +7,23 @@ type Refund struct {
 	RefundedAt    string `json:"time"`
 	Value         int64  `json:"value"`
 	Status        string `json:"status"`
+	Comment       string `json:"comment,omitempty"`
+}
+
+// Refund statuses used by OpenPix.
+const (
+	RefundInProcessing = "IN_PROCESSING"
+	RefundConfirmed    = "CONFIRMED"
+	RefundRejected     = "REJECTED"
+)
+
+// Refund webhook events sent by OpenPix.
+const (
+	EventRefundConfirmed = "OPENPIX:PIX_TRANSACTION_REFUND_SENT_CONFIRMED"
+	EventRefundRejected  = "OPENPIX:PIX_TRANSACTION_REFUND_SENT_REJECTED"
+)
+
+type RefundWebhookEvent struct {
+	WebhookEvent string `json:"event"`
+	Refund       Refund `json:"refund"`
 }
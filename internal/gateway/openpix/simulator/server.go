@@ -0,0 +1,682 @@
+// Package simulator is an in-memory OpenPix API used for local development and
+// end to end tests. It serves the endpoints used by the OpenPix client and
+// sends signed webhooks to the API when a charge is paid or expires and when a
+// refund is confirmed or rejected.
+package simulator
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dinizgab/booking-mvp/internal/gateway/openpix"
+	"github.com/dinizgab/booking-mvp/internal/gateway/openpix/webhooks"
+	"github.com/google/uuid"
+)
+
+// Charge statuses used by OpenPix.
+const (
+	StatusActive    = openpix.ChargeActive
+	StatusCompleted = openpix.ChargeCompleted
+	StatusExpired   = openpix.ChargeExpired
+)
+
+// Webhook event names sent by OpenPix.
+const (
+	eventChargeCompleted = "OPENPIX:CHARGE_COMPLETED"
+	eventChargeExpired   = "OPENPIX:CHARGE_EXPIRED"
+)
+
+var (
+	ErrChargeNotFound    = errors.New("charge not found")
+	ErrChargeNotActive   = errors.New("charge is not active")
+	ErrRefundNotFound    = errors.New("refund not found")
+	ErrRefundNotPending  = errors.New("refund is not in processing")
+	ErrChargeNotPaid     = errors.New("charge is not paid")
+	ErrSubaccountMissing = errors.New("subaccount not found")
+	ErrNoBalance         = errors.New("subaccount has no balance to withdraw")
+)
+
+type Config struct {
+	// AppID is the expected Authorization header. Empty accepts any caller.
+	AppID string
+	// WebhookURL is the base URL of the API webhook routes, e.g.
+	// http://localhost:8080/webhooks. Empty disables webhook delivery.
+	WebhookURL string
+	// WebhookSecret signs the webhook payloads with HMAC-SHA256.
+	WebhookSecret string
+	// BaseURL is the public URL of the simulator used in payment links.
+	BaseURL string
+}
+
+// Server holds the simulated OpenPix account state.
+type Server struct {
+	cfg        Config
+	httpClient *http.Client
+	mux        *http.ServeMux
+
+	mu          sync.Mutex
+	subaccounts map[string]openpix.Subaccount
+	charges     map[string]*charge
+	refunds     []openpix.Refund
+	withdrawals []openpix.Withdraw
+	failNext    int
+}
+
+type charge struct {
+	openpix.Charge
+	Splits       []openpix.Split       `json:"splits"`
+	Transactions []openpix.Transaction `json:"transactions"`
+}
+
+// addRefundTransaction records a refund of value in the charge transactions.
+func (c *charge) addRefundTransaction(value int64) {
+	c.Transactions = append(c.Transactions, openpix.Transaction{
+		Type:       openpix.TransactionRefund,
+		Value:      value,
+		EndToEndID: uuid.NewString(),
+		Time:       timestamp(),
+	})
+}
+
+func New(cfg Config) *Server {
+	s := &Server{
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		mux:        http.NewServeMux(),
+	}
+	s.Reset()
+
+	s.mux.HandleFunc("POST /api/v1/subaccount", s.api(s.createSubaccount))
+	s.mux.HandleFunc("GET /api/v1/subaccount/{pixKey}", s.api(s.getSubaccount))
+	s.mux.HandleFunc("POST /api/v1/subaccount/{pixKey}/withdraw", s.api(s.withdraw))
+	s.mux.HandleFunc("GET /api/v1/transaction", s.api(s.listTransactions))
+	s.mux.HandleFunc("GET /api/v1/transaction/{id}", s.api(s.getTransaction))
+	s.mux.HandleFunc("POST /api/v1/charge", s.api(s.createCharge))
+	s.mux.HandleFunc("GET /api/v1/charge", s.api(s.listChargesAPI))
+	s.mux.HandleFunc("GET /api/v1/charge/{id}", s.api(s.getCharge))
+	s.mux.HandleFunc("DELETE /api/v1/charge/{id}", s.api(s.deleteCharge))
+	s.mux.HandleFunc("POST /api/v1/charge/{id}/refund", s.api(s.refundCharge))
+	s.mux.HandleFunc("GET /api/v1/refund/{id}", s.api(s.getRefund))
+
+	s.mux.HandleFunc("GET /simulator/charges", s.listCharges)
+	s.mux.HandleFunc("POST /simulator/charges/{id}/pay", s.control(s.Pay))
+	s.mux.HandleFunc("POST /simulator/charges/{id}/expire", s.control(s.Expire))
+	s.mux.HandleFunc("POST /simulator/refunds/{id}/confirm", s.control(s.ConfirmRefund))
+	s.mux.HandleFunc("POST /simulator/refunds/{id}/reject", s.control(s.RejectRefund))
+	s.mux.HandleFunc("POST /simulator/fail", s.fail)
+	s.mux.HandleFunc("POST /simulator/reset", s.reset)
+
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Reset drops every subaccount, charge, refund and withdrawal.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.subaccounts = make(map[string]openpix.Subaccount)
+	s.charges = make(map[string]*charge)
+	s.refunds = nil
+	s.withdrawals = nil
+	s.failNext = 0
+}
+
+// FailNext makes the next OpenPix API call answer with the given status.
+func (s *Server) FailNext(status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failNext = status
+}
+
+// Charges returns the charges created so far sorted by creation time.
+func (s *Server) Charges() []openpix.Charge {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	charges := make([]openpix.Charge, 0, len(s.charges))
+	for _, c := range s.charges {
+		charges = append(charges, c.Charge)
+	}
+	sort.Slice(charges, func(i, j int) bool {
+		if charges[i].CreatedAt != charges[j].CreatedAt {
+			return charges[i].CreatedAt < charges[j].CreatedAt
+		}
+		return charges[i].CorrelationID < charges[j].CorrelationID
+	})
+
+	return charges
+}
+
+// Refunds returns the refunds requested so far.
+func (s *Server) Refunds() []openpix.Refund {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]openpix.Refund(nil), s.refunds...)
+}
+
+// Pay completes the charge, credits the split values to the subaccounts and
+// sends the CHARGE_COMPLETED webhook.
+func (s *Server) Pay(ctx context.Context, correlationId string) error {
+	s.mu.Lock()
+	c, ok := s.charges[correlationId]
+	if !ok {
+		s.mu.Unlock()
+		return ErrChargeNotFound
+	}
+	if c.Status != StatusActive {
+		s.mu.Unlock()
+		return ErrChargeNotActive
+	}
+
+	now := timestamp()
+	c.Status = StatusCompleted
+	c.PaidAt = now
+	c.UpdatedAt = now
+	for _, split := range c.Splits {
+		subaccount := s.subaccounts[split.PixKey]
+		subaccount.PixKey = split.PixKey
+		subaccount.Balance += split.Value
+		s.subaccounts[split.PixKey] = subaccount
+	}
+	pix := openpix.Pix{Value: c.Value, EndToEndID: uuid.NewString(), Time: now}
+	c.Transactions = append(c.Transactions, openpix.Transaction{
+		Type:       openpix.TransactionPayment,
+		Value:      pix.Value,
+		EndToEndID: pix.EndToEndID,
+		Time:       pix.Time,
+	})
+	paid := c.Charge
+	s.mu.Unlock()
+
+	return s.sendWebhook(ctx, "/pix/confirmed", openpix.ChargeWebhookEvent{WebhookEvent: eventChargeCompleted, Charge: paid, Pix: pix})
+}
+
+// RefundFromDashboard refunds value of the paid charge the way a manual refund
+// in the OpenPix dashboard does: the refund shows in the charge transactions
+// but the platform is never called nor notified.
+func (s *Server) RefundFromDashboard(correlationId string, value int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.charges[correlationId]
+	if !ok {
+		return ErrChargeNotFound
+	}
+	if c.Status != StatusCompleted {
+		return ErrChargeNotPaid
+	}
+
+	c.addRefundTransaction(value)
+
+	return nil
+}
+
+// Expire expires the charge and sends the CHARGE_EXPIRED webhook.
+func (s *Server) Expire(ctx context.Context, correlationId string) error {
+	s.mu.Lock()
+	c, ok := s.charges[correlationId]
+	if !ok {
+		s.mu.Unlock()
+		return ErrChargeNotFound
+	}
+	if c.Status != StatusActive {
+		s.mu.Unlock()
+		return ErrChargeNotActive
+	}
+
+	c.Status = StatusExpired
+	c.UpdatedAt = timestamp()
+	expired := c.Charge
+	s.mu.Unlock()
+
+	return s.sendWebhook(ctx, "/pix/expired", openpix.ChargeWebhookEvent{WebhookEvent: eventChargeExpired, Charge: expired})
+}
+
+// ConfirmRefund completes a refund in processing and sends the refund
+// confirmed webhook.
+func (s *Server) ConfirmRefund(ctx context.Context, correlationId string) error {
+	return s.finishRefund(ctx, correlationId, openpix.RefundConfirmed, openpix.EventRefundConfirmed, "")
+}
+
+// RejectRefund fails a refund in processing and sends the refund rejected
+// webhook.
+func (s *Server) RejectRefund(ctx context.Context, correlationId string) error {
+	return s.finishRefund(ctx, correlationId, openpix.RefundRejected, openpix.EventRefundRejected, "refund rejected by the simulator")
+}
+
+func (s *Server) finishRefund(ctx context.Context, correlationId string, status string, event string, comment string) error {
+	s.mu.Lock()
+	i := s.findRefund(correlationId)
+	if i < 0 {
+		s.mu.Unlock()
+		return ErrRefundNotFound
+	}
+	if s.refunds[i].Status != openpix.RefundInProcessing {
+		s.mu.Unlock()
+		return ErrRefundNotPending
+	}
+
+	s.refunds[i].Status = status
+	s.refunds[i].Comment = comment
+	if status == openpix.RefundConfirmed {
+		s.refunds[i].RefundedAt = timestamp()
+	}
+	refund := s.refunds[i]
+	s.mu.Unlock()
+
+	return s.sendWebhook(ctx, "/pix/refund", openpix.RefundWebhookEvent{WebhookEvent: event, Refund: refund})
+}
+
+// findRefund returns the index of the refund, or -1. The caller holds s.mu.
+func (s *Server) findRefund(correlationId string) int {
+	for i, refund := range s.refunds {
+		if refund.CorrelationID == correlationId {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (s *Server) sendWebhook(ctx context.Context, path string, event any) error {
+	if s.cfg.WebhookURL == "" {
+		return nil
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("simulator.sendWebhook - failed to marshal event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.WebhookURL+path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("simulator.sendWebhook - failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.cfg.WebhookSecret != "" {
+		req.Header.Set(webhooks.SignatureHeader, Sign(s.cfg.WebhookSecret, body))
+	}
+
+	res, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("simulator.sendWebhook - failed to send request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("simulator.sendWebhook - webhook %s answered with status: %s", path, res.Status)
+	}
+
+	return nil
+}
+
+// Sign computes the base64 HMAC-SHA256 signature accepted by the webhook
+// signature middleware.
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (s *Server) createSubaccount(r *http.Request) (int, any) {
+	var in openpix.Subaccount
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil || in.PixKey == "" {
+		return http.StatusBadRequest, errorBody("invalid subaccount")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subaccount, ok := s.subaccounts[in.PixKey]
+	if !ok {
+		subaccount = openpix.Subaccount{Name: in.Name, PixKey: in.PixKey}
+		s.subaccounts[in.PixKey] = subaccount
+	}
+
+	return http.StatusOK, openpix.SubAccountResponse{Subaccount: subaccount}
+}
+
+func (s *Server) getSubaccount(r *http.Request) (int, any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subaccount, ok := s.subaccounts[r.PathValue("pixKey")]
+	if !ok {
+		return http.StatusNotFound, errorBody(ErrSubaccountMissing.Error())
+	}
+
+	return http.StatusOK, openpix.SubAccountResponse{Subaccount: subaccount}
+}
+
+// withdraw moves the requested value, or the whole balance when the body has
+// none, out of the subaccount. Withdrawals are confirmed right away.
+func (s *Server) withdraw(r *http.Request) (int, any) {
+	var in openpix.WithdrawRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			return http.StatusBadRequest, errorBody("invalid withdraw")
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pixKey := r.PathValue("pixKey")
+	subaccount, ok := s.subaccounts[pixKey]
+	if !ok {
+		return http.StatusNotFound, errorBody(ErrSubaccountMissing.Error())
+	}
+	if subaccount.Balance <= 0 {
+		return http.StatusBadRequest, errorBody(ErrNoBalance.Error())
+	}
+
+	value := in.Value
+	if value <= 0 {
+		value = subaccount.Balance
+	}
+	if value > subaccount.Balance {
+		return http.StatusBadRequest, errorBody(ErrNoBalance.Error())
+	}
+
+	withdraw := openpix.Withdraw{
+		ID:               uuid.NewString(),
+		Value:            value,
+		CorrelationId:    uuid.NewString(),
+		DestinationAlias: pixKey,
+		Status:           openpix.WithdrawConfirmed,
+		CreatedAt:        timestamp(),
+	}
+	subaccount.Balance -= value
+	s.subaccounts[pixKey] = subaccount
+	s.withdrawals = append(s.withdrawals, withdraw)
+
+	return http.StatusOK, map[string]any{"transaction": withdraw}
+}
+
+// listTransactions returns the transactions of the charge in the charge query
+// parameter.
+func (s *Server) listTransactions(r *http.Request) (int, any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.charges[r.URL.Query().Get("charge")]
+	if !ok {
+		return http.StatusNotFound, errorBody(ErrChargeNotFound.Error())
+	}
+
+	return http.StatusOK, openpix.ListTransactionsResponse{
+		Transactions: c.Transactions,
+		PageInfo: openpix.PageInfo{
+			Limit:      len(c.Transactions),
+			TotalCount: len(c.Transactions),
+		},
+	}
+}
+
+func (s *Server) getTransaction(r *http.Request) (int, any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, withdraw := range s.withdrawals {
+		if withdraw.CorrelationId == r.PathValue("id") {
+			return http.StatusOK, map[string]any{"transaction": withdraw}
+		}
+	}
+
+	return http.StatusNotFound, errorBody("transaction not found")
+}
+
+func (s *Server) createCharge(r *http.Request) (int, any) {
+	var in openpix.CreateChargeRequest
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil || in.CorrelationID == "" || in.Value <= 0 {
+		return http.StatusBadRequest, errorBody("invalid charge")
+	}
+
+	var splitTotal int64
+	for _, split := range in.Splits {
+		splitTotal += split.Value
+	}
+	if splitTotal >= in.Value {
+		return http.StatusBadRequest, errorBody("split total must be lower than the charge value")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.charges[in.CorrelationID]; ok {
+		return http.StatusOK, openpix.CreateChargeResponse{Charge: existing.Charge}
+	}
+
+	expiresIn := time.Duration(in.ExpiresIn) * time.Second
+	if expiresIn <= 0 {
+		expiresIn = 24 * time.Hour
+	}
+
+	id := uuid.NewString()
+	now := timestamp()
+	c := &charge{
+		Charge: openpix.Charge{
+			Status:         StatusActive,
+			Value:          in.Value,
+			CorrelationID:  in.CorrelationID,
+			PaymentLinkID:  id,
+			PaymentLinkURL: fmt.Sprintf("%s/pay/%s", s.cfg.BaseURL, id),
+			ExpiresDate:    time.Now().Add(expiresIn).UTC().Format(time.RFC3339),
+			Brcode:         "00020101021226880014br.gov.bcb.pix2566simulator/" + id,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		},
+		Splits: in.Splits,
+	}
+	s.charges[in.CorrelationID] = c
+
+	return http.StatusOK, openpix.CreateChargeResponse{Charge: c.Charge}
+}
+
+// listChargesAPI pages through the charges created between the start and end
+// query parameters, both optional.
+func (s *Server) listChargesAPI(r *http.Request) (int, any) {
+	query := r.URL.Query()
+	start, _ := time.Parse(time.RFC3339, query.Get("start"))
+	end, _ := time.Parse(time.RFC3339, query.Get("end"))
+	skip, _ := strconv.Atoi(query.Get("skip"))
+	limit, err := strconv.Atoi(query.Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = 100
+	}
+
+	var charges []openpix.Charge
+	for _, c := range s.Charges() {
+		createdAt, _ := time.Parse(time.RFC3339, c.CreatedAt)
+		if !start.IsZero() && createdAt.Before(start) {
+			continue
+		}
+		if !end.IsZero() && !createdAt.Before(end) {
+			continue
+		}
+		charges = append(charges, c)
+	}
+
+	total := len(charges)
+	skip = min(max(skip, 0), total)
+	page := charges[skip:min(skip+limit, total)]
+
+	return http.StatusOK, openpix.ListChargesResponse{
+		Charges: page,
+		PageInfo: openpix.PageInfo{
+			Skip:        skip,
+			Limit:       limit,
+			TotalCount:  total,
+			HasNextPage: skip+len(page) < total,
+		},
+	}
+}
+
+func (s *Server) getCharge(r *http.Request) (int, any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.charges[r.PathValue("id")]
+	if !ok {
+		return http.StatusNotFound, errorBody(ErrChargeNotFound.Error())
+	}
+
+	return http.StatusOK, openpix.CreateChargeResponse{Charge: c.Charge}
+}
+
+// deleteCharge stops an active charge from being paid. The simulator keeps it
+// as expired so it still shows up in the listings.
+func (s *Server) deleteCharge(r *http.Request) (int, any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.charges[r.PathValue("id")]
+	if !ok {
+		return http.StatusNotFound, errorBody(ErrChargeNotFound.Error())
+	}
+	if c.Status != StatusActive {
+		return http.StatusBadRequest, errorBody(ErrChargeNotActive.Error())
+	}
+
+	c.Status = StatusExpired
+	c.UpdatedAt = timestamp()
+
+	return http.StatusOK, map[string]any{"status": "OK", "id": r.PathValue("id")}
+}
+
+func (s *Server) refundCharge(r *http.Request) (int, any) {
+	var in openpix.Refund
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		return http.StatusBadRequest, errorBody("invalid refund")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.charges[r.PathValue("id")]
+	if !ok {
+		return http.StatusNotFound, errorBody(ErrChargeNotFound.Error())
+	}
+	if c.Status != StatusCompleted {
+		return http.StatusBadRequest, errorBody(ErrChargeNotPaid.Error())
+	}
+	if in.Value <= 0 || in.Value > c.Value {
+		in.Value = c.Value
+	}
+
+	refund := openpix.Refund{
+		ID:            uuid.NewString(),
+		EndToEndID:    in.EndToEndID,
+		CorrelationID: in.CorrelationID,
+		Value:         in.Value,
+		Status:        openpix.RefundInProcessing,
+	}
+	s.refunds = append(s.refunds, refund)
+	c.addRefundTransaction(in.Value)
+
+	return http.StatusOK, map[string]any{"refund": refund}
+}
+
+func (s *Server) getRefund(r *http.Request) (int, any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.findRefund(r.PathValue("id"))
+	if i < 0 {
+		return http.StatusNotFound, errorBody(ErrRefundNotFound.Error())
+	}
+
+	return http.StatusOK, map[string]any{"pixTransactionRefund": s.refunds[i]}
+}
+
+// api authenticates OpenPix API calls and applies injected failures.
+func (s *Server) api(handle func(*http.Request) (int, any)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.cfg.AppID != "" && r.Header.Get("Authorization") != s.cfg.AppID {
+			writeJSON(w, http.StatusUnauthorized, errorBody("invalid app id"))
+			return
+		}
+
+		s.mu.Lock()
+		status := s.failNext
+		s.failNext = 0
+		s.mu.Unlock()
+		if status != 0 {
+			writeJSON(w, status, errorBody("simulated failure"))
+			return
+		}
+
+		code, body := handle(r)
+		writeJSON(w, code, body)
+	}
+}
+
+func (s *Server) control(action func(context.Context, string) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := action(r.Context(), r.PathValue("id"))
+		switch {
+		case errors.Is(err, ErrChargeNotFound), errors.Is(err, ErrRefundNotFound):
+			writeJSON(w, http.StatusNotFound, errorBody(err.Error()))
+		case errors.Is(err, ErrChargeNotActive), errors.Is(err, ErrRefundNotPending):
+			writeJSON(w, http.StatusConflict, errorBody(err.Error()))
+		case err != nil:
+			writeJSON(w, http.StatusBadGateway, errorBody(err.Error()))
+		default:
+			writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+		}
+	}
+}
+
+func (s *Server) listCharges(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{"charges": s.Charges()})
+}
+
+func (s *Server) fail(w http.ResponseWriter, r *http.Request) {
+	var in struct {
+		Status int `json:"status"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil || in.Status < 400 || in.Status > 599 {
+		writeJSON(w, http.StatusBadRequest, errorBody("status must be between 400 and 599"))
+		return
+	}
+
+	s.FailNext(in.Status)
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (s *Server) reset(w http.ResponseWriter, r *http.Request) {
+	s.Reset()
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func errorBody(message string) map[string]string {
+	return map[string]string{"error": message}
+}
+
+func timestamp() string {
+	return time.Now().UTC().Format(time.RFC3339)
+}
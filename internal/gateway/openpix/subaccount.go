@@ -12,9 +12,20 @@ type Withdraw struct {
     Value int64 `json:"value"`
     CorrelationId string `json:"correlationID"`
     DestinationAlias string `json:"destinationAlias"`
+    Status string `json:"status,omitempty"`
     CreatedAt string `json:"createdAt"`
 }
 
+// Withdraw statuses used by OpenPix.
+const (
+	WithdrawConfirmed = "CONFIRMED"
+	WithdrawFailed    = "FAILED"
+)
+
+type WithdrawRequest struct {
+	Value int64 `json:"value,omitempty"`
+}
+
 type SubAccountResponse struct {
 	Subaccount Subaccount `json:"SubAccount"`
 }
@@ -0,0 +1,146 @@
+package webhooks
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	"github.com/dinizgab/booking-mvp/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+// SignatureHeader carries the signature OpenPix computes over the raw body.
+const SignatureHeader = "x-webhook-signature"
+
+var (
+	ErrVerifierNotConfigured = errors.New("webhook signature verification is not configured")
+	ErrMissingSignature      = errors.New("missing webhook signature")
+	ErrInvalidSignature      = errors.New("invalid webhook signature")
+)
+
+type SignatureVerifier interface {
+	Verify(payload []byte, signature string) error
+}
+
+type signatureVerifierImpl struct {
+	publicKey *rsa.PublicKey
+	secret    []byte
+}
+
+// NewSignatureVerifier builds a verifier from the OpenPix config. The public
+// key is used to check RSA-SHA256 signatures and the secret to check
+// HMAC-SHA256 ones; a signature matching either is accepted. When neither is
+// configured every event is rejected.
+func NewSignatureVerifier(cfg *config.OpenPixConfig) (SignatureVerifier, error) {
+	verifier := &signatureVerifierImpl{
+		secret: []byte(cfg.WebhookSecret),
+	}
+
+	if cfg.WebhookPublicKey != "" {
+		publicKey, err := parsePublicKey(cfg.WebhookPublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("webhooks.NewSignatureVerifier: %w", err)
+		}
+		verifier.publicKey = publicKey
+	}
+
+	return verifier, nil
+}
+
+func (v *signatureVerifierImpl) Verify(payload []byte, signature string) error {
+	if v.publicKey == nil && len(v.secret) == 0 {
+		return ErrVerifierNotConfigured
+	}
+
+	signature = strings.TrimSpace(signature)
+	if signature == "" {
+		return ErrMissingSignature
+	}
+
+	if v.publicKey != nil {
+		if decoded, err := base64.StdEncoding.DecodeString(signature); err == nil {
+			digest := sha256.Sum256(payload)
+			if rsa.VerifyPKCS1v15(v.publicKey, crypto.SHA256, digest[:], decoded) == nil {
+				return nil
+			}
+		}
+	}
+
+	if len(v.secret) > 0 {
+		mac := hmac.New(sha256.New, v.secret)
+		mac.Write(payload)
+		expected := mac.Sum(nil)
+
+		for _, decode := range []func(string) ([]byte, error){base64.StdEncoding.DecodeString, hex.DecodeString} {
+			if decoded, err := decode(signature); err == nil && hmac.Equal(decoded, expected) {
+				return nil
+			}
+		}
+	}
+
+	return ErrInvalidSignature
+}
+
+// parsePublicKey accepts a PEM encoded key, optionally base64 encoded as
+// OpenPix distributes it.
+func parsePublicKey(key string) (*rsa.PublicKey, error) {
+	raw := []byte(strings.TrimSpace(key))
+	if !bytes.HasPrefix(raw, []byte("-----BEGIN")) {
+		decoded, err := base64.StdEncoding.DecodeString(string(raw))
+		if err != nil {
+			return nil, fmt.Errorf("decode public key: %w", err)
+		}
+		raw = decoded
+	}
+
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, errors.New("public key is not PEM encoded")
+	}
+
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse public key: %w", err)
+	}
+
+	publicKey, ok := parsed.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("public key is not an RSA key")
+	}
+
+	return publicKey, nil
+}
+
+// SignatureMiddleware rejects webhook calls without a valid signature.
+// Deliveries of events that were already processed are acknowledged by the
+// webhook event ledger, so OpenPix retries never see an error.
+func SignatureMiddleware(verifier SignatureVerifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payload, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			log.Println("Error reading webhook body:", err)
+			c.AbortWithStatusJSON(400, gin.H{"status": "error", "message": "Invalid request data"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(payload))
+
+		if err := verifier.Verify(payload, c.GetHeader(SignatureHeader)); err != nil {
+			log.Println("Rejected webhook:", err)
+			c.AbortWithStatusJSON(401, gin.H{"status": "error", "message": "Invalid signature"})
+			return
+		}
+
+		c.Next()
+	}
+}
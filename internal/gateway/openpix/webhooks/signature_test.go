@@ -0,0 +1,209 @@
+package webhooks
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dinizgab/booking-mvp/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+const testSecret = "webhook-secret"
+
+var testPayload = []byte(`{"event":"OPENPIX:CHARGE_COMPLETED","charge":{"correlationID":"booking-1","value":5000}}`)
+
+func signHMAC(secret string, payload []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+func signRSA(t *testing.T, key *rsa.PrivateKey, payload []byte) string {
+	t.Helper()
+
+	digest := sha256.Sum256(payload)
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("sign payload: %v", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(signature)
+}
+
+func newRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	return key
+}
+
+// encodePublicKey returns the key as OpenPix distributes it: a base64 encoded
+// PEM block.
+func encodePublicKey(t *testing.T, key *rsa.PrivateKey) string {
+	t.Helper()
+
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("marshal public key: %v", err)
+	}
+	block := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+
+	return base64.StdEncoding.EncodeToString(block)
+}
+
+func newVerifier(t *testing.T, cfg config.OpenPixConfig) SignatureVerifier {
+	t.Helper()
+
+	verifier, err := NewSignatureVerifier(&cfg)
+	if err != nil {
+		t.Fatalf("NewSignatureVerifier: %v", err)
+	}
+
+	return verifier
+}
+
+func TestVerifyHMAC(t *testing.T) {
+	verifier := newVerifier(t, config.OpenPixConfig{WebhookSecret: testSecret})
+
+	tests := []struct {
+		name      string
+		signature string
+		want      error
+	}{
+		{"base64 signature", base64.StdEncoding.EncodeToString(signHMAC(testSecret, testPayload)), nil},
+		{"hex signature", hex.EncodeToString(signHMAC(testSecret, testPayload)), nil},
+		{"other secret", base64.StdEncoding.EncodeToString(signHMAC("other-secret", testPayload)), ErrInvalidSignature},
+		{"other payload", base64.StdEncoding.EncodeToString(signHMAC(testSecret, []byte(`{}`))), ErrInvalidSignature},
+		{"garbage", "not a signature", ErrInvalidSignature},
+		{"missing signature", "", ErrMissingSignature},
+		{"blank signature", "   ", ErrMissingSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := verifier.Verify(testPayload, tt.signature); !errors.Is(err, tt.want) {
+				t.Errorf("Verify() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyRSA(t *testing.T) {
+	key := newRSAKey(t)
+	otherKey := newRSAKey(t)
+	verifier := newVerifier(t, config.OpenPixConfig{WebhookPublicKey: encodePublicKey(t, key)})
+
+	tests := []struct {
+		name      string
+		signature string
+		want      error
+	}{
+		{"valid signature", signRSA(t, key, testPayload), nil},
+		{"other key", signRSA(t, otherKey, testPayload), ErrInvalidSignature},
+		{"other payload", signRSA(t, key, []byte(`{}`)), ErrInvalidSignature},
+		{"HMAC signature without secret", base64.StdEncoding.EncodeToString(signHMAC(testSecret, testPayload)), ErrInvalidSignature},
+		{"missing signature", "", ErrMissingSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := verifier.Verify(testPayload, tt.signature); !errors.Is(err, tt.want) {
+				t.Errorf("Verify() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyAcceptsEitherScheme(t *testing.T) {
+	key := newRSAKey(t)
+	verifier := newVerifier(t, config.OpenPixConfig{
+		WebhookSecret:    testSecret,
+		WebhookPublicKey: encodePublicKey(t, key),
+	})
+
+	for name, signature := range map[string]string{
+		"RSA":  signRSA(t, key, testPayload),
+		"HMAC": base64.StdEncoding.EncodeToString(signHMAC(testSecret, testPayload)),
+	} {
+		if err := verifier.Verify(testPayload, signature); err != nil {
+			t.Errorf("Verify(%s) = %v, want nil", name, err)
+		}
+	}
+}
+
+func TestVerifyNotConfigured(t *testing.T) {
+	verifier := newVerifier(t, config.OpenPixConfig{})
+
+	signature := base64.StdEncoding.EncodeToString(signHMAC(testSecret, testPayload))
+	if err := verifier.Verify(testPayload, signature); !errors.Is(err, ErrVerifierNotConfigured) {
+		t.Errorf("Verify() = %v, want %v", err, ErrVerifierNotConfigured)
+	}
+}
+
+func TestNewSignatureVerifierRejectsInvalidKey(t *testing.T) {
+	_, err := NewSignatureVerifier(&config.OpenPixConfig{WebhookPublicKey: "not a key"})
+	if err == nil {
+		t.Fatal("NewSignatureVerifier() = nil, want an error")
+	}
+}
+
+func TestSignatureMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var received []string
+	router := gin.New()
+	router.POST("/webhooks", SignatureMiddleware(newVerifier(t, config.OpenPixConfig{WebhookSecret: testSecret})), func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		received = append(received, string(body))
+		c.JSON(200, gin.H{"status": "ok"})
+	})
+
+	send := func(signature string) int {
+		req := httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(string(testPayload)))
+		if signature != "" {
+			req.Header.Set(SignatureHeader, signature)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := send(""); code != 401 {
+		t.Errorf("missing signature: status = %d, want 401", code)
+	}
+	if code := send(base64.StdEncoding.EncodeToString(signHMAC("other-secret", testPayload))); code != 401 {
+		t.Errorf("invalid signature: status = %d, want 401", code)
+	}
+	if len(received) != 0 {
+		t.Fatalf("handler called %d times for rejected calls", len(received))
+	}
+
+	// OpenPix delivers the same event again when it misses the response; the
+	// event ledger acknowledges it, so the middleware lets it through.
+	valid := base64.StdEncoding.EncodeToString(signHMAC(testSecret, testPayload))
+	for i := 0; i < 2; i++ {
+		if code := send(valid); code != 200 {
+			t.Errorf("delivery %d: status = %d, want 200", i+1, code)
+		}
+	}
+	if len(received) != 2 || received[0] != string(testPayload) {
+		t.Errorf("handler received %q, want the payload twice", received)
+	}
+}
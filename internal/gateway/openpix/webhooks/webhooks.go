@@ -1,51 +1,69 @@
 package webhooks
 
 import (
+	"errors"
 	"log"
 
-	"github.com/dinizgab/booking-mvp/internal/gateway/openpix"
+	"github.com/dinizgab/booking-mvp/internal/entity"
 	"github.com/dinizgab/booking-mvp/internal/usecase"
 	"github.com/gin-gonic/gin"
 )
 
 // TODO - Check its not updating booking status to confirmed if the payment is confirmed
-func ConfirmedPaymentWebhook(puc usecase.PaymentUsecase, buc usecase.BookingUsecase) func(*gin.Context) {
+func ConfirmedPaymentWebhook(uc usecase.WebhookUsecase) func(*gin.Context) {
+    return chargeWebhook(uc, entity.WebhookChargeCompleted, "Failed to confirm payment", "Payment confirmed")
+}
+
+func ExpiredPaymentWebhook(uc usecase.WebhookUsecase) func(*gin.Context) {
+    return chargeWebhook(uc, entity.WebhookChargeExpired, "Failed to expire payment", "Payment expired")
+}
+
+func chargeWebhook(uc usecase.WebhookUsecase, eventType string, failure string, success string) func(*gin.Context) {
     return func(c *gin.Context) {
-        var in openpix.ChargeWebhookEvent
-        err := c.ShouldBindJSON(&in)
+        payload, err := c.GetRawData()
         if err != nil {
-            log.Println("Error binding JSON:", err)
+            log.Println("Error reading webhook body:", err)
             c.JSON(400, gin.H{"status": "error", "message": "Invalid request data"})
             return
         }
 
-        err = puc.ConfirmPayment(c.Request.Context(), in.Charge)
+        err = uc.ProcessChargeEvent(c.Request.Context(), entity.ProviderOpenPix, eventType, payload)
         if err != nil {
-            log.Println("Error confirming payment:", err)
-            c.JSON(500, gin.H{"status": "error", "message": "Failed to confirm payment"})
+            log.Printf("Error processing %s webhook: %v", eventType, err)
+            if errors.Is(err, entity.ErrInvalidWebhookPayload) {
+                c.JSON(400, gin.H{"status": "error", "message": "Invalid request data"})
+                return
+            }
+
+            c.JSON(500, gin.H{"status": "error", "message": failure})
             return
         }
-        c.JSON(200, gin.H{"status": "success", "message": "Payment confirmed"})
+
+        c.JSON(200, gin.H{"status": "success", "message": success})
     }
 }
 
-func ExpiredPaymentWebhook(uc usecase.PaymentUsecase) func(*gin.Context) {
+func RefundWebhook(uc usecase.WebhookUsecase) func(*gin.Context) {
     return func(c *gin.Context) {
-        var in openpix.ChargeWebhookEvent
-        err := c.ShouldBindJSON(&in)
+        payload, err := c.GetRawData()
         if err != nil {
-            log.Println("Error binding JSON:", err)
+            log.Println("Error reading webhook body:", err)
             c.JSON(400, gin.H{"status": "error", "message": "Invalid request data"})
             return
         }
 
-        err = uc.ExpirePayment(c.Request.Context(), in.Charge)
+        err = uc.ProcessRefundEvent(c.Request.Context(), entity.ProviderOpenPix, payload)
         if err != nil {
-            log.Println("Error expiring payment:", err)
-            c.JSON(500, gin.H{"status": "error", "message": "Failed to expire payment"})
+            log.Printf("Error processing refund webhook: %v", err)
+            if errors.Is(err, entity.ErrInvalidWebhookPayload) {
+                c.JSON(400, gin.H{"status": "error", "message": "Invalid request data"})
+                return
+            }
+
+            c.JSON(500, gin.H{"status": "error", "message": "Failed to update refund"})
             return
         }
-    
-        c.JSON(200, gin.H{"status": "success", "message": "Payment expired"})
+
+        c.JSON(200, gin.H{"status": "success", "message": "Refund updated"})
     }
 }
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"log"
 	"time"
 
@@ -98,6 +99,22 @@ func FindBookingByID(uc usecase.BookingUsecase) func(*gin.Context) {
 	}
 }
 
+func ListBookingNotifications(uc usecase.OutboxUsecase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		companyID := c.GetString("company_id")
+		bookingID := c.Param("id")
+
+		notifications, err := uc.ListBookingNotifications(c.Request.Context(), companyID, bookingID)
+		if err != nil {
+			log.Println(err)
+			c.JSON(500, gin.H{"error": "Failed to list booking notifications"})
+			return
+		}
+
+		c.JSON(200, notifications)
+	}
+}
+
 func FindBookingByIDShowcase(uc usecase.BookingUsecase) func(*gin.Context) {
 	return func(c *gin.Context) {
 		bookingID := c.Query("id")
@@ -131,9 +148,241 @@ func CancelBooking(uc usecase.BookingUsecase) func(*gin.Context) {
 				return
 			}
 
+			if errors.Is(err, entity.ErrCancellationClosed) {
+				c.JSON(422, gin.H{"error": err.Error()})
+				return
+			}
+
+			if errors.Is(err, entity.ErrBookingAlreadyCancelled) ||
+				errors.Is(err, entity.ErrSeriesPaymentPending) ||
+				errors.Is(err, entity.ErrPaymentMismatchUnresolved) {
+				c.JSON(409, gin.H{"error": err.Error()})
+				return
+			}
+
 			c.JSON(500, gin.H{"error": "Failed to cancel booking"})
 			return
 		}
 		c.JSON(200, gin.H{"message": "Booking cancelled successfully"})
 	}
 }
+
+func CreateManualBooking(uc usecase.BookingUsecase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		var booking entity.Booking
+		if err := c.ShouldBindJSON(&booking); err != nil {
+			log.Println(err)
+			c.JSON(400, gin.H{"error": "Invalid request"})
+			return
+		}
+
+		companyID := c.GetString("company_id")
+		booking.CourtId = c.Param("id")
+		booking.CreatedBy = c.GetString("user_id")
+
+		id, err := uc.CreateManual(c.Request.Context(), companyID, booking)
+		if err != nil {
+			log.Println(err)
+			writeBookingError(c, err, "Failed to create booking")
+			return
+		}
+
+		c.JSON(201, gin.H{"message": "Booking created successfully", "id": id})
+	}
+}
+
+func CreateBookingSeries(uc usecase.BookingUsecase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		var series entity.BookingSeries
+		if err := c.ShouldBindJSON(&series); err != nil {
+			log.Println(err)
+			c.JSON(400, gin.H{"error": "Invalid request"})
+			return
+		}
+
+		companyID := c.GetString("company_id")
+		series.CourtId = c.Param("id")
+
+		result, err := uc.CreateSeries(c.Request.Context(), companyID, series)
+		if err != nil {
+			log.Println(err)
+			if errors.Is(err, entity.ErrInvalidSeries) {
+				c.JSON(400, gin.H{"error": "Invalid booking series"})
+				return
+			}
+
+			if errors.Is(err, entity.ErrSlotUnavailable) {
+				c.JSON(409, gin.H{"error": "Every occurrence conflicts with an existing booking", "conflicts": result.Conflicts})
+				return
+			}
+
+			writeBookingError(c, err, "Failed to create booking series")
+			return
+		}
+
+		c.JSON(201, result)
+	}
+}
+
+func FindBookingSeriesByID(uc usecase.BookingUsecase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		companyID := c.GetString("company_id")
+		seriesID := c.Param("id")
+
+		series, err := uc.FindSeriesByID(c.Request.Context(), companyID, seriesID)
+		if err != nil {
+			log.Println(err)
+			if errors.Is(err, entity.ErrSeriesNotFound) {
+				c.JSON(404, gin.H{"error": "Booking series not found"})
+				return
+			}
+
+			c.JSON(500, gin.H{"error": "Failed to find booking series"})
+			return
+		}
+
+		c.JSON(200, series)
+	}
+}
+
+func CancelBookingSeries(uc usecase.BookingUsecase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		companyID := c.GetString("company_id")
+		seriesID := c.Param("id")
+
+		err := uc.CancelSeries(c.Request.Context(), companyID, seriesID)
+		if err != nil {
+			log.Println(err)
+			writeCancellationError(c, err)
+			return
+		}
+
+		c.JSON(200, gin.H{"message": "Booking series cancelled successfully"})
+	}
+}
+
+func CancelBookingSeriesOccurrence(uc usecase.BookingUsecase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		companyID := c.GetString("company_id")
+		seriesID := c.Param("id")
+		bookingID := c.Param("booking_id")
+
+		err := uc.CancelSeriesOccurrence(c.Request.Context(), companyID, seriesID, bookingID)
+		if err != nil {
+			log.Println(err)
+			writeCancellationError(c, err)
+			return
+		}
+
+		c.JSON(200, gin.H{"message": "Booking cancelled successfully"})
+	}
+}
+
+func CancelBookingByCompany(uc usecase.BookingUsecase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		var cancellation entity.CompanyCancellation
+		if err := c.ShouldBindJSON(&cancellation); err != nil {
+			log.Println(err)
+			c.JSON(400, gin.H{"error": "Invalid request"})
+			return
+		}
+
+		if !canRefund(c, cancellation) {
+			c.JSON(403, gin.H{"error": "forbidden"})
+			return
+		}
+
+		result, err := uc.CancelByCompany(c.Request.Context(), c.GetString("company_id"), c.Param("id"), cancellation)
+		if err != nil {
+			log.Println(err)
+			writeCancellationError(c, err)
+			return
+		}
+
+		c.JSON(200, gin.H{"result": result})
+	}
+}
+
+func CancelCourtBookings(uc usecase.BookingUsecase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		var cancellation entity.CourtCancellation
+		if err := c.ShouldBindJSON(&cancellation); err != nil {
+			log.Println(err)
+			c.JSON(400, gin.H{"error": "Invalid request"})
+			return
+		}
+
+		if !canRefund(c, cancellation.CompanyCancellation) {
+			c.JSON(403, gin.H{"error": "forbidden"})
+			return
+		}
+
+		results, err := uc.CancelCourtBookings(c.Request.Context(), c.GetString("company_id"), c.Param("id"), cancellation)
+		if err != nil {
+			log.Println(err)
+			writeCancellationError(c, err)
+			return
+		}
+
+		c.JSON(200, gin.H{"results": results})
+	}
+}
+
+// canRefund reports whether the user may issue the refund of the
+// cancellation. Refunds within the cancellation policy come with cancelling
+// the booking; a full refund goes beyond it and needs
+// entity.PermissionManagePayments.
+func canRefund(c *gin.Context, cancellation entity.CompanyCancellation) bool {
+	return !cancellation.FullRefund || entity.UserRole(c.GetString("user_role")).Can(entity.PermissionManagePayments)
+}
+
+// writeCancellationError maps company cancellation errors to their HTTP
+// responses.
+func writeCancellationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, entity.ErrCancellationReasonRequired),
+		errors.Is(err, entity.ErrInvalidCancellationRange):
+		c.JSON(400, gin.H{"error": err.Error()})
+	case errors.Is(err, entity.ErrBookingNotFound):
+		c.JSON(404, gin.H{"error": "Booking not found"})
+	case errors.Is(err, entity.ErrSeriesNotFound):
+		c.JSON(404, gin.H{"error": "Booking series not found"})
+	case errors.Is(err, entity.ErrBookingAlreadyCancelled),
+		errors.Is(err, entity.ErrSeriesAlreadyCancelled),
+		errors.Is(err, entity.ErrSeriesPaymentPending),
+		errors.Is(err, entity.ErrSeriesRefundPending),
+		errors.Is(err, entity.ErrPaymentMismatchUnresolved):
+		c.JSON(409, gin.H{"error": err.Error()})
+	case errors.Is(err, entity.ErrCancellationClosed):
+		c.JSON(422, gin.H{"error": err.Error()})
+	default:
+		c.JSON(500, gin.H{"error": "Failed to cancel booking"})
+	}
+}
+
+// writeBookingError maps booking creation errors to their HTTP responses.
+func writeBookingError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, entity.ErrInvalidDuration),
+		errors.Is(err, entity.ErrBookingInPast),
+		errors.Is(err, entity.ErrInvalidPaymentMethod):
+		c.JSON(400, gin.H{"error": err.Error()})
+	case errors.Is(err, entity.ErrCourtNotFound):
+		c.JSON(404, gin.H{"error": "Court not found"})
+	case errors.Is(err, entity.ErrCompanySuspended):
+		c.JSON(403, gin.H{"error": "Company is not taking bookings"})
+	case errors.Is(err, entity.ErrSlotUnavailable),
+		errors.Is(err, entity.ErrCourtBlackedOut):
+		c.JSON(409, gin.H{"error": err.Error()})
+	case errors.Is(err, entity.ErrCourtInactive),
+		errors.Is(err, entity.ErrCourtClosed),
+		errors.Is(err, entity.ErrOutsideOpeningHours),
+		errors.Is(err, entity.ErrDurationNotAllowed),
+		errors.Is(err, entity.ErrMisalignedStart),
+		errors.Is(err, entity.ErrBookingTooSoon),
+		errors.Is(err, entity.ErrBookingTooFarAhead):
+		c.JSON(422, gin.H{"error": err.Error()})
+	default:
+		c.JSON(500, gin.H{"error": message})
+	}
+}
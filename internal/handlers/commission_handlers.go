@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+
+	"github.com/dinizgab/booking-mvp/internal/entity"
+	"github.com/dinizgab/booking-mvp/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func ListCommissionPlans(uc usecase.CommissionUsecase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		plans, err := uc.ListPlans(c.Request.Context())
+		if err != nil {
+			log.Println(err)
+			c.JSON(500, gin.H{"error": "Failed to list commission plans"})
+			return
+		}
+
+		c.JSON(200, gin.H{"plans": plans})
+	}
+}
+
+func CreateCommissionPlan(uc usecase.CommissionUsecase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		var plan entity.CommissionPlan
+		if err := c.ShouldBindJSON(&plan); err != nil {
+			log.Println(err)
+			c.JSON(400, gin.H{"error": "Invalid request"})
+			return
+		}
+
+		plan, err := uc.CreatePlan(c.Request.Context(), plan)
+		if err != nil {
+			log.Println(err)
+			if errors.Is(err, entity.ErrInvalidCommissionPlan) {
+				c.JSON(400, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(500, gin.H{"error": "Failed to create commission plan"})
+			return
+		}
+
+		c.JSON(201, gin.H{"plan": plan})
+	}
+}
+
+func UpdateCommissionPlan(uc usecase.CommissionUsecase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		var plan entity.CommissionPlan
+		if err := c.ShouldBindJSON(&plan); err != nil {
+			log.Println(err)
+			c.JSON(400, gin.H{"error": "Invalid request"})
+			return
+		}
+		plan.ID = c.Param("id")
+
+		plan, err := uc.UpdatePlan(c.Request.Context(), plan)
+		if err != nil {
+			log.Println(err)
+			switch {
+			case errors.Is(err, entity.ErrInvalidCommissionPlan):
+				c.JSON(400, gin.H{"error": err.Error()})
+			case errors.Is(err, entity.ErrCommissionPlanNotFound):
+				c.JSON(404, gin.H{"error": "Commission plan not found"})
+			default:
+				c.JSON(500, gin.H{"error": "Failed to update commission plan"})
+			}
+			return
+		}
+
+		c.JSON(200, gin.H{"plan": plan})
+	}
+}
+
+func GetCompanyCommissionPlan(uc usecase.CommissionUsecase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		plan, err := uc.GetCompanyPlan(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			log.Println(err)
+			c.JSON(500, gin.H{"error": "Failed to get commission plan"})
+			return
+		}
+
+		c.JSON(200, gin.H{"plan": plan})
+	}
+}
+
+// AssignCompanyCommissionPlan attaches a plan to the company. An empty plan_id
+// moves it back to the default plan.
+func AssignCompanyCommissionPlan(uc usecase.CommissionUsecase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		var request entity.CompanyCommissionPlan
+		if err := c.ShouldBindJSON(&request); err != nil {
+			log.Println(err)
+			c.JSON(400, gin.H{"error": "Invalid request"})
+			return
+		}
+
+		plan, err := uc.AssignCompanyPlan(c.Request.Context(), c.Param("id"), request.PlanID)
+		if err != nil {
+			log.Println(err)
+			switch {
+			case errors.Is(err, entity.ErrCommissionPlanNotFound):
+				c.JSON(404, gin.H{"error": "Commission plan not found"})
+			case errors.Is(err, entity.ErrCompanyNotFound):
+				c.JSON(404, gin.H{"error": "Company not found"})
+			default:
+				c.JSON(500, gin.H{"error": "Failed to assign commission plan"})
+			}
+			return
+		}
+
+		c.JSON(200, gin.H{"plan": plan})
+	}
+}
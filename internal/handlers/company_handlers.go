@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"log"
 
 	"github.com/dinizgab/booking-mvp/internal/entity"
@@ -20,6 +21,15 @@ func CreateNewCompany(uc usecase.CompanyUsecase) func(*gin.Context) {
 		token, err := uc.Create(c.Request.Context(), company)
 		if err != nil {
 			log.Println(err)
+			if errors.Is(err, entity.ErrUnsupportedProvider) {
+				c.JSON(400, gin.H{"error": "Unsupported payment provider"})
+				return
+			}
+			if errors.Is(err, entity.ErrEmailAlreadyInUse) {
+				c.JSON(409, gin.H{"error": "Email already in use"})
+				return
+			}
+
 			c.JSON(500, gin.H{"error": "Failed to create company"})
 			return
 		}
@@ -32,36 +42,6 @@ func CreateNewCompany(uc usecase.CompanyUsecase) func(*gin.Context) {
 	}
 }
 
-func LoginCompany(uc usecase.CompanyUsecase) func(*gin.Context) {
-	return func(c *gin.Context) {
-		var input struct {
-			Email    string `json:"email"`
-			Password string `json:"password"`
-		}
-
-		if err := c.ShouldBindJSON(&input); err != nil {
-			log.Println(err)
-			c.JSON(400, gin.H{"error": "Invalid request"})
-			return
-		}
-
-		token, err := uc.Login(c.Request.Context(), input.Email, input.Password)
-		if err != nil {
-			log.Println(err)
-			if err == entity.ErrInvalidCredentials {
-				c.JSON(401, gin.H{"error": "Invalid credentials"})
-			}
-			return
-		
-        }
-
-		c.JSON(200, gin.H{
-			"message": "Login successful",
-			"token":   token,
-		})
-	}
-}
-
 func FindCompanyByID(uc usecase.CompanyUsecase) func(*gin.Context) {
     return func(c *gin.Context) {
         id := c.Param("id")
@@ -89,6 +69,11 @@ func UpdateCompanyInformations(uc usecase.CompanyUsecase) func (*gin.Context) {
         err := uc.Update(c.Request.Context(), id, company)
         if err != nil {
             log.Println(err)
+            if errors.Is(err, entity.ErrUnsupportedProvider) {
+                c.JSON(400, gin.H{"error": "Unsupported payment provider"})
+                return
+            }
+
             c.JSON(500, gin.H{"error": "Failed to update company"})
             return
         }
@@ -129,23 +114,94 @@ func GetCompanyBalance(uc usecase.PaymentUsecase) func(*gin.Context) {
 	}
 }
 
+// CreateWithdrawRequest withdraws the amount in the body, or the whole balance
+// when the request has no body.
 func CreateWithdrawRequest(uc usecase.PaymentUsecase) func(*gin.Context) {
     return func(c *gin.Context) {
         id := c.Param("id")
 
-        err := uc.CreateWithdrawRequest(c.Request.Context(), id)
+        var request entity.WithdrawalRequest
+        if c.Request.ContentLength != 0 {
+            if err := c.ShouldBindJSON(&request); err != nil {
+                log.Println(err)
+                c.JSON(400, gin.H{"error": "Invalid request"})
+                return
+            }
+        }
+
+        withdrawal, err := uc.CreateWithdrawRequest(c.Request.Context(), id, request)
         if err != nil {
             log.Println(err)
-            c.JSON(500, gin.H{"error": "Failed to create withdraw request"})
+            switch {
+            case errors.Is(err, entity.ErrWithdrawalBelowMinimum):
+                c.JSON(400, gin.H{"error": err.Error(), "minimum": entity.MinWithdrawalAmount})
+            case errors.Is(err, entity.ErrInsufficientBalance):
+                c.JSON(400, gin.H{"error": "Withdrawal amount is above the available balance"})
+            default:
+                c.JSON(500, gin.H{"error": "Failed to create withdraw request"})
+            }
             return
         }
 
         c.JSON(200, gin.H{
             "message": "Withdraw request created successfully",
+            "withdrawal": withdrawal,
         })
     }
 }
 
+func ListWithdrawals(uc usecase.PaymentUsecase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		withdrawals, err := uc.ListWithdrawals(c.Request.Context(), c.GetString("company_id"))
+		if err != nil {
+			log.Println(err)
+			c.JSON(500, gin.H{"error": "Failed to list withdrawals"})
+			return
+		}
+
+		c.JSON(200, gin.H{"withdrawals": withdrawals})
+	}
+}
+
+func GetPayoutSchedule(uc usecase.PaymentUsecase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		schedule, err := uc.GetPayoutSchedule(c.Request.Context(), c.GetString("company_id"))
+		if err != nil {
+			log.Println(err)
+			c.JSON(500, gin.H{"error": "Failed to get payout schedule"})
+			return
+		}
+
+		c.JSON(200, gin.H{"schedule": schedule})
+	}
+}
+
+func UpdatePayoutSchedule(uc usecase.PaymentUsecase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		var schedule entity.PayoutSchedule
+		if err := c.ShouldBindJSON(&schedule); err != nil {
+			log.Println(err)
+			c.JSON(400, gin.H{"error": "Invalid request"})
+			return
+		}
+		schedule.CompanyId = c.GetString("company_id")
+
+		schedule, err := uc.UpdatePayoutSchedule(c.Request.Context(), schedule)
+		if err != nil {
+			log.Println(err)
+			if errors.Is(err, entity.ErrInvalidPayoutSchedule) {
+				c.JSON(400, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(500, gin.H{"error": "Failed to update payout schedule"})
+			return
+		}
+
+		c.JSON(200, gin.H{"schedule": schedule})
+	}
+}
+
 func FindCompanyByIDShowcase(uc usecase.CompanyUsecase) func(*gin.Context) {
     return func(c *gin.Context) {
         id := c.Param("id")
@@ -159,3 +215,57 @@ func FindCompanyByIDShowcase(uc usecase.CompanyUsecase) func(*gin.Context) {
         c.JSON(200, company)
     }
 }
+
+func GetCancellationPolicy(uc usecase.CompanyUsecase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		policy, err := uc.GetCancellationPolicy(c.Request.Context(), c.GetString("company_id"))
+		if err != nil {
+			log.Println(err)
+			c.JSON(500, gin.H{"error": "Failed to get cancellation policy"})
+			return
+		}
+
+		c.JSON(200, gin.H{"policy": policy})
+	}
+}
+
+// GetCancellationPolicyShowcase shows guests the refund rules of a company
+// before they book.
+func GetCancellationPolicyShowcase(uc usecase.CompanyUsecase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		policy, err := uc.GetCancellationPolicy(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			log.Println(err)
+			c.JSON(500, gin.H{"error": "Failed to get cancellation policy"})
+			return
+		}
+
+		c.JSON(200, gin.H{"policy": policy})
+	}
+}
+
+func UpdateCancellationPolicy(uc usecase.CompanyUsecase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		var policy entity.CancellationPolicy
+		if err := c.ShouldBindJSON(&policy); err != nil {
+			log.Println(err)
+			c.JSON(400, gin.H{"error": "Invalid request"})
+			return
+		}
+		policy.CompanyId = c.GetString("company_id")
+
+		policy, err := uc.UpdateCancellationPolicy(c.Request.Context(), policy)
+		if err != nil {
+			log.Println(err)
+			if errors.Is(err, entity.ErrInvalidCancellationPolicy) {
+				c.JSON(400, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(500, gin.H{"error": "Failed to update cancellation policy"})
+			return
+		}
+
+		c.JSON(200, gin.H{"policy": policy})
+	}
+}
@@ -2,9 +2,12 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"mime/multipart"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/dinizgab/booking-mvp/internal/entity"
 	"github.com/dinizgab/booking-mvp/internal/usecase"
@@ -49,6 +52,11 @@ func CreateCourt(uc usecase.CourtUseCase) func(*gin.Context) {
 		err = uc.Create(c, court, photos)
 		if err != nil {
 			log.Println(err)
+			if errors.Is(err, entity.ErrInvalidBookingPolicy) {
+				c.JSON(400, gin.H{"error": err.Error()})
+				return
+			}
+
 			c.JSON(500, gin.H{"error": "Failed to create court"})
 			return
 		}
@@ -138,6 +146,11 @@ func UpdateCourt(uc usecase.CourtUseCase) func(*gin.Context) {
 		err = uc.Update(c.Request.Context(), id, court)
 		if err != nil {
 			log.Println(err)
+			if errors.Is(err, entity.ErrInvalidBookingPolicy) {
+				c.JSON(400, gin.H{"error": err.Error()})
+				return
+			}
+
 			c.JSON(500, gin.H{"error": "Failed to update court"})
 			return
 		}
@@ -204,6 +217,41 @@ func ListAvailableBookingSlots(uc usecase.CourtUseCase) func(*gin.Context) {
 	}
 }
 
+func ListFreeBookingSlots(uc usecase.CourtUseCase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		courtID := c.Param("id")
+		date := c.Query("date")
+
+		// Slot length in minutes, defaults to the court minimum duration.
+		// "granularity" is kept for older clients.
+		duration := 0
+		if d := c.DefaultQuery("duration", c.Query("granularity")); d != "" {
+			parsed, err := strconv.Atoi(d)
+			if err != nil {
+				log.Println(err)
+				c.JSON(400, gin.H{"error": "Invalid duration"})
+				return
+			}
+			duration = parsed
+		}
+
+		slots, err := uc.ListFreeBookingSlots(c.Request.Context(), courtID, date, time.Duration(duration)*time.Minute)
+		if err != nil {
+			log.Println(err)
+			if errors.Is(err, entity.ErrInvalidDate) || errors.Is(err, entity.ErrInvalidGranularity) ||
+				errors.Is(err, entity.ErrDurationNotAllowed) {
+				c.JSON(400, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(500, gin.H{"error": "Failed to list free slots"})
+			return
+		}
+
+		c.JSON(200, slots)
+	}
+}
+
 func CreateNewBooking(uc usecase.BookingUsecase) func(*gin.Context) {
 	return func(c *gin.Context) {
 		courtId := c.Param("id")
@@ -220,7 +268,7 @@ func CreateNewBooking(uc usecase.BookingUsecase) func(*gin.Context) {
 		id, err := uc.Create(c.Request.Context(), booking)
 		if err != nil {
 			log.Println(err)
-			c.JSON(500, gin.H{"error": "Failed to create booking"})
+			writeBookingError(c, err, "Failed to create booking")
 			return
 		}
 
@@ -253,3 +301,228 @@ func ChangeCourtStatus(uc usecase.CourtUseCase) func(*gin.Context) {
 		c.JSON(200, gin.H{"message": "Court updated successfully"})
 	}
 }
+
+func ListCourtBlackouts(uc usecase.CourtUseCase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		companyID := c.GetString("company_id")
+		courtID := c.Param("id")
+
+		blackouts, err := uc.ListCourtBlackouts(c.Request.Context(), companyID, courtID)
+		if err != nil {
+			log.Println(err)
+			if errors.Is(err, entity.ErrCourtNotFound) {
+				c.JSON(404, gin.H{"error": "Court not found"})
+				return
+			}
+
+			c.JSON(500, gin.H{"error": "Failed to list blackouts"})
+			return
+		}
+
+		c.JSON(200, blackouts)
+	}
+}
+
+func CreateCourtBlackout(uc usecase.CourtUseCase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		var blackout entity.CourtBlackout
+		if err := c.ShouldBindJSON(&blackout); err != nil {
+			log.Println(err)
+			c.JSON(400, gin.H{"error": "Invalid request"})
+			return
+		}
+
+		courtID := c.Param("id")
+		blackout.CourtId = &courtID
+
+		id, err := uc.CreateBlackout(c.Request.Context(), c.GetString("company_id"), blackout)
+		if err != nil {
+			log.Println(err)
+			writeBlackoutError(c, err, "Failed to create blackout")
+			return
+		}
+
+		c.JSON(201, gin.H{"message": "Blackout created successfully", "id": id})
+	}
+}
+
+func UpdateCourtBlackout(uc usecase.CourtUseCase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		var blackout entity.CourtBlackout
+		if err := c.ShouldBindJSON(&blackout); err != nil {
+			log.Println(err)
+			c.JSON(400, gin.H{"error": "Invalid request"})
+			return
+		}
+
+		courtID := c.Param("id")
+		blackout.ID = c.Param("blackout_id")
+		blackout.CourtId = &courtID
+
+		err := uc.UpdateBlackout(c.Request.Context(), c.GetString("company_id"), blackout)
+		if err != nil {
+			log.Println(err)
+			writeBlackoutError(c, err, "Failed to update blackout")
+			return
+		}
+
+		c.JSON(200, gin.H{"message": "Blackout updated successfully"})
+	}
+}
+
+func DeleteCourtBlackout(uc usecase.CourtUseCase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		courtID := c.Param("id")
+
+		err := uc.DeleteBlackout(c.Request.Context(), c.GetString("company_id"), &courtID, c.Param("blackout_id"))
+		if err != nil {
+			log.Println(err)
+			writeBlackoutError(c, err, "Failed to delete blackout")
+			return
+		}
+
+		c.JSON(200, gin.H{"message": "Blackout deleted successfully"})
+	}
+}
+
+func ListCompanyBlackouts(uc usecase.CourtUseCase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		blackouts, err := uc.ListCompanyBlackouts(c.Request.Context(), c.GetString("company_id"))
+		if err != nil {
+			log.Println(err)
+			c.JSON(500, gin.H{"error": "Failed to list blackouts"})
+			return
+		}
+
+		c.JSON(200, blackouts)
+	}
+}
+
+func CreateCompanyBlackout(uc usecase.CourtUseCase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		var blackout entity.CourtBlackout
+		if err := c.ShouldBindJSON(&blackout); err != nil {
+			log.Println(err)
+			c.JSON(400, gin.H{"error": "Invalid request"})
+			return
+		}
+
+		blackout.CourtId = nil
+
+		id, err := uc.CreateBlackout(c.Request.Context(), c.GetString("company_id"), blackout)
+		if err != nil {
+			log.Println(err)
+			writeBlackoutError(c, err, "Failed to create blackout")
+			return
+		}
+
+		c.JSON(201, gin.H{"message": "Blackout created successfully", "id": id})
+	}
+}
+
+func DeleteCompanyBlackout(uc usecase.CourtUseCase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		err := uc.DeleteBlackout(c.Request.Context(), c.GetString("company_id"), nil, c.Param("blackout_id"))
+		if err != nil {
+			log.Println(err)
+			writeBlackoutError(c, err, "Failed to delete blackout")
+			return
+		}
+
+		c.JSON(200, gin.H{"message": "Blackout deleted successfully"})
+	}
+}
+
+func ListCourtPricingRules(uc usecase.CourtUseCase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		companyID := c.GetString("company_id")
+		courtID := c.Param("id")
+
+		rules, err := uc.ListPricingRules(c.Request.Context(), companyID, courtID)
+		if err != nil {
+			log.Println(err)
+			if errors.Is(err, entity.ErrCourtNotFound) {
+				c.JSON(404, gin.H{"error": "Court not found"})
+				return
+			}
+
+			c.JSON(500, gin.H{"error": "Failed to list pricing rules"})
+			return
+		}
+
+		c.JSON(200, rules)
+	}
+}
+
+func ReplaceCourtPricingRules(uc usecase.CourtUseCase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		companyID := c.GetString("company_id")
+		courtID := c.Param("id")
+
+		var rules []entity.PricingRule
+		if err := c.ShouldBindJSON(&rules); err != nil {
+			log.Println(err)
+			c.JSON(400, gin.H{"error": "Invalid request"})
+			return
+		}
+
+		saved, err := uc.ReplacePricingRules(c.Request.Context(), companyID, courtID, rules)
+		if err != nil {
+			log.Println(err)
+			switch {
+			case errors.Is(err, entity.ErrInvalidPricingRule):
+				c.JSON(400, gin.H{"error": err.Error()})
+			case errors.Is(err, entity.ErrCourtNotFound):
+				c.JSON(404, gin.H{"error": "Court not found"})
+			default:
+				c.JSON(500, gin.H{"error": "Failed to save pricing rules"})
+			}
+			return
+		}
+
+		c.JSON(200, saved)
+	}
+}
+
+func QuoteBooking(uc usecase.CourtUseCase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		courtID := c.Param("id")
+
+		var request struct {
+			StartTime time.Time `json:"start_time"`
+			EndTime   time.Time `json:"end_time"`
+		}
+		if err := c.ShouldBindJSON(&request); err != nil {
+			log.Println(err)
+			c.JSON(400, gin.H{"error": "Invalid request"})
+			return
+		}
+
+		quote, err := uc.Quote(c.Request.Context(), courtID, request.StartTime, request.EndTime)
+		if err != nil {
+			log.Println(err)
+			if errors.Is(err, entity.ErrInvalidQuote) {
+				c.JSON(400, gin.H{"error": err.Error()})
+				return
+			}
+
+			writeBookingError(c, err, "Failed to quote booking")
+			return
+		}
+
+		c.JSON(200, quote)
+	}
+}
+
+func writeBlackoutError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, entity.ErrInvalidBlackout):
+		c.JSON(400, gin.H{"error": "Invalid blackout period"})
+	case errors.Is(err, entity.ErrCourtNotFound):
+		c.JSON(404, gin.H{"error": "Court not found"})
+	case errors.Is(err, entity.ErrBlackoutNotFound):
+		c.JSON(404, gin.H{"error": "Blackout not found"})
+	default:
+		c.JSON(500, gin.H{"error": message})
+	}
+}
@@ -0,0 +1,316 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dinizgab/booking-mvp/internal/entity"
+	"github.com/dinizgab/booking-mvp/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// auditDetailsKey holds what handlers add to the audit log entry of the
+// request, such as the reason given for it.
+const auditDetailsKey = "audit_details"
+
+// operatorCompanyRoutes is the prefix of the operator routes acting on the
+// company in the id parameter.
+const operatorCompanyRoutes = "/operator/companies/:id"
+
+// AuditOperatorActions records every request made with an operator token or
+// an impersonation token once it has been handled.
+func AuditOperatorActions(uc usecase.OperatorUsecase) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		operatorID := c.GetString("operator_id")
+		if operatorID == "" {
+			return
+		}
+
+		entry := entity.AuditLogEntry{
+			OperatorID: operatorID,
+			CompanyID:  c.GetString("company_id"),
+			Action:     c.Request.Method + " " + c.FullPath(),
+			TargetID:   c.Param("id"),
+			StatusCode: c.Writer.Status(),
+		}
+		if strings.HasPrefix(c.FullPath(), operatorCompanyRoutes) {
+			entry.CompanyID = c.Param("id")
+		}
+		if details, ok := c.Get(auditDetailsKey); ok {
+			data, err := json.Marshal(details)
+			if err != nil {
+				log.Println(err)
+			}
+			entry.Details = data
+		}
+
+		// The entry is written even when the client went away.
+		if err := uc.RecordAction(context.WithoutCancel(c.Request.Context()), entry); err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+func LoginOperator(uc usecase.OperatorUsecase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		var input struct {
+			Email    string `json:"email"`
+			Password string `json:"password"`
+		}
+
+		if err := c.ShouldBindJSON(&input); err != nil {
+			log.Println(err)
+			c.JSON(400, gin.H{"error": "Invalid request"})
+			return
+		}
+
+		token, err := uc.Login(c.Request.Context(), input.Email, input.Password)
+		if err != nil {
+			log.Println(err)
+			if errors.Is(err, entity.ErrInvalidCredentials) {
+				c.JSON(401, gin.H{"error": "Invalid credentials"})
+				return
+			}
+
+			c.JSON(500, gin.H{"error": "Failed to log in"})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"message": "Login successful",
+			"token":   token,
+		})
+	}
+}
+
+// ListPlatformCompanies searches the companies by name, email, slug or CNPJ
+// with the q query parameter and filters them by status (active or
+// suspended).
+func ListPlatformCompanies(uc usecase.OperatorUsecase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		limit, offset, ok := pagination(c, 20)
+		if !ok {
+			return
+		}
+
+		page, err := uc.ListCompanies(c.Request.Context(), entity.CompanyFilter{
+			Query:  c.Query("q"),
+			Status: c.Query("status"),
+			Limit:  limit,
+			Offset: offset,
+		})
+		if err != nil {
+			log.Println(err)
+			if errors.Is(err, entity.ErrInvalidCompanyFilter) {
+				c.JSON(400, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(500, gin.H{"error": "Failed to list companies"})
+			return
+		}
+
+		c.JSON(200, page)
+	}
+}
+
+func GetPlatformCompany(uc usecase.OperatorUsecase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		company, err := uc.GetCompany(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			log.Println(err)
+			writeOperatorCompanyError(c, err, "Failed to get company")
+			return
+		}
+
+		c.JSON(200, company)
+	}
+}
+
+func SuspendCompany(uc usecase.OperatorUsecase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		var input struct {
+			Reason string `json:"reason"`
+		}
+		if err := c.ShouldBindJSON(&input); err != nil {
+			log.Println(err)
+			c.JSON(400, gin.H{"error": "Invalid request"})
+			return
+		}
+
+		id := c.Param("id")
+		c.Set(auditDetailsKey, gin.H{"reason": input.Reason})
+
+		company, err := uc.SuspendCompany(c.Request.Context(), id, input.Reason)
+		if err != nil {
+			log.Println(err)
+			writeOperatorCompanyError(c, err, "Failed to suspend company")
+			return
+		}
+
+		c.JSON(200, company)
+	}
+}
+
+func ReactivateCompany(uc usecase.OperatorUsecase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		company, err := uc.ReactivateCompany(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			log.Println(err)
+			writeOperatorCompanyError(c, err, "Failed to reactivate company")
+			return
+		}
+
+		c.JSON(200, company)
+	}
+}
+
+// ImpersonateCompany hands the operator a short lived company token. The
+// requests made with it are recorded in the audit log.
+func ImpersonateCompany(uc usecase.OperatorUsecase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		var input struct {
+			Reason string `json:"reason"`
+		}
+		if err := c.ShouldBindJSON(&input); err != nil {
+			log.Println(err)
+			c.JSON(400, gin.H{"error": "Invalid request"})
+			return
+		}
+
+		id := c.Param("id")
+		c.Set(auditDetailsKey, gin.H{"reason": input.Reason})
+
+		token, err := uc.Impersonate(c.Request.Context(), c.GetString("operator_id"), id, input.Reason)
+		if err != nil {
+			log.Println(err)
+			writeOperatorCompanyError(c, err, "Failed to impersonate company")
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"token":      token,
+			"expires_at": time.Now().Add(entity.ImpersonationDuration),
+		})
+	}
+}
+
+// GetPlatformRevenue returns the platform revenue between the from and to
+// dates (YYYY-MM-DD, both included), the current month by default.
+func GetPlatformRevenue(uc usecase.OperatorUsecase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		now := time.Now().In(entity.CourtLocation)
+		from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, entity.CourtLocation)
+		to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, entity.CourtLocation).AddDate(0, 0, 1)
+
+		if s := c.Query("from"); s != "" {
+			t, err := time.ParseInLocation(time.DateOnly, s, entity.CourtLocation)
+			if err != nil {
+				c.JSON(400, gin.H{"error": "Invalid from format"})
+				return
+			}
+			from = t
+		}
+
+		if s := c.Query("to"); s != "" {
+			t, err := time.ParseInLocation(time.DateOnly, s, entity.CourtLocation)
+			if err != nil {
+				c.JSON(400, gin.H{"error": "Invalid to format"})
+				return
+			}
+			to = t.AddDate(0, 0, 1)
+		}
+
+		revenue, err := uc.GetRevenue(c.Request.Context(), from, to)
+		if err != nil {
+			log.Println(err)
+			if errors.Is(err, entity.ErrInvalidRevenuePeriod) {
+				c.JSON(400, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(500, gin.H{"error": "Failed to get revenue"})
+			return
+		}
+
+		c.JSON(200, revenue)
+	}
+}
+
+// ListAuditLog returns the latest operator actions, optionally of a single
+// operator_id or company_id.
+func ListAuditLog(uc usecase.OperatorUsecase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		limit, offset, ok := pagination(c, 50)
+		if !ok {
+			return
+		}
+
+		filter := entity.AuditLogFilter{
+			OperatorID: c.Query("operator_id"),
+			CompanyID:  c.Query("company_id"),
+			Limit:      limit,
+			Offset:     offset,
+		}
+		for _, id := range []string{filter.OperatorID, filter.CompanyID} {
+			if id == "" {
+				continue
+			}
+			if _, err := uuid.Parse(id); err != nil {
+				c.JSON(400, gin.H{"error": "Invalid id"})
+				return
+			}
+		}
+
+		entries, err := uc.ListAuditLog(c.Request.Context(), filter)
+		if err != nil {
+			log.Println(err)
+			if errors.Is(err, entity.ErrInvalidAuditLogFilter) {
+				c.JSON(400, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(500, gin.H{"error": "Failed to list audit log"})
+			return
+		}
+
+		c.JSON(200, gin.H{"entries": entries})
+	}
+}
+
+func writeOperatorCompanyError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, entity.ErrReasonRequired):
+		c.JSON(400, gin.H{"error": err.Error()})
+	case errors.Is(err, entity.ErrCompanyNotFound):
+		c.JSON(404, gin.H{"error": "Company not found"})
+	default:
+		c.JSON(500, gin.H{"error": message})
+	}
+}
+
+// pagination reads the limit and offset query parameters. It answers 400 and
+// returns false when they are not numbers.
+func pagination(c *gin.Context, defaultLimit int) (int, int, bool) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultLimit)))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid limit"})
+		return 0, 0, false
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid offset"})
+		return 0, 0, false
+	}
+
+	return limit, offset, true
+}
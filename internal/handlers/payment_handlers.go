@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"errors"
 	"log"
 
+	"github.com/dinizgab/booking-mvp/internal/entity"
 	"github.com/dinizgab/booking-mvp/internal/usecase"
 	"github.com/gin-gonic/gin"
 )
@@ -35,3 +37,47 @@ func GetBookingChargeInformation(uc usecase.PaymentUsecase) func(*gin.Context) {
         })
     }
 }
+
+func ListPaymentMismatches(uc usecase.PaymentUsecase) func(*gin.Context) {
+    return func(c *gin.Context) {
+        companyID := c.GetString("company_id")
+
+        mismatches, err := uc.ListPaymentMismatches(c.Request.Context(), companyID)
+        if err != nil {
+            log.Println(err)
+            c.JSON(500, gin.H{"error": "Failed to list payment mismatches"})
+            return
+        }
+
+        c.JSON(200, mismatches)
+    }
+}
+
+func ResolvePaymentMismatch(uc usecase.PaymentUsecase) func(*gin.Context) {
+    return func(c *gin.Context) {
+        companyID := c.GetString("company_id")
+        id := c.Param("id")
+
+        var resolution entity.MismatchResolution
+        if err := c.ShouldBindJSON(&resolution); err != nil {
+            c.JSON(400, gin.H{"error": "Invalid request body"})
+            return
+        }
+
+        err := uc.ResolvePaymentMismatch(c.Request.Context(), companyID, id, resolution)
+        if err != nil {
+            log.Println(err)
+            switch {
+            case errors.Is(err, entity.ErrInvalidMismatchResolution):
+                c.JSON(400, gin.H{"error": err.Error()})
+            case errors.Is(err, entity.ErrPaymentMismatchNotFound):
+                c.JSON(404, gin.H{"error": "Payment mismatch not found"})
+            default:
+                c.JSON(500, gin.H{"error": "Failed to resolve payment mismatch"})
+            }
+            return
+        }
+
+        c.JSON(200, gin.H{"message": "Payment mismatch resolved successfully"})
+    }
+}
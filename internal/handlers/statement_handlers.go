@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/dinizgab/booking-mvp/internal/entity"
+	"github.com/dinizgab/booking-mvp/internal/services/statement"
+	"github.com/dinizgab/booking-mvp/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// GetCompanyStatement returns the company ledger between the from and to
+// dates (YYYY-MM-DD, both included), the current month by default. The
+// format query parameter exports it as csv or ofx instead of JSON.
+func GetCompanyStatement(uc usecase.StatementUsecase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		now := time.Now().In(entity.CourtLocation)
+		from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, entity.CourtLocation)
+		to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, entity.CourtLocation).AddDate(0, 0, 1)
+
+		if s := c.Query("from"); s != "" {
+			t, err := time.ParseInLocation(time.DateOnly, s, entity.CourtLocation)
+			if err != nil {
+				c.JSON(400, gin.H{"error": "Invalid from format"})
+				return
+			}
+			from = t
+		}
+
+		if s := c.Query("to"); s != "" {
+			t, err := time.ParseInLocation(time.DateOnly, s, entity.CourtLocation)
+			if err != nil {
+				c.JSON(400, gin.H{"error": "Invalid to format"})
+				return
+			}
+			to = t.AddDate(0, 0, 1)
+		}
+
+		format := c.DefaultQuery("format", "json")
+		if format != "json" && format != "csv" && format != "ofx" {
+			c.JSON(400, gin.H{"error": "Invalid format"})
+			return
+		}
+
+		result, err := uc.GetStatement(c.Request.Context(), c.GetString("company_id"), entity.StatementFilter{From: from, To: to})
+		if err != nil {
+			log.Println(err)
+			switch {
+			case errors.Is(err, entity.ErrInvalidStatementPeriod):
+				c.JSON(400, gin.H{"error": err.Error()})
+			case errors.Is(err, entity.ErrCompanyNotFound):
+				c.JSON(404, gin.H{"error": "Company not found"})
+			default:
+				c.JSON(500, gin.H{"error": "Failed to get statement"})
+			}
+			return
+		}
+
+		if format == "json" {
+			c.JSON(200, gin.H{"statement": result})
+			return
+		}
+
+		filename := fmt.Sprintf("extrato-%s-%s.%s", from.Format(time.DateOnly), to.AddDate(0, 0, -1).Format(time.DateOnly), format)
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+		switch format {
+		case "csv":
+			c.Header("Content-Type", "text/csv; charset=utf-8")
+			err = statement.WriteCSV(c.Writer, result)
+		case "ofx":
+			c.Header("Content-Type", "application/x-ofx")
+			err = statement.WriteOFX(c.Writer, result)
+		}
+		if err != nil {
+			log.Println(err)
+		}
+	}
+}
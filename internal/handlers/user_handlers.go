@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+
+	"github.com/dinizgab/booking-mvp/internal/entity"
+	"github.com/dinizgab/booking-mvp/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func LoginUser(uc usecase.UserUsecase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		var input struct {
+			Email    string `json:"email"`
+			Password string `json:"password"`
+		}
+
+		if err := c.ShouldBindJSON(&input); err != nil {
+			log.Println(err)
+			c.JSON(400, gin.H{"error": "Invalid request"})
+			return
+		}
+
+		token, err := uc.Login(c.Request.Context(), input.Email, input.Password)
+		if err != nil {
+			log.Println(err)
+			switch {
+			case errors.Is(err, entity.ErrInvalidCredentials):
+				c.JSON(401, gin.H{"error": "Invalid credentials"})
+			case errors.Is(err, entity.ErrCompanySuspended):
+				c.JSON(403, gin.H{"error": "Company suspended"})
+			default:
+				c.JSON(500, gin.H{"error": "Failed to log in"})
+			}
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"message": "Login successful",
+			"token":   token,
+		})
+	}
+}
+
+func AcceptInvitation(uc usecase.UserUsecase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		var input struct {
+			Token    string `json:"token"`
+			Name     string `json:"name"`
+			Password string `json:"password"`
+		}
+
+		if err := c.ShouldBindJSON(&input); err != nil {
+			log.Println(err)
+			c.JSON(400, gin.H{"error": "Invalid request"})
+			return
+		}
+
+		token, err := uc.AcceptInvitation(c.Request.Context(), input.Token, input.Name, input.Password)
+		if err != nil {
+			log.Println(err)
+			writeUserError(c, err, "Failed to accept invitation")
+			return
+		}
+
+		c.JSON(201, gin.H{
+			"message": "Invitation accepted",
+			"token":   token,
+		})
+	}
+}
+
+// GetCurrentUser returns the logged user with the permissions of its role.
+// Operators impersonating the company have no user.
+func GetCurrentUser(uc usecase.UserUsecase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		role := entity.UserRole(c.GetString("user_role"))
+		response := gin.H{
+			"role":        role,
+			"permissions": role.Permissions(),
+		}
+
+		if userID := c.GetString("user_id"); userID != "" {
+			user, err := uc.Me(c.Request.Context(), c.GetString("company_id"), userID)
+			if err != nil {
+				log.Println(err)
+				writeUserError(c, err, "Failed to get user")
+				return
+			}
+			response["user"] = user
+		}
+
+		if operatorID := c.GetString("operator_id"); operatorID != "" {
+			response["impersonated_by"] = operatorID
+		}
+
+		c.JSON(200, response)
+	}
+}
+
+func ListStaff(uc usecase.UserUsecase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		staff, err := uc.ListStaff(c.Request.Context(), c.GetString("company_id"))
+		if err != nil {
+			log.Println(err)
+			c.JSON(500, gin.H{"error": "Failed to list users"})
+			return
+		}
+
+		c.JSON(200, staff)
+	}
+}
+
+func InviteUser(uc usecase.UserUsecase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		var input struct {
+			Email string          `json:"email"`
+			Role  entity.UserRole `json:"role"`
+		}
+
+		if err := c.ShouldBindJSON(&input); err != nil {
+			log.Println(err)
+			c.JSON(400, gin.H{"error": "Invalid request"})
+			return
+		}
+
+		invitation, err := uc.Invite(
+			c.Request.Context(),
+			c.GetString("company_id"),
+			c.GetString("user_id"),
+			input.Email,
+			input.Role,
+		)
+		if err != nil {
+			log.Println(err)
+			writeUserError(c, err, "Failed to invite user")
+			return
+		}
+
+		c.JSON(201, gin.H{"invitation": invitation})
+	}
+}
+
+func RevokeInvitation(uc usecase.UserUsecase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		err := uc.RevokeInvitation(c.Request.Context(), c.GetString("company_id"), c.Param("id"))
+		if err != nil {
+			log.Println(err)
+			writeUserError(c, err, "Failed to revoke invitation")
+			return
+		}
+
+		c.JSON(200, gin.H{"message": "Invitation revoked"})
+	}
+}
+
+func UpdateUserRole(uc usecase.UserUsecase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		var input struct {
+			Role entity.UserRole `json:"role"`
+		}
+
+		if err := c.ShouldBindJSON(&input); err != nil {
+			log.Println(err)
+			c.JSON(400, gin.H{"error": "Invalid request"})
+			return
+		}
+
+		user, err := uc.UpdateRole(c.Request.Context(), c.GetString("company_id"), c.Param("id"), input.Role)
+		if err != nil {
+			log.Println(err)
+			writeUserError(c, err, "Failed to update user role")
+			return
+		}
+
+		c.JSON(200, gin.H{"user": user})
+	}
+}
+
+func RemoveUser(uc usecase.UserUsecase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		err := uc.Remove(c.Request.Context(), c.GetString("company_id"), c.Param("id"))
+		if err != nil {
+			log.Println(err)
+			writeUserError(c, err, "Failed to remove user")
+			return
+		}
+
+		c.JSON(200, gin.H{"message": "User removed"})
+	}
+}
+
+func writeUserError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, entity.ErrInvalidUser),
+		errors.Is(err, entity.ErrInvalidUserRole):
+		c.JSON(400, gin.H{"error": err.Error()})
+	case errors.Is(err, entity.ErrUserNotFound):
+		c.JSON(404, gin.H{"error": "User not found"})
+	case errors.Is(err, entity.ErrInvitationNotFound):
+		c.JSON(404, gin.H{"error": err.Error()})
+	case errors.Is(err, entity.ErrEmailAlreadyInUse),
+		errors.Is(err, entity.ErrLastOwner):
+		c.JSON(409, gin.H{"error": err.Error()})
+	default:
+		c.JSON(500, gin.H{"error": message})
+	}
+}
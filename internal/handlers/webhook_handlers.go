@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+
+	"github.com/dinizgab/booking-mvp/internal/entity"
+	"github.com/dinizgab/booking-mvp/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func ListWebhookEvents(uc usecase.WebhookUsecase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		companyID := c.GetString("company_id")
+
+		status, ok := parseWebhookEventStatus(c)
+		if !ok {
+			return
+		}
+
+		events, err := uc.ListEvents(c.Request.Context(), companyID, status)
+		if err != nil {
+			log.Println(err)
+			c.JSON(500, gin.H{"error": "Failed to list webhook events"})
+			return
+		}
+
+		c.JSON(200, events)
+	}
+}
+
+func GetWebhookEvent(uc usecase.WebhookUsecase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		companyID := c.GetString("company_id")
+
+		event, err := uc.GetEvent(c.Request.Context(), companyID, c.Param("id"))
+		if err != nil {
+			log.Println(err)
+			writeGetWebhookEventError(c, err)
+			return
+		}
+
+		c.JSON(200, event)
+	}
+}
+
+func ReplayWebhookEvent(uc usecase.WebhookUsecase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		companyID := c.GetString("company_id")
+		id := c.Param("id")
+
+		err := uc.Replay(c.Request.Context(), companyID, id)
+		if err != nil {
+			log.Println(err)
+			writeReplayWebhookEventError(c, err)
+			return
+		}
+
+		c.JSON(200, gin.H{"message": "Webhook event replayed successfully"})
+	}
+}
+
+// ListPlatformWebhookEvents lists the webhook events of every company. It is
+// served to operators only.
+func ListPlatformWebhookEvents(uc usecase.WebhookUsecase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		status, ok := parseWebhookEventStatus(c)
+		if !ok {
+			return
+		}
+
+		events, err := uc.ListPlatformEvents(c.Request.Context(), status)
+		if err != nil {
+			log.Println(err)
+			c.JSON(500, gin.H{"error": "Failed to list webhook events"})
+			return
+		}
+
+		c.JSON(200, events)
+	}
+}
+
+func GetPlatformWebhookEvent(uc usecase.WebhookUsecase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		event, err := uc.GetPlatformEvent(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			log.Println(err)
+			writeGetWebhookEventError(c, err)
+			return
+		}
+
+		c.JSON(200, event)
+	}
+}
+
+func ReplayPlatformWebhookEvent(uc usecase.WebhookUsecase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		err := uc.ReplayPlatformEvent(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			log.Println(err)
+			writeReplayWebhookEventError(c, err)
+			return
+		}
+
+		c.JSON(200, gin.H{"message": "Webhook event replayed successfully"})
+	}
+}
+
+// parseWebhookEventStatus reads the optional status filter. It writes the
+// error response and returns false when the status is unknown.
+func parseWebhookEventStatus(c *gin.Context) (*entity.WebhookEventStatus, bool) {
+	s := c.Query("status")
+	if s == "" {
+		return nil, true
+	}
+
+	status := entity.WebhookEventStatus(s)
+	switch status {
+	case entity.WebhookEventPending, entity.WebhookEventProcessing, entity.WebhookEventProcessed, entity.WebhookEventFailed:
+		return &status, true
+	default:
+		c.JSON(400, gin.H{"error": "Invalid status"})
+		return nil, false
+	}
+}
+
+func writeGetWebhookEventError(c *gin.Context, err error) {
+	if errors.Is(err, entity.ErrWebhookEventNotFound) {
+		c.JSON(404, gin.H{"error": "Webhook event not found"})
+		return
+	}
+
+	c.JSON(500, gin.H{"error": "Failed to get webhook event"})
+}
+
+func writeReplayWebhookEventError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, entity.ErrWebhookEventNotFound):
+		c.JSON(404, gin.H{"error": "Webhook event not found"})
+	case errors.Is(err, entity.ErrWebhookEventNotReplayable):
+		c.JSON(409, gin.H{"error": err.Error()})
+	default:
+		c.JSON(500, gin.H{"error": "Failed to replay webhook event"})
+	}
+}
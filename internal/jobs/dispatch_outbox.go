@@ -0,0 +1,41 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/dinizgab/booking-mvp/internal/usecase"
+)
+
+// dispatchOutboxJob delivers the emails queued in the outbox.
+type dispatchOutboxJob struct {
+	outboxUsecase usecase.OutboxUsecase
+}
+
+func NewDispatchOutboxJob(outboxUsecase usecase.OutboxUsecase) Job {
+	return &dispatchOutboxJob{
+		outboxUsecase: outboxUsecase,
+	}
+}
+
+func (j *dispatchOutboxJob) Name() string {
+	return "dispatch-outbox"
+}
+
+func (j *dispatchOutboxJob) Interval() time.Duration {
+	return 15 * time.Second
+}
+
+func (j *dispatchOutboxJob) Run(ctx context.Context) error {
+	sent, err := j.outboxUsecase.DispatchPending(ctx)
+	if err != nil {
+		return err
+	}
+
+	if sent > 0 {
+		log.Printf("Jobs.DispatchOutbox - delivered %d messages", sent)
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/dinizgab/booking-mvp/internal/usecase"
+)
+
+// expirePaymentsJob expires pending payments past their expiration date and
+// cancels their bookings, covering missed OpenPix expiration webhooks.
+type expirePaymentsJob struct {
+	paymentUsecase usecase.PaymentUsecase
+}
+
+func NewExpirePaymentsJob(paymentUsecase usecase.PaymentUsecase) Job {
+	return &expirePaymentsJob{
+		paymentUsecase: paymentUsecase,
+	}
+}
+
+func (j *expirePaymentsJob) Name() string {
+	return "expire-pending-payments"
+}
+
+func (j *expirePaymentsJob) Interval() time.Duration {
+	return time.Minute
+}
+
+func (j *expirePaymentsJob) Run(ctx context.Context) error {
+	expired, err := j.paymentUsecase.ExpireStalePayments(ctx)
+	if err != nil {
+		return err
+	}
+
+	if expired > 0 {
+		log.Printf("Jobs.ExpirePayments - expired %d pending payments", expired)
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/dinizgab/booking-mvp/internal/entity"
+	"github.com/dinizgab/booking-mvp/internal/usecase"
+)
+
+// reconcileDashboardRefundsWindow is how far back each run looks. Charges can
+// be refunded on the provider dashboards long after they were paid, well past
+// the window of the hourly reconciliation.
+const reconcileDashboardRefundsWindow = 30 * 24 * time.Hour
+
+// reconcileDashboardRefundsJob compares the payments of the last month with
+// the gateway charges to catch the paid charges refunded by hand on the
+// provider dashboards. The other differences are left to reconcilePaymentsJob.
+type reconcileDashboardRefundsJob struct {
+	reconciliationUsecase usecase.ReconciliationUsecase
+}
+
+func NewReconcileDashboardRefundsJob(reconciliationUsecase usecase.ReconciliationUsecase) Job {
+	return &reconcileDashboardRefundsJob{
+		reconciliationUsecase: reconciliationUsecase,
+	}
+}
+
+func (j *reconcileDashboardRefundsJob) Name() string {
+	return "reconcile-dashboard-refunds"
+}
+
+func (j *reconcileDashboardRefundsJob) Interval() time.Duration {
+	return 6 * time.Hour
+}
+
+func (j *reconcileDashboardRefundsJob) Run(ctx context.Context) error {
+	to := time.Now().Add(-reconcilePaymentsSettleDelay)
+	report, err := j.reconciliationUsecase.Reconcile(ctx, to.Add(-reconcileDashboardRefundsWindow), to)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range report.Mismatches() {
+		if item.Issue != entity.ReconciliationRefundedRemotely {
+			continue
+		}
+
+		log.Printf("Jobs.ReconcileDashboardRefunds - %s payment %s refunded on the gateway: %d of %d",
+			item.Provider, item.CorrelationID, item.RemoteRefunded, item.LocalValue)
+	}
+
+	return nil
+}
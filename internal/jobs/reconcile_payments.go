@@ -0,0 +1,56 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/dinizgab/booking-mvp/internal/usecase"
+)
+
+// reconcilePaymentsWindow is how far back each run looks. It overlaps the
+// previous run so charges created around a run are checked twice.
+const reconcilePaymentsWindow = 2 * time.Hour
+
+// reconcilePaymentsSettleDelay skips the most recent charges, whose webhooks
+// may still be in flight.
+const reconcilePaymentsSettleDelay = 15 * time.Minute
+
+// reconcilePaymentsJob compares the recent payments with the gateway charges,
+// covering missed webhooks and changes made on the provider dashboards.
+type reconcilePaymentsJob struct {
+	reconciliationUsecase usecase.ReconciliationUsecase
+}
+
+func NewReconcilePaymentsJob(reconciliationUsecase usecase.ReconciliationUsecase) Job {
+	return &reconcilePaymentsJob{
+		reconciliationUsecase: reconciliationUsecase,
+	}
+}
+
+func (j *reconcilePaymentsJob) Name() string {
+	return "reconcile-payments"
+}
+
+func (j *reconcilePaymentsJob) Interval() time.Duration {
+	return time.Hour
+}
+
+func (j *reconcilePaymentsJob) Run(ctx context.Context) error {
+	to := time.Now().Add(-reconcilePaymentsSettleDelay)
+	report, err := j.reconciliationUsecase.Reconcile(ctx, to.Add(-reconcilePaymentsWindow), to)
+	if err != nil {
+		return err
+	}
+
+	if report.Fixed > 0 {
+		log.Printf("Jobs.ReconcilePayments - fixed %d payments", report.Fixed)
+	}
+
+	for _, item := range report.Mismatches() {
+		log.Printf("Jobs.ReconcilePayments - %s payment %s: %s (local %s %d, remote %s %d)",
+			item.Provider, item.CorrelationID, item.Issue, item.LocalStatus, item.LocalValue, item.RemoteStatus, item.RemoteValue)
+	}
+
+	return nil
+}
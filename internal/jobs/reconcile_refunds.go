@@ -0,0 +1,42 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/dinizgab/booking-mvp/internal/usecase"
+)
+
+// reconcileRefundsJob polls the gateways for refunds still in progress,
+// covering missed refund webhooks.
+type reconcileRefundsJob struct {
+	paymentUsecase usecase.PaymentUsecase
+}
+
+func NewReconcileRefundsJob(paymentUsecase usecase.PaymentUsecase) Job {
+	return &reconcileRefundsJob{
+		paymentUsecase: paymentUsecase,
+	}
+}
+
+func (j *reconcileRefundsJob) Name() string {
+	return "reconcile-pending-refunds"
+}
+
+func (j *reconcileRefundsJob) Interval() time.Duration {
+	return 10 * time.Minute
+}
+
+func (j *reconcileRefundsJob) Run(ctx context.Context) error {
+	updated, err := j.paymentUsecase.ReconcilePendingRefunds(ctx)
+	if err != nil {
+		return err
+	}
+
+	if updated > 0 {
+		log.Printf("Jobs.ReconcileRefunds - updated %d refunds", updated)
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/dinizgab/booking-mvp/internal/usecase"
+)
+
+// reconcileWithdrawalsJob polls the gateways for withdrawals still in
+// progress and records their final status.
+type reconcileWithdrawalsJob struct {
+	paymentUsecase usecase.PaymentUsecase
+}
+
+func NewReconcileWithdrawalsJob(paymentUsecase usecase.PaymentUsecase) Job {
+	return &reconcileWithdrawalsJob{
+		paymentUsecase: paymentUsecase,
+	}
+}
+
+func (j *reconcileWithdrawalsJob) Name() string {
+	return "reconcile-pending-withdrawals"
+}
+
+func (j *reconcileWithdrawalsJob) Interval() time.Duration {
+	return 10 * time.Minute
+}
+
+func (j *reconcileWithdrawalsJob) Run(ctx context.Context) error {
+	updated, err := j.paymentUsecase.ReconcilePendingWithdrawals(ctx)
+	if err != nil {
+		return err
+	}
+
+	if updated > 0 {
+		log.Printf("Jobs.ReconcileWithdrawals - updated %d withdrawals", updated)
+	}
+
+	return nil
+}
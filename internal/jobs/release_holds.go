@@ -0,0 +1,41 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/dinizgab/booking-mvp/internal/usecase"
+)
+
+// releaseHoldsJob frees the slots held by pending bookings whose hold expired.
+type releaseHoldsJob struct {
+	bookingUsecase usecase.BookingUsecase
+}
+
+func NewReleaseHoldsJob(bookingUsecase usecase.BookingUsecase) Job {
+	return &releaseHoldsJob{
+		bookingUsecase: bookingUsecase,
+	}
+}
+
+func (j *releaseHoldsJob) Name() string {
+	return "release-expired-holds"
+}
+
+func (j *releaseHoldsJob) Interval() time.Duration {
+	return time.Minute
+}
+
+func (j *releaseHoldsJob) Run(ctx context.Context) error {
+	released, err := j.bookingUsecase.ReleaseExpiredHolds(ctx)
+	if err != nil {
+		return err
+	}
+
+	if released > 0 {
+		log.Printf("Jobs.ReleaseHolds - released %d expired booking holds", released)
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package jobs
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/dinizgab/booking-mvp/internal/database"
+)
+
+//go:embed sql/try_advisory_xact_lock.sql
+var tryAdvisoryXactLockQuery string
+
+// Job is a unit of background work executed periodically by the Runner.
+type Job interface {
+	Name() string
+	Interval() time.Duration
+	Run(ctx context.Context) error
+}
+
+type Runner interface {
+	Register(job Job)
+	Start(ctx context.Context)
+	Wait()
+}
+
+type runnerImpl struct {
+	db   database.Database
+	jobs []Job
+	wg   sync.WaitGroup
+}
+
+func NewRunner(db database.Database) Runner {
+	return &runnerImpl{
+		db: db,
+	}
+}
+
+func (r *runnerImpl) Register(job Job) {
+	r.jobs = append(r.jobs, job)
+}
+
+// Start schedules every registered job on its own goroutine until ctx is done.
+func (r *runnerImpl) Start(ctx context.Context) {
+	for _, job := range r.jobs {
+		r.wg.Add(1)
+		go r.loop(ctx, job)
+	}
+}
+
+// Wait blocks until every job loop has returned.
+func (r *runnerImpl) Wait() {
+	r.wg.Wait()
+}
+
+func (r *runnerImpl) loop(ctx context.Context, job Job) {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(job.Interval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.runOnce(ctx, job); err != nil {
+				log.Printf("Jobs.Runner - job %s failed: %v", job.Name(), err)
+			}
+		}
+	}
+}
+
+// runOnce executes the job while holding a transaction scoped advisory lock,
+// so only one replica runs a given job at a time.
+func (r *runnerImpl) runOnce(ctx context.Context, job Job) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("Jobs.Runner.runOnce: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var acquired bool
+	err = tx.QueryRow(ctx, tryAdvisoryXactLockQuery, job.Name()).Scan(&acquired)
+	if err != nil {
+		return fmt.Errorf("Jobs.Runner.runOnce - failed to acquire lock: %w", err)
+	}
+
+	if !acquired {
+		return nil
+	}
+
+	if err := job.Run(ctx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("Jobs.Runner.runOnce - failed to release lock: %w", err)
+	}
+
+	return nil
+}
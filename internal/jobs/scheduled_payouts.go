@@ -0,0 +1,42 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/dinizgab/booking-mvp/internal/usecase"
+)
+
+// scheduledPayoutsJob runs the weekly automatic payouts companies opted into.
+// Each company is paid out at most once on its payout day.
+type scheduledPayoutsJob struct {
+	paymentUsecase usecase.PaymentUsecase
+}
+
+func NewScheduledPayoutsJob(paymentUsecase usecase.PaymentUsecase) Job {
+	return &scheduledPayoutsJob{
+		paymentUsecase: paymentUsecase,
+	}
+}
+
+func (j *scheduledPayoutsJob) Name() string {
+	return "run-scheduled-payouts"
+}
+
+func (j *scheduledPayoutsJob) Interval() time.Duration {
+	return time.Hour
+}
+
+func (j *scheduledPayoutsJob) Run(ctx context.Context) error {
+	paid, err := j.paymentUsecase.RunScheduledPayouts(ctx)
+	if err != nil {
+		return err
+	}
+
+	if paid > 0 {
+		log.Printf("Jobs.ScheduledPayouts - paid out %d companies", paid)
+	}
+
+	return nil
+}
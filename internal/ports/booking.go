@@ -13,3 +13,7 @@ type BookingSummaryReader interface {
 type BookingCancelTokenWriter interface {
     SetCancelTokenHash(ctx context.Context, bookingId string, cancelTokenHash string) error
 }
+
+type CommissionPlanReader interface {
+	GetCompanyCommissionPlan(ctx context.Context, companyId string) (entity.CommissionPlan, error)
+}
@@ -0,0 +1,37 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/dinizgab/booking-mvp/internal/entity"
+)
+
+// PaymentGateway is implemented by each payment provider adapter. Account ids
+// are the provider identifier of the company account: the Pix key of the
+// OpenPix subaccount or the connected account id of card providers.
+type PaymentGateway interface {
+	Provider() entity.PaymentProvider
+	CreateAccount(ctx context.Context, company entity.Company) (string, error)
+	// CreateCharge charges the guest commission.Total and credits the company
+	// account with commission.CompanyValue().
+	CreateCharge(ctx context.Context, accountId string, booking entity.Booking, commission entity.Commission) (entity.Charge, error)
+	// CancelCharge stops the unpaid charge of payment from being paid.
+	CancelCharge(ctx context.Context, payment entity.Payment) error
+	// ListCharges returns the charges created in [from, to).
+	ListCharges(ctx context.Context, from time.Time, to time.Time) ([]entity.Charge, error)
+	GetBalance(ctx context.Context, accountId string) (int64, error)
+	// Withdraw moves amount cents of the account balance to the company bank
+	// account, or the whole balance when amount is zero.
+	Withdraw(ctx context.Context, accountId string, amount int64) (entity.Withdrawal, error)
+	// GetWithdrawal fetches the current state of a withdrawal.
+	GetWithdrawal(ctx context.Context, withdrawal entity.Withdrawal) (entity.Withdrawal, error)
+	// RefundCharge gives amount cents of the paid charge back to the payer.
+	RefundCharge(ctx context.Context, payment entity.Payment, amount int64) (entity.Refund, error)
+	// GetRefund fetches the current state of the refund requested for payment.
+	GetRefund(ctx context.Context, payment entity.Payment) (entity.Refund, error)
+	// DecodeChargeEvent reads the charge from a provider webhook payload.
+	DecodeChargeEvent(payload []byte) (entity.Charge, error)
+	// DecodeRefundEvent reads the refund from a provider webhook payload.
+	DecodeRefundEvent(payload []byte) (entity.Refund, error)
+}
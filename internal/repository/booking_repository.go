@@ -3,14 +3,20 @@ package repository
 import (
 	"context"
 	_ "embed"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/dinizgab/booking-mvp/internal/database"
 	"github.com/dinizgab/booking-mvp/internal/entity"
 	"github.com/dinizgab/booking-mvp/internal/ports"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
+// exclusionViolationCode is raised by the no_overlapping_bookings constraint.
+const exclusionViolationCode = "23P01"
+
 type (
 	BookingRepository interface {
 		ports.BookingSummaryReader
@@ -21,10 +27,18 @@ type (
 		FindByIDShowcase(ctx context.Context, id string) (entity.Booking, error)
 		ListByCompanyID(ctx context.Context, companyId string, filter entity.BookingFilter) ([]entity.Booking, error)
 		ConfirmBooking(ctx context.Context, companyId string, bookingId string) error
-		CancelBooking(ctx context.Context, id string) error
+		CancelBooking(ctx context.Context, id string, messages ...entity.OutboxMessage) error
 		Update(ctx context.Context, booking entity.Booking) error
 		Delete(ctx context.Context, id string) error
 		GetCancelTokenInfo(ctx context.Context, bookingId string) (entity.Booking, error)
+		ReleaseExpiredHolds(ctx context.Context) (int64, error)
+		CreateSeries(ctx context.Context, series entity.BookingSeries, occurrences []entity.Booking) (entity.SeriesCreationResult, error)
+		FindSeriesByID(ctx context.Context, companyId string, id string) (entity.BookingSeries, error)
+		CancelSeries(ctx context.Context, companyId string, id string) error
+		CancelSeriesOccurrence(ctx context.Context, companyId string, seriesId string, bookingId string) error
+		FindCancellable(ctx context.Context, companyId string, id string) (entity.Booking, error)
+		ListCancellableByCourt(ctx context.Context, companyId string, courtId string, from time.Time, to time.Time) ([]entity.Booking, error)
+		CancelByCompany(ctx context.Context, companyId string, id string, reason string, messages ...entity.OutboxMessage) error
 	}
 
 	bookingRepositoryImpl struct {
@@ -45,6 +59,12 @@ var (
 	confirmBookingQuery string
 	//go:embed sql/booking/cancel_booking.sql
 	cancelBookingQuery string
+	//go:embed sql/booking/cancel_booking_by_company.sql
+	cancelBookingByCompanyQuery string
+	//go:embed sql/booking/find_cancellable_booking.sql
+	findCancellableBookingQuery string
+	//go:embed sql/booking/list_cancellable_court_bookings.sql
+	listCancellableCourtBookingsQuery string
 	//go:embed sql/booking/update_booking.sql
 	updateBookingQuery string
 	//go:embed sql/booking/delete_booking.sql
@@ -55,6 +75,18 @@ var (
 	setCancelTokenHashQuery string
 	//go:embed sql/booking/get_cancel_token_info.sql
 	getCancelTokenInfoQuery string
+	//go:embed sql/booking/release_expired_holds.sql
+	releaseExpiredHoldsQuery string
+	//go:embed sql/booking/create_booking_series.sql
+	createBookingSeriesQuery string
+	//go:embed sql/booking/find_booking_series_by_id.sql
+	findBookingSeriesByIDQuery string
+	//go:embed sql/booking/list_booking_series_occurrences.sql
+	listBookingSeriesOccurrencesQuery string
+	//go:embed sql/booking/cancel_booking_series.sql
+	cancelBookingSeriesQuery string
+	//go:embed sql/booking/cancel_booking_series_occurrence.sql
+	cancelBookingSeriesOccurrenceQuery string
 )
 
 func NewBookingRepository(db database.Database) BookingRepository {
@@ -64,26 +96,15 @@ func NewBookingRepository(db database.Database) BookingRepository {
 }
 
 func (r *bookingRepositoryImpl) Create(ctx context.Context, booking entity.Booking) (string, error) {
-	row := r.db.QueryRow(
-		ctx,
-		createBookingQuery,
-		booking.CourtId,
-		booking.StartTime,
-		booking.EndTime,
-		booking.GuestName,
-		booking.GuestEmail,
-		booking.GuestPhone,
-		booking.Status,
-		booking.VerificationCode,
-		booking.TotalPrice,
-		booking.CancelTokenHash,
-		booking.Court.CompanyId,
-	)
+	row := r.db.QueryRow(ctx, createBookingQuery, createBookingArgs(booking)...)
 
 	var id string
 
 	err := row.Scan(&id)
 	if err != nil {
+		if isExclusionViolation(err) {
+			return "", entity.ErrSlotUnavailable
+		}
 		return "", fmt.Errorf("BookingRepository.Create - error scanning row: %w", err)
 	}
 
@@ -117,6 +138,7 @@ func (r *bookingRepositoryImpl) ListByCompanyID(ctx context.Context, companyId s
 			&booking.GuestName,
 			&booking.GuestPhone,
 			&booking.GuestEmail,
+			&booking.PaymentMethod,
 			&court.Name,
 		)
 		if err != nil {
@@ -149,6 +171,8 @@ func (r *bookingRepositoryImpl) FindByID(ctx context.Context, id string) (entity
 		&booking.GuestEmail,
 		&booking.VerificationCode,
 		&booking.TotalPrice,
+		&booking.PaymentMethod,
+		&booking.CreatedBy,
 		&court.Name,
 	)
 	if err != nil {
@@ -197,11 +221,28 @@ func (r *bookingRepositoryImpl) ConfirmBooking(ctx context.Context, companyId st
 	return nil
 }
 
-func (r *bookingRepositoryImpl) CancelBooking(ctx context.Context, id string) error {
-	_, err := r.db.Exec(ctx, cancelBookingQuery, id)
+// CancelBooking cancels the booking, expires its pending payment and enqueues
+// the messages in the same transaction.
+func (r *bookingRepositoryImpl) CancelBooking(ctx context.Context, id string, messages ...entity.OutboxMessage) error {
+	tx, err := r.db.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("BookingRepository.CancelBooking: %w", err)
 	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	if _, err := tx.Exec(ctx, cancelBookingQuery, id); err != nil {
+		return fmt.Errorf("BookingRepository.CancelBooking: %w", err)
+	}
+
+	if err := insertOutboxMessages(ctx, tx, messages); err != nil {
+		return fmt.Errorf("BookingRepository.CancelBooking: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("BookingRepository.CancelBooking: %w", err)
+	}
 
 	return nil
 }
@@ -234,8 +275,11 @@ func (r *bookingRepositoryImpl) GetBookingSummary(ctx context.Context, bookingId
 		&booking.StartTime,
 		&booking.EndTime,
         &booking.TotalPrice,
+		&booking.PlatformFee,
 		&booking.VerificationCode,
 		&booking.CancelTokenHash,
+		&company.Email,
+		&booking.Status,
 	)
 	if err != nil {
 		return entity.Booking{}, fmt.Errorf("BookingRepository.GetBookingConfirmationInfo: %w", err)
@@ -258,7 +302,8 @@ func (r *bookingRepositoryImpl) SetCancelTokenHash(ctx context.Context, bookingI
 
 func (r *bookingRepositoryImpl) GetCancelTokenInfo(ctx context.Context, bookingId string) (entity.Booking, error) {
 	var booking entity.Booking
-	err := r.db.QueryRow(ctx, getCancelTokenInfoQuery, bookingId).Scan(&booking.CancelTokenHash, &booking.CancelTokenHashExpiresAt)
+	booking.Court = &entity.Court{}
+	err := r.db.QueryRow(ctx, getCancelTokenInfoQuery, bookingId).Scan(&booking.CancelTokenHash, &booking.StartTime, &booking.Status, &booking.Court.CompanyId, &booking.SeriesId)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return booking, fmt.Errorf("BookingRepository.GetCancelTokenInfo: booking not found")
@@ -268,3 +313,310 @@ func (r *bookingRepositoryImpl) GetCancelTokenInfo(ctx context.Context, bookingI
 
 	return booking, nil
 }
+
+func (r *bookingRepositoryImpl) ReleaseExpiredHolds(ctx context.Context) (int64, error) {
+	var released int64
+	err := r.db.QueryRow(ctx, releaseExpiredHoldsQuery).Scan(&released)
+	if err != nil {
+		return 0, fmt.Errorf("BookingRepository.ReleaseExpiredHolds: %w", err)
+	}
+
+	return released, nil
+}
+
+func isExclusionViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == exclusionViolationCode
+}
+
+// CreateSeries stores the series and every occurrence that does not overlap an
+// existing booking. Overlapping occurrences are reported as conflicts. If no
+// occurrence could be booked the series is discarded and ErrSlotUnavailable is
+// returned alongside the conflicts.
+func (r *bookingRepositoryImpl) CreateSeries(ctx context.Context, series entity.BookingSeries, occurrences []entity.Booking) (entity.SeriesCreationResult, error) {
+	result := entity.SeriesCreationResult{
+		Created:   make([]entity.Booking, 0, len(occurrences)),
+		Conflicts: make([]entity.Booking, 0),
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return result, fmt.Errorf("BookingRepository.CreateSeries: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	err = tx.QueryRow(
+		ctx,
+		createBookingSeriesQuery,
+		series.CompanyId,
+		series.CourtId,
+		series.GuestName,
+		series.GuestPhone,
+		series.GuestEmail,
+		series.Frequency,
+		series.StartTime,
+		series.EndTime,
+		series.Until,
+		nullableCount(series.Count),
+		series.PaymentMode,
+		series.Status,
+	).Scan(&series.ID, &series.CreatedAt)
+	if err != nil {
+		return result, fmt.Errorf("BookingRepository.CreateSeries: %w", err)
+	}
+
+	for _, occurrence := range occurrences {
+		occurrence.SeriesId = &series.ID
+
+		savepoint, err := tx.Begin(ctx)
+		if err != nil {
+			return result, fmt.Errorf("BookingRepository.CreateSeries - savepoint: %w", err)
+		}
+
+		err = savepoint.QueryRow(ctx, createBookingQuery, createBookingArgs(occurrence)...).Scan(&occurrence.ID)
+		if err != nil {
+			_ = savepoint.Rollback(ctx)
+			if isExclusionViolation(err) {
+				result.Conflicts = append(result.Conflicts, occurrence)
+				continue
+			}
+
+			return result, fmt.Errorf("BookingRepository.CreateSeries - error creating occurrence: %w", err)
+		}
+
+		if err := savepoint.Commit(ctx); err != nil {
+			return result, fmt.Errorf("BookingRepository.CreateSeries - release savepoint: %w", err)
+		}
+
+		result.Created = append(result.Created, occurrence)
+	}
+
+	result.Series = series
+	if len(result.Created) == 0 {
+		return result, entity.ErrSlotUnavailable
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return result, fmt.Errorf("BookingRepository.CreateSeries - commit tx: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *bookingRepositoryImpl) FindSeriesByID(ctx context.Context, companyId string, id string) (entity.BookingSeries, error) {
+	var series entity.BookingSeries
+	err := r.db.QueryRow(ctx, findBookingSeriesByIDQuery, id, companyId).Scan(
+		&series.ID,
+		&series.CompanyId,
+		&series.CourtId,
+		&series.GuestName,
+		&series.GuestPhone,
+		&series.GuestEmail,
+		&series.Frequency,
+		&series.StartTime,
+		&series.EndTime,
+		&series.Until,
+		&series.Count,
+		&series.PaymentMode,
+		&series.Status,
+		&series.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.BookingSeries{}, entity.ErrSeriesNotFound
+		}
+		return entity.BookingSeries{}, fmt.Errorf("BookingRepository.FindSeriesByID: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, listBookingSeriesOccurrencesQuery, id)
+	if err != nil {
+		return entity.BookingSeries{}, fmt.Errorf("BookingRepository.FindSeriesByID - error querying occurrences: %w", err)
+	}
+	defer rows.Close()
+
+	series.Bookings = make([]entity.Booking, 0)
+	for rows.Next() {
+		var booking entity.Booking
+		err := rows.Scan(
+			&booking.ID,
+			&booking.CourtId,
+			&booking.StartTime,
+			&booking.EndTime,
+			&booking.CreatedAt,
+			&booking.Status,
+			&booking.TotalPrice,
+		)
+		if err != nil {
+			return entity.BookingSeries{}, fmt.Errorf("BookingRepository.FindSeriesByID - error scanning occurrence: %w", err)
+		}
+
+		booking.SeriesId = &series.ID
+		series.Bookings = append(series.Bookings, booking)
+	}
+	if err := rows.Err(); err != nil {
+		return entity.BookingSeries{}, fmt.Errorf("BookingRepository.FindSeriesByID: %w", err)
+	}
+
+	return series, nil
+}
+
+// CancelSeries cancels the series and its upcoming occurrences, expiring their
+// pending payments. Occurrences whose payment was refunded first are already
+// cancelled but still get the cancellation details.
+func (r *bookingRepositoryImpl) CancelSeries(ctx context.Context, companyId string, id string) error {
+	_, err := r.db.Exec(ctx, cancelBookingSeriesQuery, id, companyId)
+	if err != nil {
+		return fmt.Errorf("BookingRepository.CancelSeries: %w", err)
+	}
+
+	return nil
+}
+
+// CancelSeriesOccurrence cancels one occurrence of the series, expiring its
+// pending payment.
+func (r *bookingRepositoryImpl) CancelSeriesOccurrence(ctx context.Context, companyId string, seriesId string, bookingId string) error {
+	var cancelled int
+	err := r.db.QueryRow(ctx, cancelBookingSeriesOccurrenceQuery, seriesId, companyId, bookingId).Scan(&cancelled)
+	if err != nil {
+		return fmt.Errorf("BookingRepository.CancelSeriesOccurrence: %w", err)
+	}
+
+	if cancelled == 0 {
+		return entity.ErrBookingNotFound
+	}
+
+	return nil
+}
+
+// FindCancellable returns the booking fields needed to cancel it on behalf of
+// the company that owns it.
+func (r *bookingRepositoryImpl) FindCancellable(ctx context.Context, companyId string, id string) (entity.Booking, error) {
+	booking, err := scanCancellableBooking(r.db.QueryRow(ctx, findCancellableBookingQuery, id, companyId))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.Booking{}, entity.ErrBookingNotFound
+		}
+		return entity.Booking{}, fmt.Errorf("BookingRepository.FindCancellable: %w", err)
+	}
+
+	return booking, nil
+}
+
+// ListCancellableByCourt returns the upcoming pending and confirmed bookings of
+// the court that start in the [from, to) range.
+func (r *bookingRepositoryImpl) ListCancellableByCourt(ctx context.Context, companyId string, courtId string, from time.Time, to time.Time) ([]entity.Booking, error) {
+	rows, err := r.db.Query(ctx, listCancellableCourtBookingsQuery, courtId, companyId, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("BookingRepository.ListCancellableByCourt: %w", err)
+	}
+	defer rows.Close()
+
+	bookings := make([]entity.Booking, 0)
+	for rows.Next() {
+		booking, err := scanCancellableBooking(rows)
+		if err != nil {
+			return nil, fmt.Errorf("BookingRepository.ListCancellableByCourt: %w", err)
+		}
+		bookings = append(bookings, booking)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("BookingRepository.ListCancellableByCourt: %w", err)
+	}
+
+	return bookings, nil
+}
+
+// CancelByCompany cancels the booking with the given reason and enqueues the
+// guest notifications in the same transaction. A booking whose payment was
+// refunded first is already marked as cancelled but has no cancellation
+// details yet, so it is still updated here. A payment still pending is
+// expired, so a late Pix is refunded instead of confirmed.
+func (r *bookingRepositoryImpl) CancelByCompany(ctx context.Context, companyId string, id string, reason string, messages ...entity.OutboxMessage) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("BookingRepository.CancelByCompany: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	tag, err := tx.Exec(ctx, cancelBookingByCompanyQuery, id, companyId, reason)
+	if err != nil {
+		return fmt.Errorf("BookingRepository.CancelByCompany: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return entity.ErrBookingAlreadyCancelled
+	}
+
+	if err := insertOutboxMessages(ctx, tx, messages); err != nil {
+		return fmt.Errorf("BookingRepository.CancelByCompany: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("BookingRepository.CancelByCompany: %w", err)
+	}
+
+	return nil
+}
+
+func scanCancellableBooking(row pgx.Row) (entity.Booking, error) {
+	var booking entity.Booking
+	err := row.Scan(
+		&booking.ID,
+		&booking.CourtId,
+		&booking.StartTime,
+		&booking.EndTime,
+		&booking.Status,
+		&booking.PaymentMethod,
+		&booking.SeriesId,
+	)
+
+	return booking, err
+}
+
+func createBookingArgs(booking entity.Booking) []any {
+	return []any{
+		booking.CourtId,
+		booking.StartTime,
+		booking.EndTime,
+		booking.GuestName,
+		booking.GuestEmail,
+		booking.GuestPhone,
+		booking.Status,
+		booking.VerificationCode,
+		booking.TotalPrice,
+		booking.CancelTokenHash,
+		booking.Court.CompanyId,
+		nullableTime(booking.HoldExpiresAt),
+		booking.SeriesId,
+		booking.PaymentMethod,
+		nullableString(booking.CreatedBy),
+	}
+}
+
+func nullableTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
+
+func nullableString(s string) *string {
+	if s == "" {
+		return nil
+	}
+
+	return &s
+}
+
+func nullableCount(count int) *int {
+	if count <= 0 {
+		return nil
+	}
+
+	return &count
+}
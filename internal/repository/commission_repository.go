@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+
+	"github.com/dinizgab/booking-mvp/internal/database"
+	"github.com/dinizgab/booking-mvp/internal/entity"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	//go:embed sql/commission/list_commission_plans.sql
+	listCommissionPlansQuery string
+	//go:embed sql/commission/find_commission_plan_by_id.sql
+	findCommissionPlanByIDQuery string
+	//go:embed sql/commission/get_company_commission_plan.sql
+	getCompanyCommissionPlanQuery string
+	//go:embed sql/commission/create_commission_plan.sql
+	createCommissionPlanQuery string
+	//go:embed sql/commission/update_commission_plan.sql
+	updateCommissionPlanQuery string
+	//go:embed sql/commission/clear_default_commission_plan.sql
+	clearDefaultCommissionPlanQuery string
+	//go:embed sql/commission/set_company_commission_plan.sql
+	setCompanyCommissionPlanQuery string
+)
+
+type CommissionPlanRepository interface {
+	List(ctx context.Context) ([]entity.CommissionPlan, error)
+	FindByID(ctx context.Context, id string) (entity.CommissionPlan, error)
+	Create(ctx context.Context, plan entity.CommissionPlan) (entity.CommissionPlan, error)
+	Update(ctx context.Context, plan entity.CommissionPlan) (entity.CommissionPlan, error)
+	GetCompanyCommissionPlan(ctx context.Context, companyId string) (entity.CommissionPlan, error)
+	SetCompanyCommissionPlan(ctx context.Context, companyId string, planId string) error
+}
+
+type commissionPlanRepositoryImpl struct {
+	db database.Database
+}
+
+func NewCommissionPlanRepository(db database.Database) CommissionPlanRepository {
+	return &commissionPlanRepositoryImpl{
+		db: db,
+	}
+}
+
+func (r *commissionPlanRepositoryImpl) List(ctx context.Context) ([]entity.CommissionPlan, error) {
+	rows, err := r.db.Query(ctx, listCommissionPlansQuery)
+	if err != nil {
+		return nil, fmt.Errorf("CommissionPlanRepository.List: %w", err)
+	}
+	defer rows.Close()
+
+	plans := make([]entity.CommissionPlan, 0)
+	for rows.Next() {
+		plan, err := scanCommissionPlan(rows)
+		if err != nil {
+			return nil, fmt.Errorf("CommissionPlanRepository.List: %w", err)
+		}
+		plans = append(plans, plan)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("CommissionPlanRepository.List: %w", err)
+	}
+
+	return plans, nil
+}
+
+func (r *commissionPlanRepositoryImpl) FindByID(ctx context.Context, id string) (entity.CommissionPlan, error) {
+	plan, err := scanCommissionPlan(r.db.QueryRow(ctx, findCommissionPlanByIDQuery, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.CommissionPlan{}, entity.ErrCommissionPlanNotFound
+		}
+		return entity.CommissionPlan{}, fmt.Errorf("CommissionPlanRepository.FindByID: %w", err)
+	}
+
+	return plan, nil
+}
+
+// Create stores the plan. A new default plan replaces the previous one.
+func (r *commissionPlanRepositoryImpl) Create(ctx context.Context, plan entity.CommissionPlan) (entity.CommissionPlan, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return entity.CommissionPlan{}, fmt.Errorf("CommissionPlanRepository.Create: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	if plan.IsDefault {
+		if _, err := tx.Exec(ctx, clearDefaultCommissionPlanQuery, nil); err != nil {
+			return entity.CommissionPlan{}, fmt.Errorf("CommissionPlanRepository.Create: %w", err)
+		}
+	}
+
+	plan, err = scanCommissionPlan(tx.QueryRow(
+		ctx,
+		createCommissionPlanQuery,
+		plan.Name,
+		plan.PercentBps,
+		plan.FixedFee,
+		plan.PaidBy,
+		plan.IsDefault,
+	))
+	if err != nil {
+		return entity.CommissionPlan{}, fmt.Errorf("CommissionPlanRepository.Create: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return entity.CommissionPlan{}, fmt.Errorf("CommissionPlanRepository.Create: %w", err)
+	}
+
+	return plan, nil
+}
+
+// Update changes the plan. Making it the default replaces the previous
+// default plan.
+func (r *commissionPlanRepositoryImpl) Update(ctx context.Context, plan entity.CommissionPlan) (entity.CommissionPlan, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return entity.CommissionPlan{}, fmt.Errorf("CommissionPlanRepository.Update: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	if plan.IsDefault {
+		if _, err := tx.Exec(ctx, clearDefaultCommissionPlanQuery, plan.ID); err != nil {
+			return entity.CommissionPlan{}, fmt.Errorf("CommissionPlanRepository.Update: %w", err)
+		}
+	}
+
+	plan, err = scanCommissionPlan(tx.QueryRow(
+		ctx,
+		updateCommissionPlanQuery,
+		plan.ID,
+		plan.Name,
+		plan.PercentBps,
+		plan.FixedFee,
+		plan.PaidBy,
+		plan.IsDefault,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.CommissionPlan{}, entity.ErrCommissionPlanNotFound
+		}
+		return entity.CommissionPlan{}, fmt.Errorf("CommissionPlanRepository.Update: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return entity.CommissionPlan{}, fmt.Errorf("CommissionPlanRepository.Update: %w", err)
+	}
+
+	return plan, nil
+}
+
+// GetCompanyCommissionPlan returns the plan attached to the company, or the
+// default plan when it has none.
+func (r *commissionPlanRepositoryImpl) GetCompanyCommissionPlan(ctx context.Context, companyId string) (entity.CommissionPlan, error) {
+	plan, err := scanCommissionPlan(r.db.QueryRow(ctx, getCompanyCommissionPlanQuery, companyId))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.CommissionPlan{}, entity.ErrCommissionPlanNotFound
+		}
+		return entity.CommissionPlan{}, fmt.Errorf("CommissionPlanRepository.GetCompanyCommissionPlan: %w", err)
+	}
+
+	return plan, nil
+}
+
+// SetCompanyCommissionPlan attaches the plan to the company. An empty plan id
+// moves the company back to the default plan.
+func (r *commissionPlanRepositoryImpl) SetCompanyCommissionPlan(ctx context.Context, companyId string, planId string) error {
+	tag, err := r.db.Exec(ctx, setCompanyCommissionPlanQuery, companyId, nullableString(planId))
+	if err != nil {
+		return fmt.Errorf("CommissionPlanRepository.SetCompanyCommissionPlan: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return entity.ErrCompanyNotFound
+	}
+
+	return nil
+}
+
+func scanCommissionPlan(row pgx.Row) (entity.CommissionPlan, error) {
+	var plan entity.CommissionPlan
+	err := row.Scan(
+		&plan.ID,
+		&plan.Name,
+		&plan.PercentBps,
+		&plan.FixedFee,
+		&plan.PaidBy,
+		&plan.IsDefault,
+		&plan.CreatedAt,
+		&plan.UpdatedAt,
+	)
+
+	return plan, err
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 
@@ -17,11 +18,13 @@ type (
 	CompanyRepository interface {
 		Create(ctx context.Context, company entity.Company) (entity.Company, error)
 		FindByID(ctx context.Context, id string) (entity.Company, error)
-		FindByEmail(ctx context.Context, email string) (entity.Company, error)
 		FindByIDShowcase(ctx context.Context, slug string) (entity.Company, error)
 		GetDashboardInfo(ctx context.Context, companyId string) (entity.CompanyDashboard, error)
 		Update(ctx context.Context, id string, company entity.Company) error
 		Delete(ctx context.Context, id string) error
+		GetCancellationPolicy(ctx context.Context, companyId string) (entity.CancellationPolicy, error)
+		SaveCancellationPolicy(ctx context.Context, policy entity.CancellationPolicy) error
+		IsSuspended(ctx context.Context, id string) (bool, error)
 	}
 
 	companyRepositoryImpl struct {
@@ -34,8 +37,6 @@ var (
 	createCompanyQuery string
 	//go:embed sql/company/find_company_by_id.sql
 	findCompanyByIDQuery string
-	//go:embed sql/company/find_company_by_email.sql
-	findCompanyByEmailQuery string
 	//go:embed sql/company/find_company_by_id_showcase.sql
 	findCompanyByIDShowcaseQuery string
 	//go:embed sql/company/get_dashboard_info.sql
@@ -44,6 +45,12 @@ var (
 	updateCompanyQuery string
 	//go:embed sql/company/delete_company.sql
 	deleteCompanyQuery string
+	//go:embed sql/company/get_cancellation_policy.sql
+	getCancellationPolicyQuery string
+	//go:embed sql/company/save_cancellation_policy.sql
+	saveCancellationPolicyQuery string
+	//go:embed sql/company/is_company_suspended.sql
+	isCompanySuspendedQuery string
 )
 
 func NewCompanyRepository(db database.Database) CompanyRepository {
@@ -59,10 +66,10 @@ func (r *companyRepositoryImpl) Create(ctx context.Context, company entity.Compa
 		company.Address,
 		company.Phone,
 		company.Email,
-		company.Password,
 		company.CNPJ,
 		company.Slug,
-	).Scan(&company.ID)
+		string(company.PaymentProvider),
+	).Scan(&company.ID, &company.PaymentProvider)
 	if err != nil {
 		return company, fmt.Errorf("CompanyRepository.Create - error creating company: %w", err)
 	}
@@ -84,6 +91,7 @@ func (r *companyRepositoryImpl) FindByID(ctx context.Context, id string) (entity
 		&company.Slug,
 		&pixKey,
 		&pixKeyType,
+		&company.PaymentProvider,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -101,24 +109,6 @@ func (r *companyRepositoryImpl) FindByID(ctx context.Context, id string) (entity
 	return company, nil
 }
 
-func (r *companyRepositoryImpl) FindByEmail(ctx context.Context, email string) (entity.Company, error) {
-	var company entity.Company
-	err := r.db.QueryRow(ctx, findCompanyByEmailQuery, email).Scan(
-		&company.ID,
-		&company.Email,
-		&company.Password,
-	)
-	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return entity.Company{}, err
-		}
-
-		return entity.Company{}, fmt.Errorf("CompanyRepository.FindByEmail: %w", err)
-	}
-
-	return company, nil
-}
-
 func (r *companyRepositoryImpl) FindByIDShowcase(ctx context.Context, slug string) (entity.Company, error) {
 	var company entity.Company
 	err := r.db.QueryRow(ctx, findCompanyByIDShowcaseQuery, slug).Scan(
@@ -144,6 +134,7 @@ func (r *companyRepositoryImpl) GetDashboardInfo(ctx context.Context, companyId
 	var dashboard entity.CompanyDashboard
 	err := r.db.QueryRow(ctx, getDashboardInfoQuery, companyId).Scan(
 		&dashboard.TotalEarnings,
+		&dashboard.TotalCommission,
 		&dashboard.TotalBookedHours,
 		&dashboard.TotalBookings,
 		&dashboard.TotalClients,
@@ -166,6 +157,7 @@ func (r *companyRepositoryImpl) Update(ctx context.Context, id string, company e
 		company.CNPJ,
 		company.Slug,
 		id,
+		string(company.PaymentProvider),
 	)
 	if err != nil {
 		return fmt.Errorf("CompanyRepository.Update: %w", err)
@@ -182,3 +174,55 @@ func (r *companyRepositoryImpl) Delete(ctx context.Context, id string) error {
 
 	return nil
 }
+
+// GetCancellationPolicy returns the company policy, or the default one when the
+// company never configured it.
+func (r *companyRepositoryImpl) GetCancellationPolicy(ctx context.Context, companyId string) (entity.CancellationPolicy, error) {
+	var policy entity.CancellationPolicy
+	var tiers []byte
+	err := r.db.QueryRow(ctx, getCancellationPolicyQuery, companyId).Scan(
+		&policy.CompanyId,
+		&tiers,
+		&policy.RefundPlatformFee,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.DefaultCancellationPolicy(companyId), nil
+		}
+		return entity.CancellationPolicy{}, fmt.Errorf("CompanyRepository.GetCancellationPolicy: %w", err)
+	}
+
+	if err := json.Unmarshal(tiers, &policy.Tiers); err != nil {
+		return entity.CancellationPolicy{}, fmt.Errorf("CompanyRepository.GetCancellationPolicy: %w", err)
+	}
+
+	return policy, nil
+}
+
+func (r *companyRepositoryImpl) SaveCancellationPolicy(ctx context.Context, policy entity.CancellationPolicy) error {
+	tiers, err := json.Marshal(policy.Tiers)
+	if err != nil {
+		return fmt.Errorf("CompanyRepository.SaveCancellationPolicy: %w", err)
+	}
+
+	_, err = r.db.Exec(ctx, saveCancellationPolicyQuery, policy.CompanyId, tiers, policy.RefundPlatformFee)
+	if err != nil {
+		return fmt.Errorf("CompanyRepository.SaveCancellationPolicy: %w", err)
+	}
+
+	return nil
+}
+
+func (r *companyRepositoryImpl) IsSuspended(ctx context.Context, id string) (bool, error) {
+	var suspended bool
+	err := r.db.QueryRow(ctx, isCompanySuspendedQuery, id).Scan(&suspended)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, entity.ErrCompanyNotFound
+		}
+
+		return false, fmt.Errorf("CompanyRepository.IsSuspended: %w", err)
+	}
+
+	return suspended, nil
+}
@@ -27,9 +27,18 @@ type (
 		ListByCompany(ctx context.Context, companyID string) ([]entity.Court, error)
 		ListCompanyCourtsShowcase(ctx context.Context, companyID string) ([]entity.Court, error)
 		ListAvailableBookingSlots(ctx context.Context, id string, date string) ([]entity.Booking, error)
+		ListBookingsInRange(ctx context.Context, id string, start time.Time, end time.Time) ([]entity.Booking, error)
 		Update(ctx context.Context, id string, c entity.Court) error
 		Delete(ctx context.Context, id string) error
 		UpdateCourtStatus(ctx context.Context, id string, court entity.Court) error
+		CreateBlackout(ctx context.Context, blackout entity.CourtBlackout) (string, error)
+		UpdateBlackout(ctx context.Context, blackout entity.CourtBlackout) error
+		DeleteBlackout(ctx context.Context, companyId string, courtId *string, id string) error
+		ListCourtBlackouts(ctx context.Context, courtId string) ([]entity.CourtBlackout, error)
+		ListCompanyBlackouts(ctx context.Context, companyId string) ([]entity.CourtBlackout, error)
+		ListBlackoutsInRange(ctx context.Context, courtId string, start time.Time, end time.Time) ([]entity.CourtBlackout, error)
+		ListPricingRules(ctx context.Context, courtId string) ([]entity.PricingRule, error)
+		ReplacePricingRules(ctx context.Context, courtId string, rules []entity.PricingRule) error
 	}
 
 	courtRepositoryImpl struct {
@@ -50,6 +59,8 @@ var (
 	listCompanyCourtsShowcaseQuery string
 	//go:embed sql/court/list_available_booking_slots.sql
 	listAvailableBookingSlotsQuery string
+	//go:embed sql/court/list_court_bookings_in_range.sql
+	listCourtBookingsInRangeQuery string
 	//go:embed sql/court/list_court_photos.sql
 	listCourtPhotosQuery string
 	//go:embed sql/court/list_court_schedule.sql
@@ -62,6 +73,24 @@ var (
 	deleteCourtQuery string
 	//go:embed sql/court/update_court_status.sql
 	updateCourtStatusQuery string
+	//go:embed sql/court/create_court_blackout.sql
+	createCourtBlackoutQuery string
+	//go:embed sql/court/update_court_blackout.sql
+	updateCourtBlackoutQuery string
+	//go:embed sql/court/delete_court_blackout.sql
+	deleteCourtBlackoutQuery string
+	//go:embed sql/court/list_court_blackouts.sql
+	listCourtBlackoutsQuery string
+	//go:embed sql/court/list_company_blackouts.sql
+	listCompanyBlackoutsQuery string
+	//go:embed sql/court/list_court_blackouts_in_range.sql
+	listCourtBlackoutsInRangeQuery string
+	//go:embed sql/court/list_court_pricing_rules.sql
+	listCourtPricingRulesQuery string
+	//go:embed sql/court/delete_court_pricing_rules.sql
+	deleteCourtPricingRulesQuery string
+	//go:embed sql/court/create_court_pricing_rule.sql
+	createCourtPricingRuleQuery string
 )
 
 func NewCourtRepository(db database.Database) CourtRepository {
@@ -83,6 +112,7 @@ func (r *courtRepositoryImpl) Create(ctx context.Context, c *entity.Court) (stri
 		}
 	}()
 
+	policy := c.Policy()
 	var id string
 	err = tx.QueryRow(
 		ctx,
@@ -94,6 +124,11 @@ func (r *courtRepositoryImpl) Create(ctx context.Context, c *entity.Court) (stri
 		c.HourlyPrice,
 		c.IsActive,
 		c.Capacity,
+		policy.MinDurationMinutes,
+		policy.MaxDurationMinutes,
+		policy.SlotGranularityMinutes,
+		policy.LeadTimeMinutes,
+		policy.MaxAdvanceDays,
 	).Scan(
 		&id,
 	)
@@ -161,6 +196,7 @@ func (r *courtRepositoryImpl) FindByID(ctx context.Context, id string) (entity.C
 	// An array instead of a single photo
 	var courtPhotos []entity.CourtPhoto
 	var courtSchedule []entity.CourtSchedule
+	var policy entity.BookingPolicy
 
 	err := r.db.QueryRow(ctx, findCourtByIDQuery, id).Scan(
 		&court.ID,
@@ -171,6 +207,11 @@ func (r *courtRepositoryImpl) FindByID(ctx context.Context, id string) (entity.C
 		&court.HourlyPrice,
 		&court.IsActive,
 		&court.Capacity,
+		&policy.MinDurationMinutes,
+		&policy.MaxDurationMinutes,
+		&policy.SlotGranularityMinutes,
+		&policy.LeadTimeMinutes,
+		&policy.MaxAdvanceDays,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -220,8 +261,15 @@ func (r *courtRepositoryImpl) FindByID(ctx context.Context, id string) (entity.C
 		courtPhotos = append(courtPhotos, courtPhoto)
 	}
 
+	pricingRules, err := r.ListPricingRules(ctx, id)
+	if err != nil {
+		return entity.Court{}, fmt.Errorf("CourtRepository.FindByID: %w", err)
+	}
+
 	court.Photos = courtPhotos
 	court.CourtSchedule = courtSchedule
+	court.BookingPolicy = &policy
+	court.PricingRules = pricingRules
 
 	return court, nil
 }
@@ -407,6 +455,32 @@ func (r *courtRepositoryImpl) ListAvailableBookingSlots(ctx context.Context, id
 	return bookings, nil
 }
 
+func (r *courtRepositoryImpl) ListBookingsInRange(ctx context.Context, id string, start time.Time, end time.Time) ([]entity.Booking, error) {
+	rows, err := r.db.Query(ctx, listCourtBookingsInRangeQuery, id, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("CourtRepository.ListBookingsInRange: %w", err)
+	}
+	defer rows.Close()
+
+	bookings := make([]entity.Booking, 0)
+	for rows.Next() {
+		var booking entity.Booking
+		err := rows.Scan(
+			&booking.StartTime,
+			&booking.EndTime,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("CourtRepository.ListBookingsInRange: %w", err)
+		}
+		bookings = append(bookings, booking)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("CourtRepository.ListBookingsInRange: %w", err)
+	}
+
+	return bookings, nil
+}
+
 func (r *courtRepositoryImpl) Update(ctx context.Context, id string, c entity.Court) error {
 	tx, err := r.db.Begin(ctx)
 	if err != nil {
@@ -419,9 +493,19 @@ func (r *courtRepositoryImpl) Update(ctx context.Context, id string, c entity.Co
 		}
 	}()
 
-	_, err = r.db.Exec(
-		ctx,
-		updateCourtQuery,
+	// A missing policy keeps the stored values.
+	policyArgs := make([]any, 5)
+	if p := c.BookingPolicy; p != nil {
+		policyArgs = []any{
+			p.MinDurationMinutes,
+			p.MaxDurationMinutes,
+			p.SlotGranularityMinutes,
+			p.LeadTimeMinutes,
+			p.MaxAdvanceDays,
+		}
+	}
+
+	updateArgs := append([]any{
 		c.Name,
 		c.Description,
 		c.SportType,
@@ -429,7 +513,8 @@ func (r *courtRepositoryImpl) Update(ctx context.Context, id string, c entity.Co
 		c.IsActive,
 		c.Capacity,
 		id,
-	)
+	}, policyArgs...)
+	_, err = r.db.Exec(ctx, updateCourtQuery, updateArgs...)
 	if err != nil {
 		return fmt.Errorf("CourtRepository.Update: %w", err)
 	}
@@ -478,3 +563,175 @@ func (r *courtRepositoryImpl) UpdateCourtStatus(ctx context.Context, id string,
 
 	return nil
 }
+
+func (r *courtRepositoryImpl) CreateBlackout(ctx context.Context, blackout entity.CourtBlackout) (string, error) {
+	var id string
+	err := r.db.QueryRow(
+		ctx,
+		createCourtBlackoutQuery,
+		blackout.CompanyId,
+		blackout.CourtId,
+		blackout.StartTime,
+		blackout.EndTime,
+		blackout.AllDay,
+		blackout.Reason,
+	).Scan(&id)
+	if err != nil {
+		return "", fmt.Errorf("CourtRepository.CreateBlackout: %w", err)
+	}
+
+	return id, nil
+}
+
+func (r *courtRepositoryImpl) UpdateBlackout(ctx context.Context, blackout entity.CourtBlackout) error {
+	tag, err := r.db.Exec(
+		ctx,
+		updateCourtBlackoutQuery,
+		blackout.StartTime,
+		blackout.EndTime,
+		blackout.AllDay,
+		blackout.Reason,
+		blackout.ID,
+		blackout.CompanyId,
+		blackout.CourtId,
+	)
+	if err != nil {
+		return fmt.Errorf("CourtRepository.UpdateBlackout: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return entity.ErrBlackoutNotFound
+	}
+
+	return nil
+}
+
+func (r *courtRepositoryImpl) DeleteBlackout(ctx context.Context, companyId string, courtId *string, id string) error {
+	tag, err := r.db.Exec(ctx, deleteCourtBlackoutQuery, id, companyId, courtId)
+	if err != nil {
+		return fmt.Errorf("CourtRepository.DeleteBlackout: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return entity.ErrBlackoutNotFound
+	}
+
+	return nil
+}
+
+func (r *courtRepositoryImpl) ListCourtBlackouts(ctx context.Context, courtId string) ([]entity.CourtBlackout, error) {
+	return r.listBlackouts(ctx, "CourtRepository.ListCourtBlackouts", listCourtBlackoutsQuery, courtId)
+}
+
+func (r *courtRepositoryImpl) ListCompanyBlackouts(ctx context.Context, companyId string) ([]entity.CourtBlackout, error) {
+	return r.listBlackouts(ctx, "CourtRepository.ListCompanyBlackouts", listCompanyBlackoutsQuery, companyId)
+}
+
+func (r *courtRepositoryImpl) ListBlackoutsInRange(ctx context.Context, courtId string, start time.Time, end time.Time) ([]entity.CourtBlackout, error) {
+	return r.listBlackouts(ctx, "CourtRepository.ListBlackoutsInRange", listCourtBlackoutsInRangeQuery, courtId, start, end)
+}
+
+func (r *courtRepositoryImpl) listBlackouts(ctx context.Context, op string, query string, args ...any) ([]entity.CourtBlackout, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	blackouts := make([]entity.CourtBlackout, 0)
+	for rows.Next() {
+		var blackout entity.CourtBlackout
+		err := rows.Scan(
+			&blackout.ID,
+			&blackout.CompanyId,
+			&blackout.CourtId,
+			&blackout.StartTime,
+			&blackout.EndTime,
+			&blackout.AllDay,
+			&blackout.Reason,
+			&blackout.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		blackouts = append(blackouts, blackout)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return blackouts, nil
+}
+
+func (r *courtRepositoryImpl) ListPricingRules(ctx context.Context, courtId string) ([]entity.PricingRule, error) {
+	rows, err := r.db.Query(ctx, listCourtPricingRulesQuery, courtId)
+	if err != nil {
+		return nil, fmt.Errorf("CourtRepository.ListPricingRules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := make([]entity.PricingRule, 0)
+	for rows.Next() {
+		var rule entity.PricingRule
+		err := rows.Scan(
+			&rule.ID,
+			&rule.CourtId,
+			&rule.Weekday,
+			&rule.StartTime,
+			&rule.EndTime,
+			&rule.HourlyPrice,
+			&rule.Priority,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("CourtRepository.ListPricingRules: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("CourtRepository.ListPricingRules: %w", err)
+	}
+
+	return rules, nil
+}
+
+// ReplacePricingRules swaps every pricing rule of the court for the given set
+// in a single transaction.
+func (r *courtRepositoryImpl) ReplacePricingRules(ctx context.Context, courtId string, rules []entity.PricingRule) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("CourtRepository.ReplacePricingRules: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+				log.Printf("CourtRepository.ReplacePricingRules: could not rollback transaction: %v\n", rollbackErr)
+			}
+		}
+	}()
+
+	if _, err = tx.Exec(ctx, deleteCourtPricingRulesQuery, courtId); err != nil {
+		return fmt.Errorf("CourtRepository.ReplacePricingRules: %w", err)
+	}
+
+	for _, rule := range rules {
+		_, err = tx.Exec(
+			ctx,
+			createCourtPricingRuleQuery,
+			courtId,
+			rule.Weekday,
+			rule.StartTime,
+			rule.EndTime,
+			rule.HourlyPrice,
+			rule.Priority,
+		)
+		if err != nil {
+			return fmt.Errorf("CourtRepository.ReplacePricingRules: %w", err)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("CourtRepository.ReplacePricingRules: commit tx: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dinizgab/booking-mvp/internal/database"
+	"github.com/dinizgab/booking-mvp/internal/entity"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	//go:embed sql/ledger/list_ledger_entries.sql
+	listLedgerEntriesQuery string
+	//go:embed sql/ledger/get_ledger_balance.sql
+	getLedgerBalanceQuery string
+)
+
+// LedgerRepository reads the company_ledger view, which derives the entries
+// from payments and withdrawals.
+type LedgerRepository interface {
+	ListEntries(ctx context.Context, companyId string, filter entity.StatementFilter) ([]entity.LedgerEntry, error)
+	GetBalance(ctx context.Context, companyId string, before time.Time) (string, int64, error)
+}
+
+type ledgerRepositoryImpl struct {
+	db database.Database
+}
+
+func NewLedgerRepository(db database.Database) LedgerRepository {
+	return &ledgerRepositoryImpl{
+		db: db,
+	}
+}
+
+// ListEntries returns the company entries of the period sorted by date.
+func (r *ledgerRepositoryImpl) ListEntries(ctx context.Context, companyId string, filter entity.StatementFilter) ([]entity.LedgerEntry, error) {
+	rows, err := r.db.Query(ctx, listLedgerEntriesQuery, companyId, filter.From, filter.To)
+	if err != nil {
+		return nil, fmt.Errorf("LedgerRepository.ListEntries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]entity.LedgerEntry, 0)
+	for rows.Next() {
+		var entry entity.LedgerEntry
+		err := rows.Scan(
+			&entry.ID,
+			&entry.OccurredAt,
+			&entry.Type,
+			&entry.ReferenceID,
+			&entry.BookingID,
+			&entry.Debit,
+			&entry.Credit,
+			&entry.Amount,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("LedgerRepository.ListEntries: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("LedgerRepository.ListEntries: %w", err)
+	}
+
+	return entries, nil
+}
+
+// GetBalance returns the company name and its ledger balance before the given
+// time.
+func (r *ledgerRepositoryImpl) GetBalance(ctx context.Context, companyId string, before time.Time) (string, int64, error) {
+	var name string
+	var balance int64
+	err := r.db.QueryRow(ctx, getLedgerBalanceQuery, companyId, before).Scan(&name, &balance)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", 0, fmt.Errorf("LedgerRepository.GetBalance: %w", entity.ErrCompanyNotFound)
+		}
+		return "", 0, fmt.Errorf("LedgerRepository.GetBalance: %w", err)
+	}
+
+	return name, balance, nil
+}
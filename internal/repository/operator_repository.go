@@ -0,0 +1,297 @@
+package repository
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dinizgab/booking-mvp/internal/database"
+	"github.com/dinizgab/booking-mvp/internal/entity"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	//go:embed sql/operator/create_operator.sql
+	createOperatorQuery string
+	//go:embed sql/operator/find_operator_by_email.sql
+	findOperatorByEmailQuery string
+	//go:embed sql/operator/list_companies.sql
+	listCompaniesQuery string
+	//go:embed sql/operator/find_company_overview.sql
+	findCompanyOverviewQuery string
+	//go:embed sql/operator/suspend_company.sql
+	suspendCompanyQuery string
+	//go:embed sql/operator/reactivate_company.sql
+	reactivateCompanyQuery string
+	//go:embed sql/operator/get_platform_revenue.sql
+	getPlatformRevenueQuery string
+	//go:embed sql/operator/create_audit_log_entry.sql
+	createAuditLogEntryQuery string
+	//go:embed sql/operator/list_audit_log.sql
+	listAuditLogQuery string
+)
+
+// OperatorRepository holds the platform operators and the queries they run
+// across every company.
+type OperatorRepository interface {
+	Create(ctx context.Context, operator entity.Operator) (entity.Operator, error)
+	FindByEmail(ctx context.Context, email string) (entity.Operator, error)
+	ListCompanies(ctx context.Context, filter entity.CompanyFilter) (entity.CompanyPage, error)
+	FindCompany(ctx context.Context, id string) (entity.CompanyOverview, error)
+	SuspendCompany(ctx context.Context, id string, reason string) error
+	ReactivateCompany(ctx context.Context, id string) error
+	GetRevenue(ctx context.Context, from, to time.Time) ([]entity.ProviderRevenue, error)
+	CreateAuditLogEntry(ctx context.Context, entry entity.AuditLogEntry) error
+	ListAuditLog(ctx context.Context, filter entity.AuditLogFilter) ([]entity.AuditLogEntry, error)
+}
+
+type operatorRepositoryImpl struct {
+	db database.Database
+}
+
+func NewOperatorRepository(db database.Database) OperatorRepository {
+	return &operatorRepositoryImpl{
+		db: db,
+	}
+}
+
+func (r *operatorRepositoryImpl) Create(ctx context.Context, operator entity.Operator) (entity.Operator, error) {
+	err := r.db.QueryRow(ctx, createOperatorQuery, operator.Name, operator.Email, operator.Password).Scan(
+		&operator.ID,
+		&operator.CreatedAt,
+	)
+	if err != nil {
+		return entity.Operator{}, fmt.Errorf("OperatorRepository.Create: %w", err)
+	}
+
+	return operator, nil
+}
+
+func (r *operatorRepositoryImpl) FindByEmail(ctx context.Context, email string) (entity.Operator, error) {
+	var operator entity.Operator
+	err := r.db.QueryRow(ctx, findOperatorByEmailQuery, email).Scan(
+		&operator.ID,
+		&operator.Name,
+		&operator.Email,
+		&operator.Password,
+		&operator.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.Operator{}, entity.ErrInvalidCredentials
+		}
+		return entity.Operator{}, fmt.Errorf("OperatorRepository.FindByEmail: %w", err)
+	}
+
+	return operator, nil
+}
+
+func (r *operatorRepositoryImpl) ListCompanies(ctx context.Context, filter entity.CompanyFilter) (entity.CompanyPage, error) {
+	rows, err := r.db.Query(ctx, listCompaniesQuery, filter.Query, filter.Status, filter.Limit, filter.Offset)
+	if err != nil {
+		return entity.CompanyPage{}, fmt.Errorf("OperatorRepository.ListCompanies: %w", err)
+	}
+	defer rows.Close()
+
+	page := entity.CompanyPage{Companies: make([]entity.CompanyOverview, 0)}
+	for rows.Next() {
+		var company entity.CompanyOverview
+		var planID *string
+		err := rows.Scan(
+			&company.ID,
+			&company.Name,
+			&company.Slug,
+			&company.Email,
+			&company.Phone,
+			&company.CNPJ,
+			&company.PaymentProvider,
+			&planID,
+			&company.SuspendedAt,
+			&company.SuspensionReason,
+			&company.Courts,
+			&company.Bookings,
+			&page.Total,
+		)
+		if err != nil {
+			return entity.CompanyPage{}, fmt.Errorf("OperatorRepository.ListCompanies: %w", err)
+		}
+
+		page.Companies = append(page.Companies, withCompanyStatus(company, planID))
+	}
+	if err := rows.Err(); err != nil {
+		return entity.CompanyPage{}, fmt.Errorf("OperatorRepository.ListCompanies: %w", err)
+	}
+
+	return page, nil
+}
+
+func (r *operatorRepositoryImpl) FindCompany(ctx context.Context, id string) (entity.CompanyOverview, error) {
+	var company entity.CompanyOverview
+	var planID *string
+	err := r.db.QueryRow(ctx, findCompanyOverviewQuery, id).Scan(
+		&company.ID,
+		&company.Name,
+		&company.Slug,
+		&company.Email,
+		&company.Phone,
+		&company.CNPJ,
+		&company.PaymentProvider,
+		&planID,
+		&company.SuspendedAt,
+		&company.SuspensionReason,
+		&company.Courts,
+		&company.Bookings,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.CompanyOverview{}, entity.ErrCompanyNotFound
+		}
+		return entity.CompanyOverview{}, fmt.Errorf("OperatorRepository.FindCompany: %w", err)
+	}
+
+	return withCompanyStatus(company, planID), nil
+}
+
+// SuspendCompany suspends the company, keeping the original suspension time
+// when it is already suspended.
+func (r *operatorRepositoryImpl) SuspendCompany(ctx context.Context, id string, reason string) error {
+	tag, err := r.db.Exec(ctx, suspendCompanyQuery, id, reason)
+	if err != nil {
+		return fmt.Errorf("OperatorRepository.SuspendCompany: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return entity.ErrCompanyNotFound
+	}
+
+	return nil
+}
+
+func (r *operatorRepositoryImpl) ReactivateCompany(ctx context.Context, id string) error {
+	tag, err := r.db.Exec(ctx, reactivateCompanyQuery, id)
+	if err != nil {
+		return fmt.Errorf("OperatorRepository.ReactivateCompany: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return entity.ErrCompanyNotFound
+	}
+
+	return nil
+}
+
+// GetRevenue sums the payments paid and refunded in [from, to) per provider.
+func (r *operatorRepositoryImpl) GetRevenue(ctx context.Context, from, to time.Time) ([]entity.ProviderRevenue, error) {
+	rows, err := r.db.Query(ctx, getPlatformRevenueQuery, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("OperatorRepository.GetRevenue: %w", err)
+	}
+	defer rows.Close()
+
+	providers := make([]entity.ProviderRevenue, 0)
+	for rows.Next() {
+		var revenue entity.ProviderRevenue
+		err := rows.Scan(
+			&revenue.Provider,
+			&revenue.Payments,
+			&revenue.Volume,
+			&revenue.Commission,
+			&revenue.GatewayFees,
+			&revenue.Refunded,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("OperatorRepository.GetRevenue: %w", err)
+		}
+		providers = append(providers, revenue)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("OperatorRepository.GetRevenue: %w", err)
+	}
+
+	return providers, nil
+}
+
+func (r *operatorRepositoryImpl) CreateAuditLogEntry(ctx context.Context, entry entity.AuditLogEntry) error {
+	var details []byte
+	if len(entry.Details) > 0 {
+		details = entry.Details
+	}
+
+	_, err := r.db.Exec(
+		ctx,
+		createAuditLogEntryQuery,
+		entry.OperatorID,
+		nullableString(entry.CompanyID),
+		entry.Action,
+		nullableString(entry.TargetID),
+		entry.StatusCode,
+		details,
+	)
+	if err != nil {
+		return fmt.Errorf("OperatorRepository.CreateAuditLogEntry: %w", err)
+	}
+
+	return nil
+}
+
+func (r *operatorRepositoryImpl) ListAuditLog(ctx context.Context, filter entity.AuditLogFilter) ([]entity.AuditLogEntry, error) {
+	rows, err := r.db.Query(
+		ctx,
+		listAuditLogQuery,
+		nullableString(filter.OperatorID),
+		nullableString(filter.CompanyID),
+		filter.Limit,
+		filter.Offset,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("OperatorRepository.ListAuditLog: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]entity.AuditLogEntry, 0)
+	for rows.Next() {
+		var entry entity.AuditLogEntry
+		var companyID, targetID *string
+		var details []byte
+		err := rows.Scan(
+			&entry.ID,
+			&entry.OperatorID,
+			&companyID,
+			&entry.Action,
+			&targetID,
+			&entry.StatusCode,
+			&details,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("OperatorRepository.ListAuditLog: %w", err)
+		}
+
+		if companyID != nil {
+			entry.CompanyID = *companyID
+		}
+		if targetID != nil {
+			entry.TargetID = *targetID
+		}
+		entry.Details = details
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("OperatorRepository.ListAuditLog: %w", err)
+	}
+
+	return entries, nil
+}
+
+func withCompanyStatus(company entity.CompanyOverview, planID *string) entity.CompanyOverview {
+	if planID != nil {
+		company.CommissionPlanID = *planID
+	}
+
+	company.Status = entity.CompanyStatusActive
+	if company.SuspendedAt != nil {
+		company.Status = entity.CompanyStatusSuspended
+	}
+
+	return company
+}
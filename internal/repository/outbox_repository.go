@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"time"
+
+	"github.com/dinizgab/booking-mvp/internal/database"
+	"github.com/dinizgab/booking-mvp/internal/entity"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	//go:embed sql/outbox/create_outbox_message.sql
+	createOutboxMessageQuery string
+	//go:embed sql/outbox/list_due_outbox_messages.sql
+	listDueOutboxMessagesQuery string
+	//go:embed sql/outbox/mark_outbox_message_sent.sql
+	markOutboxMessageSentQuery string
+	//go:embed sql/outbox/mark_outbox_message_failed.sql
+	markOutboxMessageFailedQuery string
+	//go:embed sql/outbox/list_booking_outbox_messages.sql
+	listBookingOutboxMessagesQuery string
+)
+
+type OutboxRepository interface {
+	ListDue(ctx context.Context, limit int) ([]entity.OutboxMessage, error)
+	MarkSent(ctx context.Context, id string) error
+	MarkFailed(ctx context.Context, id string, status entity.OutboxStatus, lastError string, nextAttemptAt time.Time) error
+	ListByBooking(ctx context.Context, companyId string, bookingId string) ([]entity.OutboxMessage, error)
+}
+
+type outboxRepositoryImpl struct {
+	db database.Database
+}
+
+func NewOutboxRepository(db database.Database) OutboxRepository {
+	return &outboxRepositoryImpl{
+		db: db,
+	}
+}
+
+func (r *outboxRepositoryImpl) ListDue(ctx context.Context, limit int) ([]entity.OutboxMessage, error) {
+	return r.listMessages(ctx, "OutboxRepository.ListDue", listDueOutboxMessagesQuery, limit)
+}
+
+func (r *outboxRepositoryImpl) MarkSent(ctx context.Context, id string) error {
+	_, err := r.db.Exec(ctx, markOutboxMessageSentQuery, id)
+	if err != nil {
+		return fmt.Errorf("OutboxRepository.MarkSent: %w", err)
+	}
+
+	return nil
+}
+
+func (r *outboxRepositoryImpl) MarkFailed(ctx context.Context, id string, status entity.OutboxStatus, lastError string, nextAttemptAt time.Time) error {
+	_, err := r.db.Exec(ctx, markOutboxMessageFailedQuery, id, status, lastError, nextAttemptAt)
+	if err != nil {
+		return fmt.Errorf("OutboxRepository.MarkFailed: %w", err)
+	}
+
+	return nil
+}
+
+func (r *outboxRepositoryImpl) ListByBooking(ctx context.Context, companyId string, bookingId string) ([]entity.OutboxMessage, error) {
+	return r.listMessages(ctx, "OutboxRepository.ListByBooking", listBookingOutboxMessagesQuery, bookingId, companyId)
+}
+
+func (r *outboxRepositoryImpl) listMessages(ctx context.Context, op string, query string, args ...any) ([]entity.OutboxMessage, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	messages := make([]entity.OutboxMessage, 0)
+	for rows.Next() {
+		var message entity.OutboxMessage
+		var payload []byte
+		err := rows.Scan(
+			&message.ID,
+			&message.BookingId,
+			&message.Template,
+			&message.Subject,
+			&message.Recipient,
+			&payload,
+			&message.Status,
+			&message.Attempts,
+			&message.LastError,
+			&message.NextAttemptAt,
+			&message.SentAt,
+			&message.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		message.Payload = payload
+		messages = append(messages, message)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return messages, nil
+}
+
+// insertOutboxMessages writes the messages inside the caller transaction so
+// they are only delivered if the state change commits.
+func insertOutboxMessages(ctx context.Context, tx pgx.Tx, messages []entity.OutboxMessage) error {
+	for _, message := range messages {
+		_, err := tx.Exec(
+			ctx,
+			createOutboxMessageQuery,
+			message.BookingId,
+			message.Template,
+			message.Subject,
+			message.Recipient,
+			[]byte(message.Payload),
+		)
+		if err != nil {
+			return fmt.Errorf("insert outbox message: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -3,20 +3,25 @@ package repository
 import (
 	"context"
 	_ "embed"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/dinizgab/booking-mvp/internal/database"
 	"github.com/dinizgab/booking-mvp/internal/entity"
-	"github.com/dinizgab/booking-mvp/internal/gateway/openpix"
 	"github.com/jackc/pgx/v5"
 )
 
 var (
 	//go:embed sql/payment/create_pix_subaccount.sql
 	createPixSubaccountQuery string
-	//go:embed sql/payment/get_subaccount_pix_key_by_company_id.sql
-	getSubaccountPixKeyByCompanyIDQuery string
+	//go:embed sql/payment/get_payment_account.sql
+	getPaymentAccountQuery string
+	//go:embed sql/payment/save_payment_account.sql
+	savePaymentAccountQuery string
+	//go:embed sql/payment/get_company_payment_provider.sql
+	getCompanyPaymentProviderQuery string
 	//go:embed sql/payment/create_charge.sql
 	createChargeQuery string
     //go:embed sql/payment/confirm_booking_payment.sql
@@ -27,25 +32,85 @@ var (
     createWithdrawRequestQuery string
 	//go:embed sql/payment/expire_payment.sql
 	expirePaymentQuery string
+	//go:embed sql/payment/expire_stale_payments.sql
+	expireStalePaymentsQuery string
     //go:embed sql/payment/get_booking_charge_information_by_booking_id.sql
     getBookingChargeInformationByBookingId string
     //go:embed sql/payment/get_payment_by_booking_id.sql
     getPaymentByBoookingIdQuery string
     //go:embed sql/payment/save_refund_request.sql
     saveRefundRequestQuery string
+	//go:embed sql/payment/finish_refund.sql
+	finishRefundQuery string
+	//go:embed sql/payment/get_payment_by_refund_correlation_id.sql
+	getPaymentByRefundCorrelationIDQuery string
+	//go:embed sql/payment/list_pending_refunds.sql
+	listPendingRefundsQuery string
+	//go:embed sql/payment/list_payments_for_reconciliation.sql
+	listPaymentsForReconciliationQuery string
+	//go:embed sql/payment/get_payment_by_correlation_id.sql
+	getPaymentByCorrelationIDQuery string
+	//go:embed sql/payment/record_late_payment.sql
+	recordLatePaymentQuery string
+	//go:embed sql/payment/mark_payment_mismatch.sql
+	markPaymentMismatchQuery string
+	//go:embed sql/payment/list_payment_mismatches.sql
+	listPaymentMismatchesQuery string
+	//go:embed sql/payment/accept_payment_mismatch.sql
+	acceptPaymentMismatchQuery string
+	//go:embed sql/payment/list_withdrawals.sql
+	listWithdrawalsQuery string
+	//go:embed sql/payment/list_pending_withdrawals.sql
+	listPendingWithdrawalsQuery string
+	//go:embed sql/payment/update_withdrawal_status.sql
+	updateWithdrawalStatusQuery string
+	//go:embed sql/payment/get_payout_schedule.sql
+	getPayoutScheduleQuery string
+	//go:embed sql/payment/save_payout_schedule.sql
+	savePayoutScheduleQuery string
+	//go:embed sql/payment/claim_due_payout_schedules.sql
+	claimDuePayoutSchedulesQuery string
+	//go:embed sql/payment/release_payout_schedule.sql
+	releasePayoutScheduleQuery string
 )
 
 type PaymentRepository interface {
 	CreateSubaccount(ctx context.Context, subaccount entity.Subaccount) error
-	GetSubaccountPixKeyByCompanyID(ctx context.Context, companyId string) (string, error)
-	CreateCharge(ctx context.Context, companyId string, charge openpix.Charge) error
-    ConfirmPayment(ctx context.Context, charge openpix.Charge) error
+	GetPaymentAccount(ctx context.Context, companyId string, provider entity.PaymentProvider) (string, error)
+	SavePaymentAccount(ctx context.Context, companyId string, provider entity.PaymentProvider, accountId string) error
+	GetCompanyPaymentProvider(ctx context.Context, companyId string) (entity.PaymentProvider, error)
+	CreateCharge(ctx context.Context, companyId string, charge entity.Charge, commission entity.Commission) error
+    ConfirmPayment(ctx context.Context, charge entity.Charge, cancelTokenHash string, messages ...entity.OutboxMessage) (bool, error)
     GetBookingPaymentStatusByID(ctx context.Context, id string) (string, error)
-    CreateWithdrawRequest(ctx context.Context, companyId string, withdraw openpix.Withdraw) error
-	ExpirePayment(ctx context.Context, charge openpix.Charge) error
+    CreateWithdrawRequest(ctx context.Context, companyId string, withdraw entity.Withdrawal) (entity.Withdrawal, error)
+	ExpirePayment(ctx context.Context, charge entity.Charge) error
+	ExpireStalePayments(ctx context.Context) (int64, error)
     GetBookingChargeInformation(ctx context.Context, id string) (entity.Payment, error)
     GetPaymentByBookingID(ctx context.Context, id string) (entity.Payment, error)
-    SaveRefundRequest(ctx context.Context, bookingId string, refund openpix.Refund) error
+    SaveRefundRequest(ctx context.Context, bookingId string, refund entity.Refund, messages ...entity.OutboxMessage) error
+	GetPaymentByRefundCorrelationID(ctx context.Context, correlationId string) (entity.Payment, error)
+	ListPendingRefunds(ctx context.Context, olderThan time.Duration) ([]entity.Payment, error)
+	FinishRefund(ctx context.Context, refund entity.Refund, messages ...entity.OutboxMessage) (bool, error)
+	ListPaymentsForReconciliation(ctx context.Context, provider entity.PaymentProvider, from time.Time, to time.Time) ([]entity.Payment, error)
+	GetPaymentByCorrelationID(ctx context.Context, correlationId string) (entity.Payment, error)
+	MarkPaymentMismatch(ctx context.Context, charge entity.Charge, messages ...entity.OutboxMessage) (bool, error)
+	// RefundLatePayment records the charge paid after its payment expired or
+	// its booking was cancelled, and the refund of it, in one transaction.
+	RefundLatePayment(ctx context.Context, bookingId string, charge entity.Charge, refund entity.Refund, messages ...entity.OutboxMessage) error
+	// SaveSeriesRefund is SaveRefundRequest for the upfront payment of a
+	// series. The booking holding the payment is kept, since the payment
+	// also paid for occurrences that go on.
+	SaveSeriesRefund(ctx context.Context, bookingId string, refund entity.Refund, messages ...entity.OutboxMessage) error
+	ListPaymentMismatches(ctx context.Context, companyId string) ([]entity.PaymentMismatch, error)
+	GetPaymentMismatch(ctx context.Context, companyId string, paymentId string) (entity.PaymentMismatch, error)
+	AcceptPaymentMismatch(ctx context.Context, companyId string, mismatch entity.PaymentMismatch, cancelTokenHash string, messages ...entity.OutboxMessage) (bool, error)
+	ListWithdrawals(ctx context.Context, companyId string) ([]entity.Withdrawal, error)
+	ListPendingWithdrawals(ctx context.Context, olderThan time.Duration) ([]entity.Withdrawal, error)
+	UpdateWithdrawalStatus(ctx context.Context, withdrawal entity.Withdrawal) (bool, error)
+	GetPayoutSchedule(ctx context.Context, companyId string) (entity.PayoutSchedule, error)
+	SavePayoutSchedule(ctx context.Context, schedule entity.PayoutSchedule) error
+	ClaimDuePayoutSchedules(ctx context.Context, weekday time.Weekday, dayStart time.Time) ([]entity.PayoutSchedule, error)
+	ReleasePayoutSchedule(ctx context.Context, schedule entity.PayoutSchedule) error
 }
 
 type paymentRepositoryImpl struct {
@@ -67,17 +132,39 @@ func (r *paymentRepositoryImpl) CreateSubaccount(ctx context.Context, subaccount
 	return nil
 }
 
-func (r *paymentRepositoryImpl) GetSubaccountPixKeyByCompanyID(ctx context.Context, companyId string) (string, error) {
-	var pixKey string
-	err := r.db.QueryRow(ctx, getSubaccountPixKeyByCompanyIDQuery, companyId).Scan(&pixKey)
+func (r *paymentRepositoryImpl) GetPaymentAccount(ctx context.Context, companyId string, provider entity.PaymentProvider) (string, error) {
+	var accountId string
+	err := r.db.QueryRow(ctx, getPaymentAccountQuery, companyId, provider).Scan(&accountId)
 	if err != nil {
-        return "", fmt.Errorf("paymentRepositoryImpl.GetSubaccountPixKeyByCompanyID - failed to get subaccount pix key: %w", err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", entity.ErrPaymentAccountNotFound
+		}
+		return "", fmt.Errorf("paymentRepositoryImpl.GetPaymentAccount - failed to get payment account: %w", err)
 	}
 
-	return pixKey, nil
+	return accountId, nil
 }
 
-func (r *paymentRepositoryImpl) CreateCharge(ctx context.Context, companyId string, charge openpix.Charge) error {
+func (r *paymentRepositoryImpl) SavePaymentAccount(ctx context.Context, companyId string, provider entity.PaymentProvider, accountId string) error {
+	_, err := r.db.Exec(ctx, savePaymentAccountQuery, companyId, provider, accountId)
+	if err != nil {
+		return fmt.Errorf("paymentRepositoryImpl.SavePaymentAccount - failed to save payment account: %w", err)
+	}
+
+	return nil
+}
+
+func (r *paymentRepositoryImpl) GetCompanyPaymentProvider(ctx context.Context, companyId string) (entity.PaymentProvider, error) {
+	var provider entity.PaymentProvider
+	err := r.db.QueryRow(ctx, getCompanyPaymentProviderQuery, companyId).Scan(&provider)
+	if err != nil {
+		return "", fmt.Errorf("paymentRepositoryImpl.GetCompanyPaymentProvider - failed to get payment provider: %w", err)
+	}
+
+	return provider, nil
+}
+
+func (r *paymentRepositoryImpl) CreateCharge(ctx context.Context, companyId string, charge entity.Charge, commission entity.Commission) error {
 	bookingId := strings.Replace(charge.CorrelationID, "booking-", "", 1)
 	_, err := r.db.Exec(
 		ctx,
@@ -85,13 +172,15 @@ func (r *paymentRepositoryImpl) CreateCharge(ctx context.Context, companyId stri
 		companyId,
 		bookingId,
 		charge.CorrelationID,
-		charge.PaymentLinkID,
+		charge.ChargeID,
 		charge.PaymentLinkURL,
-        charge.QrCodeImage,
-		charge.Brcode,
+		charge.QrCodeImage,
+		charge.BrCode,
 		charge.Value,
-        charge.GasPrice,
-		charge.ExpiresDate,
+		commission.Fee,
+		nullableTime(charge.ExpiresAt),
+		charge.Provider,
+		commission.PaidBy,
 	)
 	if err != nil {
         return fmt.Errorf("paymentRepositoryImpl.CreateCharge - failed to create charge: %w", err)
@@ -100,18 +189,47 @@ func (r *paymentRepositoryImpl) CreateCharge(ctx context.Context, companyId stri
 	return nil
 }
 
-func (r *paymentRepositoryImpl) ConfirmPayment(ctx context.Context, charge openpix.Charge) error {
-    _, err := r.db.Exec(
-        ctx,
-        confirmPaymentQuery,
-        charge.CorrelationID,
-        charge.PaidAt,
-    )
-    if err != nil {
-        return fmt.Errorf("paymentRepositoryImpl.ConfirmPayment - failed to confirm payment: %w", err)
-    }
+// ConfirmPayment marks the charge paid, stores the booking cancel token hash
+// and enqueues the given messages in a single transaction. It reports false,
+// changing nothing, when the payment was not pending anymore.
+func (r *paymentRepositoryImpl) ConfirmPayment(ctx context.Context, charge entity.Charge, cancelTokenHash string, messages ...entity.OutboxMessage) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("paymentRepositoryImpl.ConfirmPayment - failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	tag, err := tx.Exec(
+		ctx,
+		confirmPaymentQuery,
+		charge.CorrelationID,
+		nullableTime(charge.PaidAt),
+		charge.GatewayFee,
+	)
+	if err != nil {
+		return false, fmt.Errorf("paymentRepositoryImpl.ConfirmPayment - failed to confirm payment: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	bookingId := strings.TrimPrefix(charge.CorrelationID, "booking-")
+	if _, err := tx.Exec(ctx, setCancelTokenHashQuery, cancelTokenHash, bookingId); err != nil {
+		return false, fmt.Errorf("paymentRepositoryImpl.ConfirmPayment - failed to set cancel token: %w", err)
+	}
+
+	if err := insertOutboxMessages(ctx, tx, messages); err != nil {
+		return false, fmt.Errorf("paymentRepositoryImpl.ConfirmPayment - %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("paymentRepositoryImpl.ConfirmPayment - failed to commit transaction: %w", err)
+	}
 
-    return nil
+	return true, nil
 }
 
 func (r *paymentRepositoryImpl) GetBookingPaymentStatusByID(ctx context.Context, id string) (string, error) {
@@ -129,9 +247,15 @@ func (r *paymentRepositoryImpl) GetBookingPaymentStatusByID(ctx context.Context,
 
 func (r *paymentRepositoryImpl) GetBookingChargeInformation(ctx context.Context, id string) (entity.Payment, error) {
     var payment entity.Payment
+    var refundRequestedAt, refundedAt *time.Time
     err := r.db.QueryRow(ctx, getBookingChargeInformationByBookingId, id).Scan(
         &payment.BrCode,
         &payment.QrCodeImage,
+        &payment.Status,
+        &refundRequestedAt,
+        &refundedAt,
+        &payment.RefundedValue,
+        &payment.RefundFailureReason,
     )
     if err != nil {
         if err == pgx.ErrNoRows {
@@ -139,26 +263,38 @@ func (r *paymentRepositoryImpl) GetBookingChargeInformation(ctx context.Context,
         }
         return entity.Payment{}, fmt.Errorf("paymentRepositoryImpl.GetPaymentBookingPaymentInformation - failed to get booking payment information: %w", err)
     }
+    if refundRequestedAt != nil {
+        payment.RefundRequestedAt = *refundRequestedAt
+    }
+    if refundedAt != nil {
+        payment.RefundedAt = *refundedAt
+    }
 
     return payment, nil
 }
 
-func (r *paymentRepositoryImpl) CreateWithdrawRequest(ctx context.Context, companyId string, withdraw openpix.Withdraw) error {
-    _, err := r.db.Exec(
+func (r *paymentRepositoryImpl) CreateWithdrawRequest(ctx context.Context, companyId string, withdraw entity.Withdrawal) (entity.Withdrawal, error) {
+    err := r.db.QueryRow(
         ctx,
         createWithdrawRequestQuery,
         companyId,
-        withdraw.CorrelationId,
+        withdraw.CorrelationID,
         withdraw.Value,
-    )
+        withdraw.Provider,
+        withdraw.Status,
+        nullableString(withdraw.FailureReason),
+        withdraw.Trigger,
+        nullableTime(withdraw.CompletedAt),
+    ).Scan(&withdraw.ID, &withdraw.CreatedAt)
     if err != nil {
-        return fmt.Errorf("paymentRepositoryImpl.CreateWithdrawRequest - failed to create withdraw request: %w", err)
+        return entity.Withdrawal{}, fmt.Errorf("paymentRepositoryImpl.CreateWithdrawRequest - failed to create withdraw request: %w", err)
     }
+    withdraw.CompanyID = companyId
 
-    return nil
+    return withdraw, nil
 }
 
-func (r *paymentRepositoryImpl) ExpirePayment(ctx context.Context, charge openpix.Charge) error {
+func (r *paymentRepositoryImpl) ExpirePayment(ctx context.Context, charge entity.Charge) error {
 	_, err := r.db.Exec(
 		ctx,
 		expirePaymentQuery,
@@ -171,14 +307,34 @@ func (r *paymentRepositoryImpl) ExpirePayment(ctx context.Context, charge openpi
 	return nil
 }
 
+func (r *paymentRepositoryImpl) ExpireStalePayments(ctx context.Context) (int64, error) {
+	var expired int64
+	err := r.db.QueryRow(ctx, expireStalePaymentsQuery).Scan(&expired)
+	if err != nil {
+		return 0, fmt.Errorf("paymentRepositoryImpl.ExpireStalePayments - failed to expire stale payments: %w", err)
+	}
+
+	return expired, nil
+}
+
 func (r *paymentRepositoryImpl) GetPaymentByBookingID(ctx context.Context, id string) (entity.Payment, error) {
     var payment entity.Payment
+    var paidAt *time.Time
     err := r.db.QueryRow(ctx, getPaymentByBoookingIdQuery, id).Scan(
         &payment.ID,
         &payment.CorrelationID,
         &payment.BookingID,
-        &payment.PaidAt,
+        &paidAt,
         &payment.ValueTotal,
+        &payment.ValueCommission,
+        &payment.ValueCompany,
+        &payment.Provider,
+        &payment.Status,
+        &payment.CommissionPaidBy,
+        &payment.ChargeID,
+        &payment.ValueReceived,
+        &payment.RefundedValue,
+        &payment.RefundedPlatformValue,
     )
     if err != nil {
         if err == pgx.ErrNoRows {
@@ -186,22 +342,555 @@ func (r *paymentRepositoryImpl) GetPaymentByBookingID(ctx context.Context, id st
         }
         return entity.Payment{}, fmt.Errorf("paymentRepositoryImpl.GetPaymentByBookingID - failed to get payment by booking ID: %w", err)
     }
+    if paidAt != nil {
+        payment.PaidAt = *paidAt
+    }
 
     return payment, nil
 }
 
-func (r *paymentRepositoryImpl) SaveRefundRequest(ctx context.Context, bookingId string, refund openpix.Refund) error {
-    _, err := r.db.Exec(
-        ctx,
-        saveRefundRequestQuery,
-        bookingId,
-        refund.RefundedAt,
-        refund.EndToEndID,
-    )
-    if err != nil {
-        return fmt.Errorf("paymentRepositoryImpl.SaveRefundRequest - failed to save refund request: %w", err)
-    }
+func (r *paymentRepositoryImpl) SaveRefundRequest(ctx context.Context, bookingId string, refund entity.Refund, messages ...entity.OutboxMessage) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("paymentRepositoryImpl.SaveRefundRequest - failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	if err := saveRefund(ctx, tx, bookingId, refund, true); err != nil {
+		return fmt.Errorf("paymentRepositoryImpl.SaveRefundRequest - failed to save refund request: %w", err)
+	}
+
+	if err := insertOutboxMessages(ctx, tx, messages); err != nil {
+		return fmt.Errorf("paymentRepositoryImpl.SaveRefundRequest - %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("paymentRepositoryImpl.SaveRefundRequest - failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *paymentRepositoryImpl) RefundLatePayment(ctx context.Context, bookingId string, charge entity.Charge, refund entity.Refund, messages ...entity.OutboxMessage) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("paymentRepositoryImpl.RefundLatePayment - failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	_, err = tx.Exec(ctx, recordLatePaymentQuery, charge.CorrelationID, nullableTime(charge.PaidAt), charge.ValueReceived, charge.GatewayFee)
+	if err != nil {
+		return fmt.Errorf("paymentRepositoryImpl.RefundLatePayment - failed to record payment: %w", err)
+	}
 
-    return nil
+	if err := saveRefund(ctx, tx, bookingId, refund, true); err != nil {
+		return fmt.Errorf("paymentRepositoryImpl.RefundLatePayment - failed to save refund request: %w", err)
+	}
+
+	if err := insertOutboxMessages(ctx, tx, messages); err != nil {
+		return fmt.Errorf("paymentRepositoryImpl.RefundLatePayment - %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("paymentRepositoryImpl.RefundLatePayment - failed to commit transaction: %w", err)
+	}
+
+	return nil
 }
 
+func (r *paymentRepositoryImpl) SaveSeriesRefund(ctx context.Context, bookingId string, refund entity.Refund, messages ...entity.OutboxMessage) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("paymentRepositoryImpl.SaveSeriesRefund - failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	if err := saveRefund(ctx, tx, bookingId, refund, false); err != nil {
+		return fmt.Errorf("paymentRepositoryImpl.SaveSeriesRefund - failed to save refund request: %w", err)
+	}
+
+	if err := insertOutboxMessages(ctx, tx, messages); err != nil {
+		return fmt.Errorf("paymentRepositoryImpl.SaveSeriesRefund - %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("paymentRepositoryImpl.SaveSeriesRefund - failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// saveRefund stores the refund on the payment of the booking, cancelling the
+// booking when cancelBooking is set.
+func saveRefund(ctx context.Context, tx pgx.Tx, bookingId string, refund entity.Refund, cancelBooking bool) error {
+	_, err := tx.Exec(
+		ctx,
+		saveRefundRequestQuery,
+		bookingId,
+		nullableTime(refund.RefundedAt),
+		refund.EndToEndID,
+		refund.Value,
+		refund.CorrelationID,
+		refund.PaymentStatus(),
+		refund.FailureReason,
+		cancelBooking,
+		refund.PlatformValue,
+	)
+
+	return err
+}
+
+func (r *paymentRepositoryImpl) GetPaymentByRefundCorrelationID(ctx context.Context, correlationId string) (entity.Payment, error) {
+	payment, err := scanRefundPayment(r.db.QueryRow(ctx, getPaymentByRefundCorrelationIDQuery, correlationId))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.Payment{}, fmt.Errorf("paymentRepositoryImpl.GetPaymentByRefundCorrelationID - refund %s not found: %w", correlationId, err)
+		}
+		return entity.Payment{}, fmt.Errorf("paymentRepositoryImpl.GetPaymentByRefundCorrelationID - failed to get payment: %w", err)
+	}
+
+	return payment, nil
+}
+
+// ListPendingRefunds returns payments waiting for a refund confirmation for
+// longer than olderThan.
+func (r *paymentRepositoryImpl) ListPendingRefunds(ctx context.Context, olderThan time.Duration) ([]entity.Payment, error) {
+	rows, err := r.db.Query(ctx, listPendingRefundsQuery, olderThan.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("paymentRepositoryImpl.ListPendingRefunds - failed to list refunds: %w", err)
+	}
+	defer rows.Close()
+
+	payments := make([]entity.Payment, 0)
+	for rows.Next() {
+		payment, err := scanRefundPayment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("paymentRepositoryImpl.ListPendingRefunds - failed to scan refund: %w", err)
+		}
+		payments = append(payments, payment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("paymentRepositoryImpl.ListPendingRefunds - %w", err)
+	}
+
+	return payments, nil
+}
+
+// FinishRefund moves a refunding payment to refunded or refund_failed and
+// enqueues the guest notifications. It reports false when the refund was
+// already finished.
+func (r *paymentRepositoryImpl) FinishRefund(ctx context.Context, refund entity.Refund, messages ...entity.OutboxMessage) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("paymentRepositoryImpl.FinishRefund - failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	tag, err := tx.Exec(
+		ctx,
+		finishRefundQuery,
+		refund.CorrelationID,
+		refund.PaymentStatus(),
+		nullableTime(refund.RefundedAt),
+		refund.FailureReason,
+	)
+	if err != nil {
+		return false, fmt.Errorf("paymentRepositoryImpl.FinishRefund - failed to update refund: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	if err := insertOutboxMessages(ctx, tx, messages); err != nil {
+		return false, fmt.Errorf("paymentRepositoryImpl.FinishRefund - %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("paymentRepositoryImpl.FinishRefund - failed to commit transaction: %w", err)
+	}
+
+	return true, nil
+}
+
+func scanRefundPayment(row pgx.Row) (entity.Payment, error) {
+	var payment entity.Payment
+	err := row.Scan(
+		&payment.ID,
+		&payment.CorrelationID,
+		&payment.BookingID,
+		&payment.ValueTotal,
+		&payment.ValueCompany,
+		&payment.Provider,
+		&payment.Status,
+		&payment.RefundCorrelationID,
+		&payment.RefundEndToEndID,
+		&payment.RefundedValue,
+	)
+
+	return payment, err
+}
+
+// ListPaymentsForReconciliation returns the payments of the provider created
+// in [from, to).
+func (r *paymentRepositoryImpl) ListPaymentsForReconciliation(ctx context.Context, provider entity.PaymentProvider, from time.Time, to time.Time) ([]entity.Payment, error) {
+	rows, err := r.db.Query(ctx, listPaymentsForReconciliationQuery, provider, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("paymentRepositoryImpl.ListPaymentsForReconciliation - failed to list payments: %w", err)
+	}
+	defer rows.Close()
+
+	var payments []entity.Payment
+	for rows.Next() {
+		payment, err := scanPayment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("paymentRepositoryImpl.ListPaymentsForReconciliation - failed to scan payment: %w", err)
+		}
+		payments = append(payments, payment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("paymentRepositoryImpl.ListPaymentsForReconciliation - %w", err)
+	}
+
+	return payments, nil
+}
+
+func (r *paymentRepositoryImpl) GetPaymentByCorrelationID(ctx context.Context, correlationId string) (entity.Payment, error) {
+	payment, err := scanPayment(r.db.QueryRow(ctx, getPaymentByCorrelationIDQuery, correlationId))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.Payment{}, fmt.Errorf("paymentRepositoryImpl.GetPaymentByCorrelationID - payment %s not found: %w", correlationId, err)
+		}
+		return entity.Payment{}, fmt.Errorf("paymentRepositoryImpl.GetPaymentByCorrelationID - failed to get payment: %w", err)
+	}
+
+	return payment, nil
+}
+
+// MarkPaymentMismatch moves a pending payment to the mismatch status, keeping
+// the value received from the gateway, and enqueues the alerts. The booking
+// stays pending. It reports false when the payment was no longer pending.
+func (r *paymentRepositoryImpl) MarkPaymentMismatch(ctx context.Context, charge entity.Charge, messages ...entity.OutboxMessage) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("paymentRepositoryImpl.MarkPaymentMismatch - failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	tag, err := tx.Exec(ctx, markPaymentMismatchQuery, charge.CorrelationID, charge.ValueReceived, nullableTime(charge.PaidAt))
+	if err != nil {
+		return false, fmt.Errorf("paymentRepositoryImpl.MarkPaymentMismatch - failed to update payment: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	if err := insertOutboxMessages(ctx, tx, messages); err != nil {
+		return false, fmt.Errorf("paymentRepositoryImpl.MarkPaymentMismatch - %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("paymentRepositoryImpl.MarkPaymentMismatch - failed to commit transaction: %w", err)
+	}
+
+	return true, nil
+}
+
+func (r *paymentRepositoryImpl) ListPaymentMismatches(ctx context.Context, companyId string) ([]entity.PaymentMismatch, error) {
+	rows, err := r.db.Query(ctx, listPaymentMismatchesQuery, companyId, nil)
+	if err != nil {
+		return nil, fmt.Errorf("paymentRepositoryImpl.ListPaymentMismatches - failed to list mismatches: %w", err)
+	}
+	defer rows.Close()
+
+	mismatches := []entity.PaymentMismatch{}
+	for rows.Next() {
+		mismatch, err := scanPaymentMismatch(rows)
+		if err != nil {
+			return nil, fmt.Errorf("paymentRepositoryImpl.ListPaymentMismatches - failed to scan mismatch: %w", err)
+		}
+		mismatches = append(mismatches, mismatch)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("paymentRepositoryImpl.ListPaymentMismatches - %w", err)
+	}
+
+	return mismatches, nil
+}
+
+func (r *paymentRepositoryImpl) GetPaymentMismatch(ctx context.Context, companyId string, paymentId string) (entity.PaymentMismatch, error) {
+	mismatch, err := scanPaymentMismatch(r.db.QueryRow(ctx, listPaymentMismatchesQuery, companyId, paymentId))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.PaymentMismatch{}, fmt.Errorf("paymentRepositoryImpl.GetPaymentMismatch: %w", entity.ErrPaymentMismatchNotFound)
+		}
+		return entity.PaymentMismatch{}, fmt.Errorf("paymentRepositoryImpl.GetPaymentMismatch - failed to get mismatch: %w", err)
+	}
+
+	return mismatch, nil
+}
+
+// AcceptPaymentMismatch marks the payment paid with the value received,
+// confirms its booking and enqueues the guest confirmation. It reports false
+// when the mismatch was already resolved.
+func (r *paymentRepositoryImpl) AcceptPaymentMismatch(ctx context.Context, companyId string, mismatch entity.PaymentMismatch, cancelTokenHash string, messages ...entity.OutboxMessage) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("paymentRepositoryImpl.AcceptPaymentMismatch - failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	tag, err := tx.Exec(ctx, acceptPaymentMismatchQuery, mismatch.PaymentID, companyId)
+	if err != nil {
+		return false, fmt.Errorf("paymentRepositoryImpl.AcceptPaymentMismatch - failed to accept payment: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	if _, err := tx.Exec(ctx, setCancelTokenHashQuery, cancelTokenHash, mismatch.BookingID); err != nil {
+		return false, fmt.Errorf("paymentRepositoryImpl.AcceptPaymentMismatch - failed to set cancel token: %w", err)
+	}
+
+	if err := insertOutboxMessages(ctx, tx, messages); err != nil {
+		return false, fmt.Errorf("paymentRepositoryImpl.AcceptPaymentMismatch - %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("paymentRepositoryImpl.AcceptPaymentMismatch - failed to commit transaction: %w", err)
+	}
+
+	return true, nil
+}
+
+func scanPaymentMismatch(row pgx.Row) (entity.PaymentMismatch, error) {
+	var mismatch entity.PaymentMismatch
+	var paidAt *time.Time
+	err := row.Scan(
+		&mismatch.PaymentID,
+		&mismatch.BookingID,
+		&mismatch.CorrelationID,
+		&mismatch.Provider,
+		&mismatch.GuestName,
+		&mismatch.GuestEmail,
+		&mismatch.CourtName,
+		&mismatch.StartTime,
+		&mismatch.EndTime,
+		&mismatch.ValueTotal,
+		&mismatch.ValueCommission,
+		&mismatch.ValueReceived,
+		&mismatch.ValueDelta,
+		&paidAt,
+	)
+	if paidAt != nil {
+		mismatch.PaidAt = *paidAt
+	}
+
+	return mismatch, err
+}
+
+func scanPayment(row pgx.Row) (entity.Payment, error) {
+	var payment entity.Payment
+	var paidAt *time.Time
+	err := row.Scan(
+		&payment.ID,
+		&payment.CorrelationID,
+		&payment.BookingID,
+		&paidAt,
+		&payment.ValueTotal,
+		&payment.ValueCommission,
+		&payment.ValueCompany,
+		&payment.Provider,
+		&payment.Status,
+		&payment.ValueReceived,
+		&payment.ValueDelta,
+	)
+	if paidAt != nil {
+		payment.PaidAt = *paidAt
+	}
+
+	return payment, err
+}
+
+func (r *paymentRepositoryImpl) ListWithdrawals(ctx context.Context, companyId string) ([]entity.Withdrawal, error) {
+	rows, err := r.db.Query(ctx, listWithdrawalsQuery, companyId)
+	if err != nil {
+		return nil, fmt.Errorf("paymentRepositoryImpl.ListWithdrawals - failed to list withdrawals: %w", err)
+	}
+	defer rows.Close()
+
+	withdrawals := make([]entity.Withdrawal, 0)
+	for rows.Next() {
+		withdrawal, err := scanWithdrawal(rows, false)
+		if err != nil {
+			return nil, fmt.Errorf("paymentRepositoryImpl.ListWithdrawals - failed to scan withdrawal: %w", err)
+		}
+		withdrawals = append(withdrawals, withdrawal)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("paymentRepositoryImpl.ListWithdrawals - %w", err)
+	}
+
+	return withdrawals, nil
+}
+
+// ListPendingWithdrawals returns withdrawals the gateway has not finished for
+// longer than olderThan, along with the account they were made from.
+func (r *paymentRepositoryImpl) ListPendingWithdrawals(ctx context.Context, olderThan time.Duration) ([]entity.Withdrawal, error) {
+	rows, err := r.db.Query(ctx, listPendingWithdrawalsQuery, olderThan.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("paymentRepositoryImpl.ListPendingWithdrawals - failed to list withdrawals: %w", err)
+	}
+	defer rows.Close()
+
+	withdrawals := make([]entity.Withdrawal, 0)
+	for rows.Next() {
+		withdrawal, err := scanWithdrawal(rows, true)
+		if err != nil {
+			return nil, fmt.Errorf("paymentRepositoryImpl.ListPendingWithdrawals - failed to scan withdrawal: %w", err)
+		}
+		withdrawals = append(withdrawals, withdrawal)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("paymentRepositoryImpl.ListPendingWithdrawals - %w", err)
+	}
+
+	return withdrawals, nil
+}
+
+// UpdateWithdrawalStatus finishes a pending withdrawal. It reports false when
+// the withdrawal was already finished.
+func (r *paymentRepositoryImpl) UpdateWithdrawalStatus(ctx context.Context, withdrawal entity.Withdrawal) (bool, error) {
+	tag, err := r.db.Exec(
+		ctx,
+		updateWithdrawalStatusQuery,
+		withdrawal.ID,
+		withdrawal.Status,
+		withdrawal.FailureReason,
+		nullableTime(withdrawal.CompletedAt),
+	)
+	if err != nil {
+		return false, fmt.Errorf("paymentRepositoryImpl.UpdateWithdrawalStatus - failed to update withdrawal: %w", err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// GetPayoutSchedule returns the company schedule, or a disabled one when the
+// company never configured it.
+func (r *paymentRepositoryImpl) GetPayoutSchedule(ctx context.Context, companyId string) (entity.PayoutSchedule, error) {
+	schedule, err := scanPayoutSchedule(r.db.QueryRow(ctx, getPayoutScheduleQuery, companyId))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.PayoutSchedule{CompanyId: companyId, Weekday: time.Monday}, nil
+		}
+		return entity.PayoutSchedule{}, fmt.Errorf("paymentRepositoryImpl.GetPayoutSchedule - failed to get payout schedule: %w", err)
+	}
+
+	return schedule, nil
+}
+
+func (r *paymentRepositoryImpl) SavePayoutSchedule(ctx context.Context, schedule entity.PayoutSchedule) error {
+	_, err := r.db.Exec(ctx, savePayoutScheduleQuery, schedule.CompanyId, schedule.Enabled, int(schedule.Weekday))
+	if err != nil {
+		return fmt.Errorf("paymentRepositoryImpl.SavePayoutSchedule - failed to save payout schedule: %w", err)
+	}
+
+	return nil
+}
+
+// ClaimDuePayoutSchedules marks the enabled schedules for weekday that did not
+// run since dayStart as run and returns them with their previous
+// LastPayoutAt.
+func (r *paymentRepositoryImpl) ClaimDuePayoutSchedules(ctx context.Context, weekday time.Weekday, dayStart time.Time) ([]entity.PayoutSchedule, error) {
+	rows, err := r.db.Query(ctx, claimDuePayoutSchedulesQuery, int(weekday), dayStart)
+	if err != nil {
+		return nil, fmt.Errorf("paymentRepositoryImpl.ClaimDuePayoutSchedules - failed to claim payout schedules: %w", err)
+	}
+	defer rows.Close()
+
+	schedules := make([]entity.PayoutSchedule, 0)
+	for rows.Next() {
+		schedule, err := scanPayoutSchedule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("paymentRepositoryImpl.ClaimDuePayoutSchedules - failed to scan payout schedule: %w", err)
+		}
+		schedules = append(schedules, schedule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("paymentRepositoryImpl.ClaimDuePayoutSchedules - %w", err)
+	}
+
+	return schedules, nil
+}
+
+// ReleasePayoutSchedule puts back the LastPayoutAt of a claimed schedule whose
+// payout failed.
+func (r *paymentRepositoryImpl) ReleasePayoutSchedule(ctx context.Context, schedule entity.PayoutSchedule) error {
+	_, err := r.db.Exec(ctx, releasePayoutScheduleQuery, schedule.CompanyId, nullableTime(schedule.LastPayoutAt))
+	if err != nil {
+		return fmt.Errorf("paymentRepositoryImpl.ReleasePayoutSchedule - failed to release payout schedule: %w", err)
+	}
+
+	return nil
+}
+
+// scanWithdrawal reads a withdrawal row, followed by the gateway account id
+// when withAccount is set.
+func scanWithdrawal(row pgx.Row, withAccount bool) (entity.Withdrawal, error) {
+	var withdrawal entity.Withdrawal
+	var completedAt *time.Time
+	dest := []any{
+		&withdrawal.ID,
+		&withdrawal.CompanyID,
+		&withdrawal.Provider,
+		&withdrawal.CorrelationID,
+		&withdrawal.Value,
+		&withdrawal.Status,
+		&withdrawal.FailureReason,
+		&withdrawal.Trigger,
+		&withdrawal.CreatedAt,
+		&completedAt,
+	}
+	if withAccount {
+		dest = append(dest, &withdrawal.AccountID)
+	}
+	err := row.Scan(dest...)
+	if completedAt != nil {
+		withdrawal.CompletedAt = *completedAt
+	}
+
+	return withdrawal, err
+}
+
+func scanPayoutSchedule(row pgx.Row) (entity.PayoutSchedule, error) {
+	var schedule entity.PayoutSchedule
+	var weekday int
+	var lastPayoutAt *time.Time
+	err := row.Scan(
+		&schedule.CompanyId,
+		&schedule.Enabled,
+		&weekday,
+		&lastPayoutAt,
+	)
+	schedule.Weekday = time.Weekday(weekday)
+	if lastPayoutAt != nil {
+		schedule.LastPayoutAt = *lastPayoutAt
+	}
+
+	return schedule, err
+}
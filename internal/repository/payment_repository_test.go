@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dinizgab/booking-mvp/internal/database"
+	"github.com/jackc/pgx/v5"
+)
+
+// columnDatabase answers QueryRow with a single row whose values are looked up
+// by the expressions of the select list of the query, so the test breaks when
+// a query stops selecting a column or the scan reads the columns in another
+// order.
+type columnDatabase struct {
+	database.Database
+	columns map[string]any
+}
+
+func (d columnDatabase) QueryRow(ctx context.Context, sql string, arguments ...any) pgx.Row {
+	return columnRow{columns: d.columns, selected: selectList(sql)}
+}
+
+type columnRow struct {
+	columns  map[string]any
+	selected []string
+}
+
+func (r columnRow) Scan(dest ...any) error {
+	if len(dest) != len(r.selected) {
+		return fmt.Errorf("scanning %d values from %d columns", len(dest), len(r.selected))
+	}
+
+	for i, column := range r.selected {
+		value, ok := r.columns[column]
+		if !ok {
+			return fmt.Errorf("no value for column %q", column)
+		}
+
+		target := reflect.ValueOf(dest[i]).Elem()
+		source := reflect.ValueOf(value)
+		if target.Kind() == reflect.Pointer {
+			pointer := reflect.New(target.Type().Elem())
+			pointer.Elem().Set(source.Convert(target.Type().Elem()))
+			source = pointer
+		}
+		target.Set(source.Convert(target.Type()))
+	}
+
+	return nil
+}
+
+// selectList splits the expressions between select and from, ignoring the
+// commas inside function calls.
+func selectList(sql string) []string {
+	list := sql[len("select "):strings.Index(sql, "\nfrom")]
+
+	var columns []string
+	depth, start := 0, 0
+	for i, r := range list {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				columns = append(columns, strings.TrimSpace(list[start:i]))
+				start = i + 1
+			}
+		}
+	}
+
+	return append(columns, strings.TrimSpace(list[start:]))
+}
+
+func TestGetPaymentLoadsTheCommission(t *testing.T) {
+	paidAt := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	repo := NewPaymentRepository(columnDatabase{columns: map[string]any{
+		"id":                            "payment-1",
+		"correlation_id":                "correlation-1",
+		"booking_id":                    "booking-1",
+		"paid_at":                       paidAt,
+		"value_total":                   int64(11000),
+		"value_commission":              int64(1000),
+		"value_company":                 int64(10000),
+		"provider":                      "openpix",
+		"status":                        "paid",
+		"commission_paid_by":            "guest",
+		"coalesce(payment_link_id, '')": "charge-1",
+		"coalesce(value_received, 0)":   int64(11000),
+		"coalesce(value_delta, 0)":      int64(0),
+		"coalesce(refunded_value, 0)":   int64(0),
+		"refunded_platform_value":       int64(0),
+	}})
+
+	byBooking, err := repo.GetPaymentByBookingID(context.Background(), "booking-1")
+	if err != nil {
+		t.Fatalf("GetPaymentByBookingID() error = %v", err)
+	}
+
+	byCorrelation, err := repo.GetPaymentByCorrelationID(context.Background(), "correlation-1")
+	if err != nil {
+		t.Fatalf("GetPaymentByCorrelationID() error = %v", err)
+	}
+
+	for name, payment := range map[string]struct {
+		commission, company int64
+		paidAt              time.Time
+	}{
+		"GetPaymentByBookingID":     {byBooking.ValueCommission, byBooking.ValueCompany, byBooking.PaidAt},
+		"GetPaymentByCorrelationID": {byCorrelation.ValueCommission, byCorrelation.ValueCompany, byCorrelation.PaidAt},
+	} {
+		if payment.commission != 1000 || payment.company != 10000 {
+			t.Errorf("%s() commission = %d, company = %d, want 1000 and 10000", name, payment.commission, payment.company)
+		}
+		if !payment.paidAt.Equal(paidAt) {
+			t.Errorf("%s() paid at = %v, want %v", name, payment.paidAt, paidAt)
+		}
+	}
+}
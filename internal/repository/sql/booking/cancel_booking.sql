@@ -1,4 +1,14 @@
+WITH expired_payments AS (
+    UPDATE payments SET
+        status = 'expired',
+        updated_at = now()
+    WHERE
+        booking_id = $1
+        AND status = 'pending'
+)
 UPDATE bookings SET
-    status = 'cancelled'
+    status = 'cancelled',
+    cancelled_at = coalesce(cancelled_at, now()),
+    cancelled_by = coalesce(cancelled_by, 'guest')
 WHERE
     id = $1
@@ -0,0 +1,24 @@
+WITH expired_payments AS (
+    UPDATE payments SET
+        status = 'expired',
+        updated_at = now()
+    WHERE
+        booking_id = $1
+        AND status = 'pending'
+        AND EXISTS (
+            SELECT 1
+            FROM bookings
+            WHERE id = $1
+                AND company_id = $2
+                AND cancelled_at IS NULL
+        )
+)
+UPDATE bookings SET
+    status = 'cancelled',
+    cancelled_at = now(),
+    cancelled_by = 'company',
+    cancellation_reason = $3
+WHERE
+    id = $1
+    AND company_id = $2
+    AND cancelled_at IS NULL
@@ -0,0 +1,21 @@
+with series as (
+    update booking_series
+    set status = 'cancelled'
+    where id = $1
+        and company_id = $2
+    returning id
+), cancelled as (
+    update bookings
+    set status = 'cancelled',
+        cancelled_at = now(),
+        cancelled_by = 'company'
+    where series_id in (select id from series)
+        and cancelled_at is null
+        and start_time > now()
+    returning id
+)
+update payments
+set status = 'expired',
+    updated_at = now()
+where booking_id in (select id from cancelled)
+    and status = 'pending'
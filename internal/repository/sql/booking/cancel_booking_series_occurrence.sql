@@ -0,0 +1,20 @@
+with cancelled as (
+    update bookings b
+    set status = 'cancelled',
+        cancelled_at = now(),
+        cancelled_by = 'company'
+    from booking_series s
+    where b.series_id = s.id
+        and s.id = $1
+        and s.company_id = $2
+        and b.id = $3
+        and b.cancelled_at is null
+    returning b.id
+), expired_payments as (
+    update payments
+    set status = 'expired',
+        updated_at = now()
+    where booking_id in (select id from cancelled)
+        and status = 'pending'
+)
+select count(*) from cancelled
@@ -9,7 +9,11 @@ INSERT INTO bookings(
     verification_code,
     total_price,
     cancel_token_hash,
-    company_id
+    company_id,
+    hold_expires_at,
+    series_id,
+    payment_method,
+    created_by
 )
 VALUES(
 $1,
@@ -22,6 +26,10 @@ $7,
 $8,
 $9,
 $10,
-$11
+$11,
+$12,
+$13,
+$14,
+$15
 )
 RETURNING id
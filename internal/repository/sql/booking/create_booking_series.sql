@@ -0,0 +1,29 @@
+INSERT INTO booking_series(
+    company_id,
+    court_id,
+    guest_name,
+    guest_phone,
+    guest_email,
+    frequency,
+    start_time,
+    end_time,
+    until,
+    occurrences_count,
+    payment_mode,
+    status
+)
+VALUES(
+$1,
+$2,
+$3,
+$4,
+$5,
+$6,
+$7,
+$8,
+$9,
+$10,
+$11,
+$12
+)
+RETURNING id, created_at
@@ -10,6 +10,8 @@ SELECT
     b.guest_email,
     b.verification_code,
     b.total_price,
+    b.payment_method,
+    coalesce(b.created_by::text, ''),
     c.name AS name
 FROM
     bookings b
@@ -0,0 +1,20 @@
+SELECT
+    id,
+    company_id,
+    court_id,
+    guest_name,
+    guest_phone,
+    guest_email,
+    frequency,
+    start_time,
+    end_time,
+    until,
+    coalesce(occurrences_count, 0),
+    payment_mode,
+    status,
+    created_at
+FROM
+    booking_series
+WHERE
+    id = $1
+    AND company_id = $2
@@ -0,0 +1,13 @@
+SELECT
+    b.id,
+    b.court_id,
+    b.start_time,
+    b.end_time,
+    b.status,
+    b.payment_method,
+    b.series_id
+FROM
+    bookings b
+WHERE
+    b.id = $1
+    AND b.company_id = $2
@@ -8,8 +8,11 @@ SELECT
     b.start_time,
     b.end_time,
     p.value_total,
+    CASE WHEN p.commission_paid_by = 'guest' THEN p.value_commission ELSE 0 END,
     b.verification_code,
-    b.cancel_token_hash
+    b.cancel_token_hash,
+    co.email,
+    b.status
 FROM
     bookings b
 JOIN courts c
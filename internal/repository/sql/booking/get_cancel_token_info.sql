@@ -1,3 +1,4 @@
-SELECT cancel_token_hash, cancel_token_expires_at
-FROM bookings
-WHERE id = $1;
+SELECT b.cancel_token_hash, b.start_time, b.status, c.company_id, b.series_id
+FROM bookings b
+JOIN courts c ON c.id = b.court_id
+WHERE b.id = $1;
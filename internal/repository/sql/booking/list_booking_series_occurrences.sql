@@ -0,0 +1,14 @@
+SELECT
+    id,
+    court_id,
+    start_time,
+    end_time,
+    created_at,
+    status,
+    total_price
+FROM
+    bookings
+WHERE
+    series_id = $1
+ORDER BY
+    start_time
@@ -7,6 +7,7 @@ SELECT
     b.guest_name,
     b.guest_phone,
     b.guest_email,
+    b.payment_method,
     c.name
 FROM
     bookings b
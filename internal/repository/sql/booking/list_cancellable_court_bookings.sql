@@ -0,0 +1,19 @@
+SELECT
+    b.id,
+    b.court_id,
+    b.start_time,
+    b.end_time,
+    b.status,
+    b.payment_method,
+    b.series_id
+FROM
+    bookings b
+WHERE
+    b.court_id = $1
+    AND b.company_id = $2
+    AND b.start_time >= $3
+    AND b.start_time < $4
+    AND b.start_time > now()
+    AND b.status IN ('pending', 'confirmed')
+ORDER BY
+    b.start_time
@@ -0,0 +1,21 @@
+with released as (
+    update bookings
+    set status = 'cancelled'
+    where status = 'pending'
+        and hold_expires_at < now()
+        and not exists (
+            select 1
+            from payments p
+            where p.booking_id = bookings.id
+                and p.status = 'mismatch'
+        )
+    returning id
+), expired_payments as (
+    update payments
+    set status = 'expired',
+        updated_at = now()
+    where booking_id in (select id from released)
+        and status = 'pending'
+    returning id
+)
+select count(*) from released
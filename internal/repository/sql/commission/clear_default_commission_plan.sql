@@ -0,0 +1,5 @@
+update commission_plans
+set is_default = false,
+    updated_at = now()
+where is_default
+    and id is distinct from $1
@@ -0,0 +1,3 @@
+insert into commission_plans (name, percent_bps, fixed_fee, paid_by, is_default)
+values ($1, $2, $3, $4, $5)
+returning id, name, percent_bps, fixed_fee, paid_by, is_default, created_at, updated_at
@@ -0,0 +1,6 @@
+select cp.id, cp.name, cp.percent_bps, cp.fixed_fee, cp.paid_by, cp.is_default, cp.created_at, cp.updated_at
+from commission_plans cp
+where cp.id = (select commission_plan_id from companies where id = $1)
+    or cp.is_default
+order by cp.is_default
+limit 1
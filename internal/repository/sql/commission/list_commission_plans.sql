@@ -0,0 +1,3 @@
+select id, name, percent_bps, fixed_fee, paid_by, is_default, created_at, updated_at
+from commission_plans
+order by is_default desc, name
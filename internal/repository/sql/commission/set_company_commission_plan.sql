@@ -0,0 +1,3 @@
+update companies
+set commission_plan_id = $2
+where id = $1
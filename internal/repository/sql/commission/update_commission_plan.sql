@@ -0,0 +1,9 @@
+update commission_plans
+set name = $2,
+    percent_bps = $3,
+    fixed_fee = $4,
+    paid_by = $5,
+    is_default = $6,
+    updated_at = now()
+where id = $1
+returning id, name, percent_bps, fixed_fee, paid_by, is_default, created_at, updated_at
@@ -1,3 +1,3 @@
-INSERT INTO companies (name, address, phone, email, password_hash, cnpj, slug)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
-RETURNING id
+INSERT INTO companies (name, address, phone, email, cnpj, slug, payment_provider)
+VALUES ($1, $2, $3, $4, $5, $6, coalesce(nullif($7, '')::payment_provider, 'openpix'))
+RETURNING id, payment_provider
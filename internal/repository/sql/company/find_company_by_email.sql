@@ -1,3 +0,0 @@
-select id, email, password_hash
-from companies
-where email = $1
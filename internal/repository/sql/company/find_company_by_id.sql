@@ -7,7 +7,8 @@ SELECT
     cnpj,
     slug,
     pix_key,
-    pix_key_type
+    pix_key_type,
+    payment_provider
 FROM
     companies c
 LEFT JOIN openpix_subaccounts os
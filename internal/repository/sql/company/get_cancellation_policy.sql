@@ -0,0 +1,8 @@
+SELECT
+    company_id,
+    tiers,
+    refund_platform_fee
+FROM
+    cancellation_policies
+WHERE
+    company_id = $1
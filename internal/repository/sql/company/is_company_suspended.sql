@@ -0,0 +1,3 @@
+SELECT suspended_at IS NOT NULL
+FROM companies
+WHERE id = $1
@@ -0,0 +1,6 @@
+INSERT INTO cancellation_policies (company_id, tiers, refund_platform_fee)
+VALUES ($1, $2, $3)
+ON CONFLICT (company_id) DO UPDATE SET
+    tiers = excluded.tiers,
+    refund_platform_fee = excluded.refund_platform_fee,
+    updated_at = now()
@@ -5,5 +5,6 @@ set
     phone = $3,
     email = $4,
     cnpj = $5,
-    slug = $6
+    slug = $6,
+    payment_provider = coalesce(nullif($8, '')::payment_provider, payment_provider)
 where id = $7
@@ -4,12 +4,22 @@ INSERT INTO courts(company_id,
                    sport_type,
                    hourly_price,
                    is_active,
-                   capacity)
+                   capacity,
+                   min_duration_minutes,
+                   max_duration_minutes,
+                   slot_granularity_minutes,
+                   lead_time_minutes,
+                   max_advance_days)
 VALUES ($1,
         $2,
         $3,
         $4,
         $5,
         $6,
-        $7)
+        $7,
+        $8,
+        $9,
+        $10,
+        $11,
+        $12)
 RETURNING id;
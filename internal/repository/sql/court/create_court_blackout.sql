@@ -0,0 +1,3 @@
+INSERT INTO court_blackouts (company_id, court_id, start_time, end_time, all_day, reason)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id
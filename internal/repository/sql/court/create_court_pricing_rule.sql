@@ -0,0 +1,12 @@
+INSERT INTO court_pricing_rules(court_id,
+                                weekday,
+                                start_time,
+                                end_time,
+                                hourly_price,
+                                priority)
+VALUES ($1,
+        $2,
+        $3,
+        $4,
+        $5,
+        $6)
@@ -0,0 +1,5 @@
+DELETE FROM court_blackouts
+WHERE
+    id = $1
+    AND company_id = $2
+    AND court_id IS NOT DISTINCT FROM $3
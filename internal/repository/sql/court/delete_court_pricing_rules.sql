@@ -0,0 +1 @@
+DELETE FROM court_pricing_rules WHERE court_id = $1
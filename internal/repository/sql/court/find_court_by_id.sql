@@ -6,7 +6,12 @@ SELECT
     c.sport_type,
     c.hourly_price,
     c.is_active,
-    c.capacity
+    c.capacity,
+    c.min_duration_minutes,
+    c.max_duration_minutes,
+    c.slot_granularity_minutes,
+    c.lead_time_minutes,
+    c.max_advance_days
 FROM
     courts c
 WHERE
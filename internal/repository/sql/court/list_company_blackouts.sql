@@ -0,0 +1,17 @@
+SELECT
+    id,
+    company_id,
+    court_id,
+    start_time,
+    end_time,
+    all_day,
+    reason,
+    created_at
+FROM
+    court_blackouts
+WHERE
+    company_id = $1
+    AND court_id IS NULL
+    AND end_time > now()
+ORDER BY
+    start_time
@@ -0,0 +1,18 @@
+SELECT
+    cb.id,
+    cb.company_id,
+    cb.court_id,
+    cb.start_time,
+    cb.end_time,
+    cb.all_day,
+    cb.reason,
+    cb.created_at
+FROM
+    court_blackouts cb
+JOIN courts c
+    ON c.id = $1
+WHERE
+    (cb.court_id = c.id OR (cb.court_id IS NULL AND cb.company_id = c.company_id))
+    AND cb.end_time > now()
+ORDER BY
+    cb.start_time
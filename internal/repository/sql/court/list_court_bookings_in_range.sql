@@ -0,0 +1,6 @@
+select start_time, end_time
+from bookings
+where court_id = $1
+    and tstzrange(start_time, end_time) && tstzrange($2, $3)
+    and status <> 'cancelled'
+order by start_time
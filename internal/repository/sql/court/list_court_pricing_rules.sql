@@ -0,0 +1,14 @@
+SELECT
+    id,
+    court_id,
+    weekday,
+    start_time,
+    end_time,
+    hourly_price,
+    priority
+FROM
+    court_pricing_rules
+WHERE
+    court_id = $1
+ORDER BY
+    priority DESC, created_at, id
@@ -4,5 +4,10 @@ UPDATE courts SET
     sport_type = $3,
     hourly_price = $4,
     is_active = $5,
-    capacity = $6
+    capacity = $6,
+    min_duration_minutes = COALESCE($8, min_duration_minutes),
+    max_duration_minutes = COALESCE($9, max_duration_minutes),
+    slot_granularity_minutes = COALESCE($10, slot_granularity_minutes),
+    lead_time_minutes = COALESCE($11, lead_time_minutes),
+    max_advance_days = COALESCE($12, max_advance_days)
 WHERE id = $7
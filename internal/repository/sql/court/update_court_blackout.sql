@@ -0,0 +1,9 @@
+UPDATE court_blackouts SET
+    start_time = $1,
+    end_time = $2,
+    all_day = $3,
+    reason = $4
+WHERE
+    id = $5
+    AND company_id = $6
+    AND court_id IS NOT DISTINCT FROM $7
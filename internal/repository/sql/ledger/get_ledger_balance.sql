@@ -0,0 +1,14 @@
+select c.name,
+       coalesce(sum(
+           case
+               when l.credit_account = 'company' then l.amount
+               when l.debit_account = 'company' then -l.amount
+               else 0
+           end
+       ), 0)
+from companies c
+left join company_ledger l
+    on l.company_id = c.id
+    and l.occurred_at < $2
+where c.id = $1
+group by c.name
@@ -0,0 +1,6 @@
+select id, occurred_at, entry_type, reference_id, coalesce(booking_id::text, ''), debit_account, credit_account, amount
+from company_ledger
+where company_id = $1
+    and occurred_at >= $2
+    and occurred_at < $3
+order by occurred_at, id
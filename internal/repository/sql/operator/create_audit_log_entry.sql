@@ -0,0 +1,2 @@
+INSERT INTO operator_audit_log (operator_id, company_id, action, target_id, status_code, details)
+VALUES ($1, $2, $3, $4, $5, $6)
@@ -0,0 +1,3 @@
+INSERT INTO operators (name, email, password_hash)
+VALUES ($1, $2, $3)
+RETURNING id, created_at
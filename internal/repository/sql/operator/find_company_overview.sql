@@ -0,0 +1,17 @@
+SELECT
+    c.id,
+    c.name,
+    c.slug,
+    c.email,
+    c.phone,
+    COALESCE(c.cnpj, ''),
+    c.payment_provider,
+    c.commission_plan_id,
+    c.suspended_at,
+    COALESCE(c.suspension_reason, ''),
+    (SELECT COUNT(*) FROM courts ct WHERE ct.company_id = c.id),
+    (SELECT COUNT(*) FROM bookings b WHERE b.company_id = c.id)
+FROM
+    companies c
+WHERE
+    c.id = $1
@@ -0,0 +1,3 @@
+SELECT id, name, email, password_hash, created_at
+FROM operators
+WHERE email = $1
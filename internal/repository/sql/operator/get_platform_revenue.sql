@@ -0,0 +1,35 @@
+-- Payments count in the period they were paid, refunds in the period they
+-- were refunded. The commission returned with refunds comes out of the
+-- commission of the period it was returned in.
+WITH paid AS (
+    SELECT
+        provider,
+        COUNT(*) AS payments,
+        SUM(COALESCE(value_received, value_total)) AS volume,
+        SUM(value_commission) AS commission,
+        SUM(value_gateway_fee) AS gateway_fees
+    FROM payments
+    WHERE paid_at >= $1 AND paid_at < $2
+    GROUP BY provider
+),
+refunded AS (
+    SELECT
+        provider,
+        SUM(refunded_value) AS refunded,
+        SUM(refunded_platform_value) AS commission_refunded
+    FROM payments
+    WHERE status = 'refunded'
+        AND refunded_at AT TIME ZONE 'UTC' >= $1
+        AND refunded_at AT TIME ZONE 'UTC' < $2
+    GROUP BY provider
+)
+SELECT
+    COALESCE(p.provider, r.provider) AS provider,
+    COALESCE(p.payments, 0),
+    COALESCE(p.volume, 0),
+    COALESCE(p.commission, 0) - COALESCE(r.commission_refunded, 0),
+    COALESCE(p.gateway_fees, 0),
+    COALESCE(r.refunded, 0)
+FROM paid p
+FULL OUTER JOIN refunded r ON r.provider = p.provider
+ORDER BY provider
@@ -0,0 +1,18 @@
+SELECT
+    id,
+    operator_id,
+    company_id,
+    action,
+    target_id,
+    status_code,
+    details,
+    created_at
+FROM
+    operator_audit_log
+WHERE
+    ($1::uuid IS NULL OR operator_id = $1)
+    AND ($2::uuid IS NULL OR company_id = $2)
+ORDER BY
+    created_at DESC
+LIMIT $3
+OFFSET $4
@@ -0,0 +1,33 @@
+SELECT
+    c.id,
+    c.name,
+    c.slug,
+    c.email,
+    c.phone,
+    COALESCE(c.cnpj, ''),
+    c.payment_provider,
+    c.commission_plan_id,
+    c.suspended_at,
+    COALESCE(c.suspension_reason, ''),
+    (SELECT COUNT(*) FROM courts ct WHERE ct.company_id = c.id),
+    (SELECT COUNT(*) FROM bookings b WHERE b.company_id = c.id),
+    COUNT(*) OVER ()
+FROM
+    companies c
+WHERE
+    (
+        $1 = ''
+        OR c.name ILIKE '%' || $1 || '%'
+        OR c.email ILIKE '%' || $1 || '%'
+        OR c.slug ILIKE '%' || $1 || '%'
+        OR c.cnpj ILIKE '%' || $1 || '%'
+    )
+    AND (
+        $2 = ''
+        OR ($2 = 'active' AND c.suspended_at IS NULL)
+        OR ($2 = 'suspended' AND c.suspended_at IS NOT NULL)
+    )
+ORDER BY
+    c.name
+LIMIT $3
+OFFSET $4
@@ -0,0 +1,6 @@
+UPDATE companies
+SET
+    suspended_at = NULL,
+    suspension_reason = NULL
+WHERE
+    id = $1
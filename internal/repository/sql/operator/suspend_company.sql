@@ -0,0 +1,6 @@
+UPDATE companies
+SET
+    suspended_at = COALESCE(suspended_at, now()),
+    suspension_reason = $2
+WHERE
+    id = $1
@@ -0,0 +1,10 @@
+INSERT INTO outbox_messages(booking_id,
+                            template,
+                            subject,
+                            recipient,
+                            payload)
+VALUES ($1,
+        $2,
+        $3,
+        $4,
+        $5)
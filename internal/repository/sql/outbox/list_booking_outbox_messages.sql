@@ -0,0 +1,22 @@
+SELECT
+    o.id,
+    o.booking_id,
+    o.template,
+    o.subject,
+    o.recipient,
+    o.payload,
+    o.status,
+    o.attempts,
+    o.last_error,
+    o.next_attempt_at,
+    o.sent_at,
+    o.created_at
+FROM
+    outbox_messages o
+JOIN bookings b
+    ON b.id = o.booking_id
+WHERE
+    o.booking_id = $1
+    AND b.company_id = $2
+ORDER BY
+    o.created_at
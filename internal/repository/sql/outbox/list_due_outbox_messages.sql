@@ -0,0 +1,21 @@
+SELECT
+    id,
+    booking_id,
+    template,
+    subject,
+    recipient,
+    payload,
+    status,
+    attempts,
+    last_error,
+    next_attempt_at,
+    sent_at,
+    created_at
+FROM
+    outbox_messages
+WHERE
+    status = 'pending'
+    AND next_attempt_at <= now()
+ORDER BY
+    next_attempt_at
+LIMIT $1
@@ -0,0 +1,7 @@
+UPDATE outbox_messages SET
+    status = $2,
+    attempts = attempts + 1,
+    last_error = $3,
+    next_attempt_at = $4,
+    payload = CASE WHEN $2 = 'dead'::outbox_status THEN payload - 'cancel_token' ELSE payload END
+WHERE id = $1
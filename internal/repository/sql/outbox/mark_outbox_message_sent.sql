@@ -0,0 +1,8 @@
+-- The cancel token is only needed to render the email, drop it once sent.
+UPDATE outbox_messages SET
+    status = 'sent',
+    attempts = attempts + 1,
+    last_error = '',
+    sent_at = now(),
+    payload = payload - 'cancel_token'
+WHERE id = $1
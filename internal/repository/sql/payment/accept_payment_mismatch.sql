@@ -0,0 +1,24 @@
+with payment_accepted as (
+    update payments
+    set status = 'paid',
+        mismatch_resolution = 'accept',
+        mismatch_resolved_at = now(),
+        updated_at = now()
+    where id = $1
+        and company_id = $2
+        and status = 'mismatch'
+    returning booking_id
+), upfront_series as (
+    select s.id
+    from booking_series s
+    join bookings fb
+        on fb.series_id = s.id
+    join payment_accepted pa
+        on fb.id = pa.booking_id
+    where s.payment_mode = 'upfront'
+)
+update bookings b
+set status = 'confirmed'
+from payment_accepted pa
+where (b.id = pa.booking_id or b.series_id in (select id from upfront_series))
+    and b.status = 'pending'
@@ -0,0 +1,13 @@
+-- Claims the schedules due today so each company gets at most one automatic
+-- payout per day, even when the job runs more than once. The schedules come
+-- back with the last payout before the claim, which is put back when the
+-- payout fails.
+update payout_schedules s
+set last_payout_at = now(),
+    updated_at = now()
+from payout_schedules previous
+where previous.company_id = s.company_id
+    and s.enabled
+    and s.weekday = $1
+    and (s.last_payout_at is null or s.last_payout_at < $2)
+returning s.company_id, s.enabled, s.weekday, previous.last_payout_at
@@ -1,15 +1,23 @@
 with payment_confirmed as (
     update payments
     set status = 'paid',
-        paid_at = $2,
+        paid_at = coalesce($2, now()),
+        value_gateway_fee = $3,
         updated_at = now()
     where correlation_id = $1
         and status = 'pending'
     returning booking_id
+), upfront_series as (
+    select s.id
+    from booking_series s
+    join bookings fb
+        on fb.series_id = s.id
+    join payment_confirmed pc
+        on fb.id = pc.booking_id
+    where s.payment_mode = 'upfront'
 )
 update bookings b
 set status = 'confirmed'
 from payment_confirmed pc
-where b.id = pc.booking_id
+where (b.id = pc.booking_id or b.series_id in (select id from upfront_series))
     and b.status = 'pending'
-
@@ -8,7 +8,9 @@ insert into payments (
     brcode,
     value_total,
     value_commission,
-    expires_at
+    expires_at,
+    provider,
+    commission_paid_by
 ) values (
     $1,
     $2,
@@ -19,6 +21,7 @@ insert into payments (
     $7,
     $8,
     $9,
-    $10
+    $10,
+    $11,
+    $12
 )
-
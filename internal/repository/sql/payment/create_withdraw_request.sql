@@ -1,6 +1,12 @@
-insert into withdrawals (company_id, correlation_id, value)
+insert into withdrawals (company_id, correlation_id, value, provider, status, failure_reason, trigger, completed_at)
 values (
     $1, 
     $2,
-    $3
-);
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8
+)
+returning id, created_at;
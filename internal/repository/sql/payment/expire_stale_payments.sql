@@ -0,0 +1,15 @@
+with expired as (
+    update payments
+    set status = 'expired',
+        updated_at = now()
+    where status = 'pending'
+        and expires_at < now()
+    returning booking_id
+), cancelled as (
+    update bookings
+    set status = 'cancelled'
+    where id in (select booking_id from expired)
+        and status = 'pending'
+    returning id
+)
+select count(*) from expired
@@ -0,0 +1,7 @@
+update payments set
+    status = $2::text::payment_status,
+    refunded_at = case when $2::text = 'refunded' then coalesce($3, now()) else refunded_at end,
+    refund_failure_reason = nullif($4, ''),
+    updated_at = now()
+where refund_correlation_id = $1
+    and status = 'refunding'
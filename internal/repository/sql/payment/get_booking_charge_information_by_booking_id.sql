@@ -1,3 +1,4 @@
-select brcode, qr_code_image
+select brcode, qr_code_image, status, refund_requested_at, refunded_at,
+       coalesce(refunded_value, 0), coalesce(refund_failure_reason, '')
 from payments
 where booking_id = $1
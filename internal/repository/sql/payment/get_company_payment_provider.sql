@@ -0,0 +1,3 @@
+select payment_provider
+from companies
+where id = $1
@@ -0,0 +1,4 @@
+select account_id
+from payment_accounts
+where company_id = $1
+    and provider = $2
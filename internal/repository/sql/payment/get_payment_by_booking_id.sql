@@ -1,3 +1,4 @@
-select id, correlation_id, booking_id, paid_at, value_total
+select id, correlation_id, booking_id, paid_at, value_total, value_commission, value_company, provider, status, commission_paid_by, coalesce(payment_link_id, ''),
+       coalesce(value_received, 0), coalesce(refunded_value, 0), refunded_platform_value
 from payments
 where booking_id = $1;
@@ -0,0 +1,3 @@
+select id, correlation_id, booking_id, paid_at, value_total, value_commission, value_company, provider, status, coalesce(value_received, 0), coalesce(value_delta, 0)
+from payments
+where correlation_id = $1
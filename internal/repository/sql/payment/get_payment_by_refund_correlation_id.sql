@@ -0,0 +1,4 @@
+select id, correlation_id, booking_id, value_total, value_company, provider, status,
+       coalesce(refund_correlation_id, ''), coalesce(end_to_end_id, ''), coalesce(refunded_value, 0)
+from payments
+where refund_correlation_id = $1
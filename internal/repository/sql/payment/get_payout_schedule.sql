@@ -0,0 +1,3 @@
+select company_id, enabled, weekday, last_payout_at
+from payout_schedules
+where company_id = $1
@@ -1,3 +0,0 @@
-select pix_key
-from openpix_subaccounts
-where company_id = $1
@@ -0,0 +1,23 @@
+select p.id,
+       p.booking_id,
+       p.correlation_id,
+       p.provider,
+       b.guest_name,
+       b.guest_email,
+       c.name,
+       b.start_time,
+       b.end_time,
+       p.value_total,
+       p.value_commission,
+       coalesce(p.value_received, 0),
+       coalesce(p.value_delta, 0),
+       p.paid_at
+from payments p
+join bookings b
+    on b.id = p.booking_id
+join courts c
+    on c.id = b.court_id
+where p.company_id = $1
+    and p.status = 'mismatch'
+    and ($2::uuid is null or p.id = $2)
+order by p.paid_at desc
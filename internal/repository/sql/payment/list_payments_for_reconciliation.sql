@@ -0,0 +1,6 @@
+select id, correlation_id, booking_id, paid_at, value_total, value_commission, value_company, provider, status, coalesce(value_received, 0), coalesce(value_delta, 0)
+from payments
+where provider = $1
+    and created_at >= $2
+    and created_at < $3
+order by created_at
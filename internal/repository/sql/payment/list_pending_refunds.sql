@@ -0,0 +1,7 @@
+select id, correlation_id, booking_id, value_total, value_company, provider, status,
+       coalesce(refund_correlation_id, ''), coalesce(end_to_end_id, ''), coalesce(refunded_value, 0)
+from payments
+where status = 'refunding'
+    and refund_requested_at < now() - make_interval(secs => $1)
+order by refund_requested_at
+limit 100
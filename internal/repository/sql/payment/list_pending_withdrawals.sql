@@ -0,0 +1,8 @@
+select w.id, w.company_id, w.provider, w.correlation_id, w.value, w.status, coalesce(w.failure_reason, ''),
+       w.trigger, w.created_at, w.completed_at, pa.account_id
+from withdrawals w
+join payment_accounts pa on pa.company_id = w.company_id and pa.provider = w.provider
+where w.status = 'pending'
+    and w.created_at < now() - make_interval(secs => $1)
+order by w.created_at
+limit 100
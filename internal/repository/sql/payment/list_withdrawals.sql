@@ -0,0 +1,6 @@
+select id, company_id, provider, correlation_id, value, status, coalesce(failure_reason, ''),
+       trigger, created_at, completed_at
+from withdrawals
+where company_id = $1
+order by created_at desc
+limit 100
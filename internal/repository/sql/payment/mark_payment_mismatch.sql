@@ -0,0 +1,7 @@
+update payments
+set status = 'mismatch',
+    value_received = $2,
+    paid_at = coalesce($3, now()),
+    updated_at = now()
+where correlation_id = $1
+    and status = 'pending'
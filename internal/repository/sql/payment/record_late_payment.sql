@@ -0,0 +1,7 @@
+update payments
+set paid_at = coalesce($2, now()),
+    value_received = $3,
+    value_gateway_fee = $4,
+    updated_at = now()
+where correlation_id = $1
+    and status in ('pending', 'expired')
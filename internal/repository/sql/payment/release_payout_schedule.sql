@@ -0,0 +1,6 @@
+-- Puts back the last payout of a schedule whose payout failed, so the next run
+-- of the job tries again.
+update payout_schedules
+set last_payout_at = $2,
+    updated_at = now()
+where company_id = $1
@@ -0,0 +1,6 @@
+insert into payment_accounts (company_id, provider, account_id)
+values (
+    $1, $2, $3
+)
+on conflict (company_id, provider) do update set
+    account_id = excluded.account_id
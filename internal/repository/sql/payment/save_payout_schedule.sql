@@ -0,0 +1,6 @@
+insert into payout_schedules (company_id, enabled, weekday)
+values ($1, $2, $3)
+on conflict (company_id) do update set
+    enabled = excluded.enabled,
+    weekday = excluded.weekday,
+    updated_at = now()
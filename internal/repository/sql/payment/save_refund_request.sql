@@ -1,16 +1,25 @@
+-- Refunds add up: a series payment is refunded again each time more of its
+-- occurrences are cancelled.
 with upd_payments as (
     update payments set
-        refund_requested_at = $2,
-        refunded_at = $2,
+        refund_requested_at = now(),
+        refunded_at = case when $6::text = 'refunded' then coalesce($2, now()) end,
         end_to_end_id = $3,
-        status = 'refunded'
+        refunded_value = coalesce(refunded_value, 0) + $4,
+        refunded_platform_value = refunded_platform_value + $9,
+        refund_correlation_id = $5,
+        refund_failure_reason = nullif($7, ''),
+        status = $6::text::payment_status,
+        mismatch_resolution = case when status = 'mismatch' then 'refund' else mismatch_resolution end,
+        mismatch_resolved_at = case when status = 'mismatch' then now() else mismatch_resolved_at end
     where booking_id = $1
     returning booking_id
 ), update_bookings as (
     update bookings set
         status = 'cancelled'
     where id = (select booking_id from upd_payments)
-        and status = 'confirmed'
+        and $8::boolean
+        and status in ('confirmed', 'pending')
     returning id
 )
 select 1
@@ -0,0 +1,7 @@
+update withdrawals
+set status = $2,
+    failure_reason = nullif($3, ''),
+    completed_at = case when $2 = 'completed' then coalesce($4, now()) end,
+    updated_at = now()
+where id = $1
+    and status = 'pending'
@@ -0,0 +1,4 @@
+UPDATE user_invitations
+SET accepted_at = now()
+WHERE id = $1
+    AND accepted_at IS NULL
@@ -0,0 +1,4 @@
+SELECT COUNT(*)
+FROM users
+WHERE company_id = $1
+    AND role = 'owner'
@@ -0,0 +1,3 @@
+INSERT INTO users (company_id, name, email, password_hash, role)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, created_at
@@ -0,0 +1,4 @@
+DELETE FROM user_invitations
+WHERE id = $1
+    AND company_id = $2
+    AND accepted_at IS NULL
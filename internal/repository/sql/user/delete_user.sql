@@ -0,0 +1,3 @@
+DELETE FROM users
+WHERE id = $1
+    AND company_id = $2
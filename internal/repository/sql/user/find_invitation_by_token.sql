@@ -0,0 +1,5 @@
+SELECT id, company_id, email, role, invited_by, expires_at, accepted_at, created_at
+FROM user_invitations
+WHERE token_hash = $1
+    AND accepted_at IS NULL
+    AND expires_at > now()
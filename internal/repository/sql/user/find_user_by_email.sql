@@ -0,0 +1,3 @@
+SELECT id, company_id, name, email, password_hash, role, created_at
+FROM users
+WHERE lower(email) = lower($1)
@@ -0,0 +1,4 @@
+SELECT id, company_id, name, email, password_hash, role, created_at
+FROM users
+WHERE id = $1
+    AND company_id = $2
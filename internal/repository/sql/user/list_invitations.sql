@@ -0,0 +1,6 @@
+SELECT id, company_id, email, role, invited_by, expires_at, accepted_at, created_at
+FROM user_invitations
+WHERE company_id = $1
+    AND accepted_at IS NULL
+    AND expires_at > now()
+ORDER BY created_at DESC
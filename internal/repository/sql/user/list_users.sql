@@ -0,0 +1,4 @@
+SELECT id, company_id, name, email, password_hash, role, created_at
+FROM users
+WHERE company_id = $1
+ORDER BY created_at, email
@@ -0,0 +1,11 @@
+-- Inviting an email again replaces its pending invitation.
+INSERT INTO user_invitations (company_id, email, role, token_hash, invited_by, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (company_id, email) WHERE accepted_at IS NULL DO UPDATE
+SET
+    role = excluded.role,
+    token_hash = excluded.token_hash,
+    invited_by = excluded.invited_by,
+    expires_at = excluded.expires_at,
+    created_at = now()
+RETURNING id, created_at
@@ -0,0 +1,4 @@
+UPDATE users
+SET role = $3
+WHERE id = $1
+    AND company_id = $2
@@ -0,0 +1,10 @@
+UPDATE webhook_events SET
+    status = 'processing',
+    attempts = attempts + 1,
+    updated_at = now()
+WHERE
+    id = $1
+    AND (
+        status IN ('pending', 'failed')
+        OR (status = 'processing' AND updated_at < now() - make_interval(secs => $2))
+    )
@@ -0,0 +1,18 @@
+SELECT
+    id,
+    company_id,
+    provider,
+    event_key,
+    event_type,
+    payload,
+    status,
+    attempts,
+    last_error,
+    received_at,
+    processed_at,
+    updated_at
+FROM
+    webhook_events
+WHERE
+    id = $1
+    AND ($3::boolean OR company_id = $2::uuid)
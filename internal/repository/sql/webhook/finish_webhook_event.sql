@@ -0,0 +1,6 @@
+UPDATE webhook_events SET
+    status = $2,
+    last_error = $3,
+    processed_at = CASE WHEN $2 = 'processed'::webhook_event_status THEN now() ELSE processed_at END,
+    updated_at = now()
+WHERE id = $1
@@ -0,0 +1,21 @@
+SELECT
+    id,
+    company_id,
+    provider,
+    event_key,
+    event_type,
+    payload,
+    status,
+    attempts,
+    last_error,
+    received_at,
+    processed_at,
+    updated_at
+FROM
+    webhook_events
+WHERE
+    ($3::boolean OR company_id = $1::uuid)
+    AND ($2::webhook_event_status IS NULL OR status = $2)
+ORDER BY
+    received_at DESC
+LIMIT 200
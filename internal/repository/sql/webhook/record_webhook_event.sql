@@ -0,0 +1,24 @@
+INSERT INTO webhook_events(company_id,
+                           provider,
+                           event_key,
+                           event_type,
+                           payload)
+VALUES ((SELECT p.company_id FROM payments p WHERE p.correlation_id = $5 OR p.refund_correlation_id = $5 LIMIT 1),
+        $1,
+        $2,
+        $3,
+        $4)
+ON CONFLICT (provider, event_key, event_type) DO UPDATE SET
+    updated_at = webhook_events.updated_at
+RETURNING id,
+          company_id,
+          provider,
+          event_key,
+          event_type,
+          payload,
+          status,
+          attempts,
+          last_error,
+          received_at,
+          processed_at,
+          updated_at
@@ -0,0 +1,316 @@
+package repository
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+
+	"github.com/dinizgab/booking-mvp/internal/database"
+	"github.com/dinizgab/booking-mvp/internal/entity"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	//go:embed sql/user/create_user.sql
+	createUserQuery string
+	//go:embed sql/user/find_user_by_email.sql
+	findUserByEmailQuery string
+	//go:embed sql/user/find_user_by_id.sql
+	findUserByIDQuery string
+	//go:embed sql/user/list_users.sql
+	listUsersQuery string
+	//go:embed sql/user/update_user_role.sql
+	updateUserRoleQuery string
+	//go:embed sql/user/delete_user.sql
+	deleteUserQuery string
+	//go:embed sql/user/count_owners.sql
+	countOwnersQuery string
+	//go:embed sql/user/save_invitation.sql
+	saveInvitationQuery string
+	//go:embed sql/user/find_invitation_by_token.sql
+	findInvitationByTokenQuery string
+	//go:embed sql/user/list_invitations.sql
+	listInvitationsQuery string
+	//go:embed sql/user/accept_invitation.sql
+	acceptInvitationQuery string
+	//go:embed sql/user/delete_invitation.sql
+	deleteInvitationQuery string
+)
+
+// uniqueViolationCode is the Postgres error code of unique constraint
+// violations.
+const uniqueViolationCode = "23505"
+
+type UserRepository interface {
+	Create(ctx context.Context, user entity.User) (entity.User, error)
+	FindByEmail(ctx context.Context, email string) (entity.User, error)
+	FindByID(ctx context.Context, companyId string, id string) (entity.User, error)
+	List(ctx context.Context, companyId string) ([]entity.User, error)
+	UpdateRole(ctx context.Context, companyId string, id string, role entity.UserRole) error
+	Delete(ctx context.Context, companyId string, id string) error
+	CountOwners(ctx context.Context, companyId string) (int, error)
+	SaveInvitation(ctx context.Context, invitation entity.Invitation) (entity.Invitation, error)
+	FindInvitationByToken(ctx context.Context, tokenHash string) (entity.Invitation, error)
+	ListInvitations(ctx context.Context, companyId string) ([]entity.Invitation, error)
+	DeleteInvitation(ctx context.Context, companyId string, id string) error
+	// AcceptInvitation creates the user and marks the invitation accepted in
+	// one transaction.
+	AcceptInvitation(ctx context.Context, invitation entity.Invitation, user entity.User) (entity.User, error)
+}
+
+type userRepositoryImpl struct {
+	db database.Database
+}
+
+func NewUserRepository(db database.Database) UserRepository {
+	return &userRepositoryImpl{
+		db: db,
+	}
+}
+
+func (r *userRepositoryImpl) Create(ctx context.Context, user entity.User) (entity.User, error) {
+	user, err := createUser(ctx, r.db.QueryRow, user)
+	if err != nil {
+		return entity.User{}, fmt.Errorf("UserRepository.Create: %w", err)
+	}
+
+	return user, nil
+}
+
+func (r *userRepositoryImpl) FindByEmail(ctx context.Context, email string) (entity.User, error) {
+	user, err := scanUser(r.db.QueryRow(ctx, findUserByEmailQuery, email))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.User{}, entity.ErrUserNotFound
+		}
+		return entity.User{}, fmt.Errorf("UserRepository.FindByEmail: %w", err)
+	}
+
+	return user, nil
+}
+
+func (r *userRepositoryImpl) FindByID(ctx context.Context, companyId string, id string) (entity.User, error) {
+	user, err := scanUser(r.db.QueryRow(ctx, findUserByIDQuery, id, companyId))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.User{}, entity.ErrUserNotFound
+		}
+		return entity.User{}, fmt.Errorf("UserRepository.FindByID: %w", err)
+	}
+
+	return user, nil
+}
+
+func (r *userRepositoryImpl) List(ctx context.Context, companyId string) ([]entity.User, error) {
+	rows, err := r.db.Query(ctx, listUsersQuery, companyId)
+	if err != nil {
+		return nil, fmt.Errorf("UserRepository.List: %w", err)
+	}
+	defer rows.Close()
+
+	users := make([]entity.User, 0)
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("UserRepository.List: %w", err)
+		}
+		user.Password = ""
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("UserRepository.List: %w", err)
+	}
+
+	return users, nil
+}
+
+func (r *userRepositoryImpl) UpdateRole(ctx context.Context, companyId string, id string, role entity.UserRole) error {
+	tag, err := r.db.Exec(ctx, updateUserRoleQuery, id, companyId, string(role))
+	if err != nil {
+		return fmt.Errorf("UserRepository.UpdateRole: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return entity.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (r *userRepositoryImpl) Delete(ctx context.Context, companyId string, id string) error {
+	tag, err := r.db.Exec(ctx, deleteUserQuery, id, companyId)
+	if err != nil {
+		return fmt.Errorf("UserRepository.Delete: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return entity.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (r *userRepositoryImpl) CountOwners(ctx context.Context, companyId string) (int, error) {
+	var count int
+	if err := r.db.QueryRow(ctx, countOwnersQuery, companyId).Scan(&count); err != nil {
+		return 0, fmt.Errorf("UserRepository.CountOwners: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *userRepositoryImpl) SaveInvitation(ctx context.Context, invitation entity.Invitation) (entity.Invitation, error) {
+	err := r.db.QueryRow(
+		ctx,
+		saveInvitationQuery,
+		invitation.CompanyID,
+		invitation.Email,
+		string(invitation.Role),
+		invitation.TokenHash,
+		nullableString(invitation.InvitedBy),
+		invitation.ExpiresAt,
+	).Scan(&invitation.ID, &invitation.CreatedAt)
+	if err != nil {
+		return entity.Invitation{}, fmt.Errorf("UserRepository.SaveInvitation: %w", err)
+	}
+
+	return invitation, nil
+}
+
+func (r *userRepositoryImpl) FindInvitationByToken(ctx context.Context, tokenHash string) (entity.Invitation, error) {
+	invitation, err := scanInvitation(r.db.QueryRow(ctx, findInvitationByTokenQuery, tokenHash))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.Invitation{}, entity.ErrInvitationNotFound
+		}
+		return entity.Invitation{}, fmt.Errorf("UserRepository.FindInvitationByToken: %w", err)
+	}
+
+	return invitation, nil
+}
+
+func (r *userRepositoryImpl) ListInvitations(ctx context.Context, companyId string) ([]entity.Invitation, error) {
+	rows, err := r.db.Query(ctx, listInvitationsQuery, companyId)
+	if err != nil {
+		return nil, fmt.Errorf("UserRepository.ListInvitations: %w", err)
+	}
+	defer rows.Close()
+
+	invitations := make([]entity.Invitation, 0)
+	for rows.Next() {
+		invitation, err := scanInvitation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("UserRepository.ListInvitations: %w", err)
+		}
+		invitations = append(invitations, invitation)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("UserRepository.ListInvitations: %w", err)
+	}
+
+	return invitations, nil
+}
+
+func (r *userRepositoryImpl) DeleteInvitation(ctx context.Context, companyId string, id string) error {
+	tag, err := r.db.Exec(ctx, deleteInvitationQuery, id, companyId)
+	if err != nil {
+		return fmt.Errorf("UserRepository.DeleteInvitation: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return entity.ErrInvitationNotFound
+	}
+
+	return nil
+}
+
+func (r *userRepositoryImpl) AcceptInvitation(ctx context.Context, invitation entity.Invitation, user entity.User) (entity.User, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return entity.User{}, fmt.Errorf("UserRepository.AcceptInvitation: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	tag, err := tx.Exec(ctx, acceptInvitationQuery, invitation.ID)
+	if err != nil {
+		return entity.User{}, fmt.Errorf("UserRepository.AcceptInvitation: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return entity.User{}, entity.ErrInvitationNotFound
+	}
+
+	user, err = createUser(ctx, tx.QueryRow, user)
+	if err != nil {
+		return entity.User{}, fmt.Errorf("UserRepository.AcceptInvitation: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return entity.User{}, fmt.Errorf("UserRepository.AcceptInvitation: %w", err)
+	}
+
+	return user, nil
+}
+
+// createUser inserts the user with queryRow, from the pool or a transaction.
+// Emails taken by another user are reported as entity.ErrEmailAlreadyInUse.
+func createUser(ctx context.Context, queryRow func(context.Context, string, ...any) pgx.Row, user entity.User) (entity.User, error) {
+	err := queryRow(
+		ctx,
+		createUserQuery,
+		user.CompanyID,
+		user.Name,
+		user.Email,
+		user.Password,
+		string(user.Role),
+	).Scan(&user.ID, &user.CreatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return entity.User{}, entity.ErrEmailAlreadyInUse
+		}
+		return entity.User{}, err
+	}
+
+	user.Password = ""
+	return user, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode
+}
+
+func scanUser(row pgx.Row) (entity.User, error) {
+	var user entity.User
+	err := row.Scan(
+		&user.ID,
+		&user.CompanyID,
+		&user.Name,
+		&user.Email,
+		&user.Password,
+		&user.Role,
+		&user.CreatedAt,
+	)
+
+	return user, err
+}
+
+func scanInvitation(row pgx.Row) (entity.Invitation, error) {
+	var invitation entity.Invitation
+	var invitedBy *string
+	err := row.Scan(
+		&invitation.ID,
+		&invitation.CompanyID,
+		&invitation.Email,
+		&invitation.Role,
+		&invitedBy,
+		&invitation.ExpiresAt,
+		&invitation.AcceptedAt,
+		&invitation.CreatedAt,
+	)
+	if invitedBy != nil {
+		invitation.InvitedBy = *invitedBy
+	}
+
+	return invitation, err
+}
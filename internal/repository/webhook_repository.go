@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dinizgab/booking-mvp/internal/database"
+	"github.com/dinizgab/booking-mvp/internal/entity"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	//go:embed sql/webhook/record_webhook_event.sql
+	recordWebhookEventQuery string
+	//go:embed sql/webhook/claim_webhook_event.sql
+	claimWebhookEventQuery string
+	//go:embed sql/webhook/finish_webhook_event.sql
+	finishWebhookEventQuery string
+	//go:embed sql/webhook/find_webhook_event_by_id.sql
+	findWebhookEventByIDQuery string
+	//go:embed sql/webhook/list_webhook_events.sql
+	listWebhookEventsQuery string
+)
+
+type WebhookEventRepository interface {
+	Record(ctx context.Context, event entity.WebhookEvent, correlationId string) (entity.WebhookEvent, error)
+	Claim(ctx context.Context, id string, staleAfter time.Duration) (bool, error)
+	Finish(ctx context.Context, id string, status entity.WebhookEventStatus, lastError string) error
+	FindByID(ctx context.Context, companyId string, id string) (entity.WebhookEvent, error)
+	List(ctx context.Context, companyId string, status *entity.WebhookEventStatus) ([]entity.WebhookEvent, error)
+	// FindAnyByID and ListAll look at the events of every company. They are
+	// meant for platform operators.
+	FindAnyByID(ctx context.Context, id string) (entity.WebhookEvent, error)
+	ListAll(ctx context.Context, status *entity.WebhookEventStatus) ([]entity.WebhookEvent, error)
+}
+
+type webhookEventRepositoryImpl struct {
+	db database.Database
+}
+
+func NewWebhookEventRepository(db database.Database) WebhookEventRepository {
+	return &webhookEventRepositoryImpl{
+		db: db,
+	}
+}
+
+// Record stores the event, or returns the stored one when the same event was
+// already received. The company is resolved from the payment with the given
+// correlation id.
+func (r *webhookEventRepositoryImpl) Record(ctx context.Context, event entity.WebhookEvent, correlationId string) (entity.WebhookEvent, error) {
+	row := r.db.QueryRow(
+		ctx,
+		recordWebhookEventQuery,
+		event.Provider,
+		event.EventKey,
+		event.EventType,
+		[]byte(event.Payload),
+		correlationId,
+	)
+
+	recorded, err := scanWebhookEvent(row)
+	if err != nil {
+		return entity.WebhookEvent{}, fmt.Errorf("WebhookEventRepository.Record: %w", err)
+	}
+
+	return recorded, nil
+}
+
+// Claim moves the event to processing and counts the attempt. It reports
+// false when the event is already processed or being processed elsewhere,
+// unless that processing went stale.
+func (r *webhookEventRepositoryImpl) Claim(ctx context.Context, id string, staleAfter time.Duration) (bool, error) {
+	tag, err := r.db.Exec(ctx, claimWebhookEventQuery, id, staleAfter.Seconds())
+	if err != nil {
+		return false, fmt.Errorf("WebhookEventRepository.Claim: %w", err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *webhookEventRepositoryImpl) Finish(ctx context.Context, id string, status entity.WebhookEventStatus, lastError string) error {
+	_, err := r.db.Exec(ctx, finishWebhookEventQuery, id, status, lastError)
+	if err != nil {
+		return fmt.Errorf("WebhookEventRepository.Finish: %w", err)
+	}
+
+	return nil
+}
+
+func (r *webhookEventRepositoryImpl) FindByID(ctx context.Context, companyId string, id string) (entity.WebhookEvent, error) {
+	return r.findByID(ctx, nullableString(companyId), id, false)
+}
+
+func (r *webhookEventRepositoryImpl) FindAnyByID(ctx context.Context, id string) (entity.WebhookEvent, error) {
+	return r.findByID(ctx, nil, id, true)
+}
+
+func (r *webhookEventRepositoryImpl) findByID(ctx context.Context, companyId *string, id string, allCompanies bool) (entity.WebhookEvent, error) {
+	event, err := scanWebhookEvent(r.db.QueryRow(ctx, findWebhookEventByIDQuery, id, companyId, allCompanies))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.WebhookEvent{}, entity.ErrWebhookEventNotFound
+		}
+		return entity.WebhookEvent{}, fmt.Errorf("WebhookEventRepository.FindByID: %w", err)
+	}
+
+	return event, nil
+}
+
+func (r *webhookEventRepositoryImpl) List(ctx context.Context, companyId string, status *entity.WebhookEventStatus) ([]entity.WebhookEvent, error) {
+	return r.list(ctx, nullableString(companyId), status, false)
+}
+
+func (r *webhookEventRepositoryImpl) ListAll(ctx context.Context, status *entity.WebhookEventStatus) ([]entity.WebhookEvent, error) {
+	return r.list(ctx, nil, status, true)
+}
+
+func (r *webhookEventRepositoryImpl) list(ctx context.Context, companyId *string, status *entity.WebhookEventStatus, allCompanies bool) ([]entity.WebhookEvent, error) {
+	rows, err := r.db.Query(ctx, listWebhookEventsQuery, companyId, status, allCompanies)
+	if err != nil {
+		return nil, fmt.Errorf("WebhookEventRepository.List: %w", err)
+	}
+	defer rows.Close()
+
+	events := make([]entity.WebhookEvent, 0)
+	for rows.Next() {
+		event, err := scanWebhookEvent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("WebhookEventRepository.List: %w", err)
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("WebhookEventRepository.List: %w", err)
+	}
+
+	return events, nil
+}
+
+func scanWebhookEvent(row pgx.Row) (entity.WebhookEvent, error) {
+	var event entity.WebhookEvent
+	var payload []byte
+	err := row.Scan(
+		&event.ID,
+		&event.CompanyId,
+		&event.Provider,
+		&event.EventKey,
+		&event.EventType,
+		&payload,
+		&event.Status,
+		&event.Attempts,
+		&event.LastError,
+		&event.ReceivedAt,
+		&event.ProcessedAt,
+		&event.UpdatedAt,
+	)
+	if err != nil {
+		return entity.WebhookEvent{}, err
+	}
+	event.Payload = json.RawMessage(payload)
+
+	return event, nil
+}
@@ -0,0 +1,138 @@
+package pricing
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/dinizgab/booking-mvp/internal/entity"
+)
+
+// Engine computes booking prices from the court hourly price and its
+// pricing rules.
+type Engine interface {
+	Quote(court entity.Court, start time.Time, end time.Time) entity.PriceQuote
+}
+
+type ruleEngine struct{}
+
+func NewEngine() Engine {
+	return &ruleEngine{}
+}
+
+// Quote splits the interval at every rule boundary it crosses and charges each
+// part at the rate of the winning rule, or at the court hourly price when no
+// rule applies.
+func (e *ruleEngine) Quote(court entity.Court, start time.Time, end time.Time) entity.PriceQuote {
+	quote := entity.PriceQuote{
+		CourtId:   court.ID,
+		StartTime: start,
+		EndTime:   end,
+		Segments:  make([]entity.PriceSegment, 0),
+	}
+	if !end.After(start) {
+		return quote
+	}
+
+	boundaries := ruleBoundaries(court.PricingRules, start, end)
+	for i := 0; i+1 < len(boundaries); i++ {
+		from, to := boundaries[i], boundaries[i+1]
+		rate, ruleId := rateAt(court, from)
+
+		last := len(quote.Segments) - 1
+		if last >= 0 && quote.Segments[last].HourlyPrice == rate && sameRule(quote.Segments[last].RuleId, ruleId) {
+			quote.Segments[last].EndTime = to
+			continue
+		}
+
+		quote.Segments = append(quote.Segments, entity.PriceSegment{
+			StartTime:   from,
+			EndTime:     to,
+			HourlyPrice: rate,
+			RuleId:      ruleId,
+		})
+	}
+
+	for i := range quote.Segments {
+		segment := &quote.Segments[i]
+		segment.Price = int64(math.Round(float64(segment.HourlyPrice) * segment.EndTime.Sub(segment.StartTime).Hours()))
+		quote.CourtPrice += segment.Price
+	}
+	quote.Total = quote.CourtPrice
+
+	return quote
+}
+
+// ruleBoundaries returns the sorted instants inside [start, end] where the
+// applicable rules may change. The day before start is included so windows
+// running past midnight are taken into account.
+func ruleBoundaries(rules []entity.PricingRule, start time.Time, end time.Time) []time.Time {
+	boundaries := []time.Time{start, end}
+
+	local := start.In(entity.CourtLocation)
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, entity.CourtLocation).AddDate(0, 0, -1)
+	for ; day.Before(end); day = day.AddDate(0, 0, 1) {
+		for _, rule := range rules {
+			if !rule.AppliesOn(day) {
+				continue
+			}
+
+			from, to := rule.Window(day)
+			for _, t := range []time.Time{from, to} {
+				if t.After(start) && t.Before(end) {
+					boundaries = append(boundaries, t)
+				}
+			}
+		}
+	}
+
+	sort.Slice(boundaries, func(i, j int) bool { return boundaries[i].Before(boundaries[j]) })
+
+	unique := boundaries[:1]
+	for _, t := range boundaries[1:] {
+		if !t.Equal(unique[len(unique)-1]) {
+			unique = append(unique, t)
+		}
+	}
+
+	return unique
+}
+
+// rateAt returns the hourly price charged at instant t and the rule it comes
+// from, if any.
+func rateAt(court entity.Court, t time.Time) (int64, *string) {
+	var best *entity.PricingRule
+	local := t.In(entity.CourtLocation)
+	for i := range court.PricingRules {
+		rule := &court.PricingRules[i]
+		for _, day := range []time.Time{local, local.AddDate(0, 0, -1)} {
+			if !rule.AppliesOn(day) {
+				continue
+			}
+
+			from, to := rule.Window(day)
+			if t.Before(from) || !t.Before(to) {
+				continue
+			}
+
+			if best == nil || rule.Outranks(*best) {
+				best = rule
+			}
+		}
+	}
+
+	if best == nil {
+		return court.HourlyPrice, nil
+	}
+
+	id := best.ID
+	return best.HourlyPrice, &id
+}
+
+func sameRule(a *string, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
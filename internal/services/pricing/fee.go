@@ -0,0 +1,36 @@
+package pricing
+
+import "github.com/dinizgab/booking-mvp/internal/entity"
+
+// FeeCalculator computes the platform commission of a booking from the
+// company commission plan. Quotes and charges share it so both always agree.
+type FeeCalculator interface {
+	Commission(plan entity.CommissionPlan, courtPrice int64) entity.Commission
+}
+
+type planFeeCalculator struct{}
+
+func NewFeeCalculator() FeeCalculator {
+	return &planFeeCalculator{}
+}
+
+// Commission takes the plan percentage of the court price, rounded to the
+// nearest cent, plus its fixed fee. A commission paid by the company never
+// exceeds the court price.
+func (f *planFeeCalculator) Commission(plan entity.CommissionPlan, courtPrice int64) entity.Commission {
+	fee := (courtPrice*plan.PercentBps+5000)/10000 + plan.FixedFee
+
+	if plan.PaidBy == entity.CommissionPaidByCompany {
+		return entity.Commission{
+			Total:  courtPrice,
+			Fee:    min(fee, courtPrice),
+			PaidBy: entity.CommissionPaidByCompany,
+		}
+	}
+
+	return entity.Commission{
+		Total:  courtPrice + fee,
+		Fee:    fee,
+		PaidBy: entity.CommissionPaidByGuest,
+	}
+}
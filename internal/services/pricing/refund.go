@@ -0,0 +1,54 @@
+package pricing
+
+import (
+	"time"
+
+	"github.com/dinizgab/booking-mvp/internal/entity"
+)
+
+// RefundCalculator decides how much of a payment goes back to the guest when
+// the booking is cancelled.
+type RefundCalculator interface {
+	Refund(policy entity.CancellationPolicy, payment entity.Payment, start time.Time, now time.Time) (entity.RefundDecision, error)
+}
+
+type refundCalculatorImpl struct{}
+
+func NewRefundCalculator() RefundCalculator {
+	return &refundCalculatorImpl{}
+}
+
+// Refund applies the policy tier matching the notice given. The percentage is
+// taken from the court price, plus the platform fee when the guest paid it and
+// the policy refunds it.
+func (c *refundCalculatorImpl) Refund(policy entity.CancellationPolicy, payment entity.Payment, start time.Time, now time.Time) (entity.RefundDecision, error) {
+	if !now.Before(start) {
+		return entity.RefundDecision{}, entity.ErrCancellationClosed
+	}
+
+	percent := policy.RefundPercent(start, now)
+
+	base := payment.ValueCompany
+	if policy.RefundPlatformFee || payment.CommissionPaidBy == entity.CommissionPaidByCompany {
+		base = payment.ValueTotal
+	}
+
+	decision := entity.RefundDecision{
+		Amount:  base * int64(percent) / 100,
+		Percent: percent,
+	}
+	if policy.RefundPlatformFee {
+		decision.PlatformAmount = payment.ValueCommission * int64(percent) / 100
+	}
+
+	switch {
+	case decision.Amount == 0:
+		decision.Reason = entity.RefundReasonNone
+	case percent == 100:
+		decision.Reason = entity.RefundReasonFull
+	default:
+		decision.Reason = entity.RefundReasonPartial
+	}
+
+	return decision, nil
+}
@@ -0,0 +1,137 @@
+// Package statement renders company statements in the formats accountants
+// import: CSV spreadsheets and OFX bank statements.
+package statement
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dinizgab/booking-mvp/internal/entity"
+)
+
+var descriptions = map[string]string{
+	entity.LedgerCharge:           "Pagamento de reserva",
+	entity.LedgerCommission:       "Comissão da plataforma",
+	entity.LedgerGatewayFee:       "Tarifa do meio de pagamento",
+	entity.LedgerRefund:           "Reembolso ao cliente",
+	entity.LedgerCommissionRefund: "Estorno da comissão da plataforma",
+	entity.LedgerWithdrawal:       "Saque para conta bancária",
+}
+
+// Description returns the Portuguese label of the entry type.
+func Description(entryType string) string {
+	if description, ok := descriptions[entryType]; ok {
+		return description
+	}
+
+	return entryType
+}
+
+// WriteCSV writes one row per entry with amounts in reais, signed by their
+// effect on the company balance.
+func WriteCSV(w io.Writer, s entity.Statement) error {
+	out := csv.NewWriter(w)
+
+	rows := [][]string{
+		{"data", "tipo", "descricao", "referencia", "reserva", "debito", "credito", "valor", "saldo"},
+		{formatDate(s.From), "", "Saldo anterior", "", "", "", "", "", formatAmount(s.OpeningBalance)},
+	}
+	for _, entry := range s.Entries {
+		rows = append(rows, []string{
+			entry.OccurredAt.In(entity.CourtLocation).Format("2006-01-02 15:04:05"),
+			entry.Type,
+			Description(entry.Type),
+			entry.ReferenceID,
+			entry.BookingID,
+			entry.Debit,
+			entry.Credit,
+			formatAmount(entry.CompanyEffect()),
+			formatAmount(entry.Balance),
+		})
+	}
+
+	if err := out.WriteAll(rows); err != nil {
+		return fmt.Errorf("statement.WriteCSV: %w", err)
+	}
+
+	return nil
+}
+
+// WriteOFX writes the statement as an OFX 1.02 bank statement in BRL, the
+// version most Brazilian accounting software imports.
+func WriteOFX(w io.Writer, s entity.Statement) error {
+	var b strings.Builder
+
+	b.WriteString("OFXHEADER:100\nDATA:OFXSGML\nVERSION:102\nSECURITY:NONE\nENCODING:UTF-8\nCHARSET:NONE\nCOMPRESSION:NONE\nOLDFILEUID:NONE\nNEWFILEUID:NONE\n\n")
+	b.WriteString("<OFX>\n")
+	b.WriteString("<SIGNONMSGSRSV1><SONRS><STATUS><CODE>0<SEVERITY>INFO</STATUS>")
+	fmt.Fprintf(&b, "<DTSERVER>%s<LANGUAGE>POR</SONRS></SIGNONMSGSRSV1>\n", formatOFXTime(s.To))
+	b.WriteString("<BANKMSGSRSV1><STMTTRNRS><TRNUID>1<STATUS><CODE>0<SEVERITY>INFO</STATUS>\n")
+	b.WriteString("<STMTRS><CURDEF>BRL\n")
+	fmt.Fprintf(&b, "<BANKACCTFROM><BANKID>COURTLY<ACCTID>%s<ACCTTYPE>CHECKING</BANKACCTFROM>\n", ofxText(s.CompanyID))
+	fmt.Fprintf(&b, "<BANKTRANLIST><DTSTART>%s<DTEND>%s\n", formatOFXTime(s.From), formatOFXTime(s.To))
+	for _, entry := range s.Entries {
+		memo := Description(entry.Type)
+		if entry.BookingID != "" {
+			memo += " " + entry.BookingID
+		}
+
+		b.WriteString("<STMTTRN>")
+		fmt.Fprintf(&b, "<TRNTYPE>%s", ofxTransactionType(entry))
+		fmt.Fprintf(&b, "<DTPOSTED>%s", formatOFXTime(entry.OccurredAt))
+		fmt.Fprintf(&b, "<TRNAMT>%s", formatAmount(entry.CompanyEffect()))
+		fmt.Fprintf(&b, "<FITID>%s", ofxText(entry.ID))
+		fmt.Fprintf(&b, "<MEMO>%s", ofxText(memo))
+		b.WriteString("</STMTTRN>\n")
+	}
+	b.WriteString("</BANKTRANLIST>\n")
+	fmt.Fprintf(&b, "<LEDGERBAL><BALAMT>%s<DTASOF>%s</LEDGERBAL>\n", formatAmount(s.ClosingBalance), formatOFXTime(s.To))
+	b.WriteString("</STMTRS></STMTTRNRS></BANKMSGSRSV1>\n</OFX>\n")
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("statement.WriteOFX: %w", err)
+	}
+
+	return nil
+}
+
+func ofxTransactionType(entry entity.LedgerEntry) string {
+	switch entry.Type {
+	case entity.LedgerCharge, entity.LedgerCommissionRefund:
+		return "CREDIT"
+	case entity.LedgerCommission, entity.LedgerGatewayFee:
+		return "FEE"
+	case entity.LedgerWithdrawal:
+		return "XFER"
+	}
+
+	return "DEBIT"
+}
+
+// ofxText escapes the characters SGML treats as markup.
+func ofxText(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// formatAmount writes cents as reais with a dot separator, e.g. -12.50.
+func formatAmount(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+
+	return fmt.Sprintf("%s%s.%02d", sign, strconv.FormatInt(cents/100, 10), cents%100)
+}
+
+func formatDate(t time.Time) string {
+	return t.In(entity.CourtLocation).Format("2006-01-02")
+}
+
+func formatOFXTime(t time.Time) string {
+	return t.In(entity.CourtLocation).Format("20060102150405") + "[-3:BRT]"
+}
@@ -0,0 +1,51 @@
+package statement
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dinizgab/booking-mvp/internal/entity"
+)
+
+func TestWriteOFXTransactionTypes(t *testing.T) {
+	tests := []struct {
+		entryType string
+		debit     string
+		credit    string
+		want      string
+	}{
+		{entity.LedgerCharge, entity.LedgerAccountGuest, entity.LedgerAccountCompany, "<TRNTYPE>CREDIT<DTPOSTED>20260301100000[-3:BRT]<TRNAMT>110.00"},
+		{entity.LedgerCommission, entity.LedgerAccountCompany, entity.LedgerAccountPlatform, "<TRNTYPE>FEE<DTPOSTED>20260301100000[-3:BRT]<TRNAMT>-110.00"},
+		{entity.LedgerRefund, entity.LedgerAccountCompany, entity.LedgerAccountGuest, "<TRNTYPE>DEBIT<DTPOSTED>20260301100000[-3:BRT]<TRNAMT>-110.00"},
+		{entity.LedgerCommissionRefund, entity.LedgerAccountPlatform, entity.LedgerAccountCompany, "<TRNTYPE>CREDIT<DTPOSTED>20260301100000[-3:BRT]<TRNAMT>110.00"},
+		{entity.LedgerWithdrawal, entity.LedgerAccountCompany, entity.LedgerAccountBank, "<TRNTYPE>XFER<DTPOSTED>20260301100000[-3:BRT]<TRNAMT>-110.00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.entryType, func(t *testing.T) {
+			occurredAt := time.Date(2026, 3, 1, 13, 0, 0, 0, time.UTC)
+			s := entity.Statement{
+				CompanyID: "company-1",
+				From:      occurredAt.Add(-24 * time.Hour),
+				To:        occurredAt.Add(24 * time.Hour),
+				Entries: []entity.LedgerEntry{{
+					ID:         "payment-1:" + tt.entryType,
+					OccurredAt: occurredAt,
+					Type:       tt.entryType,
+					Debit:      tt.debit,
+					Credit:     tt.credit,
+					Amount:     11000,
+				}},
+			}
+
+			var out strings.Builder
+			if err := WriteOFX(&out, s); err != nil {
+				t.Fatalf("WriteOFX: %v", err)
+			}
+			if !strings.Contains(out.String(), tt.want) {
+				t.Errorf("WriteOFX() = %s, want a transaction with %s", out.String(), tt.want)
+			}
+		})
+	}
+}
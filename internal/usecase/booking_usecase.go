@@ -3,16 +3,18 @@ package usecase
 import (
 	"context"
 	"fmt"
-	"math"
+	"log"
 	"time"
 
 	"github.com/dinizgab/booking-mvp/internal/entity"
 	"github.com/dinizgab/booking-mvp/internal/repository"
+	"github.com/dinizgab/booking-mvp/internal/services/pricing"
 )
 
 type (
 	BookingUsecase interface {
 		Create(ctx context.Context, booking entity.Booking) (string, error)
+		CreateManual(ctx context.Context, companyId string, booking entity.Booking) (string, error)
 		FindByID(ctx context.Context, id string) (entity.Booking, error)
 		FindByIDShowcase(ctx context.Context, id string) (entity.Booking, error)
 		ListByCompanyID(ctx context.Context, companyId string, filter entity.BookingFilter) ([]entity.Booking, error)
@@ -20,6 +22,13 @@ type (
 		CancelBooking(ctx context.Context, bookingId string, cancelToken string) error
 		Update(ctx context.Context, booking entity.Booking) error
 		Delete(ctx context.Context, id string) error
+		ReleaseExpiredHolds(ctx context.Context) (int64, error)
+		CreateSeries(ctx context.Context, companyId string, series entity.BookingSeries) (entity.SeriesCreationResult, error)
+		FindSeriesByID(ctx context.Context, companyId string, id string) (entity.BookingSeries, error)
+		CancelSeries(ctx context.Context, companyId string, id string) error
+		CancelSeriesOccurrence(ctx context.Context, companyId string, seriesId string, bookingId string) error
+		CancelByCompany(ctx context.Context, companyId string, bookingId string, cancellation entity.CompanyCancellation) (entity.CancellationResult, error)
+		CancelCourtBookings(ctx context.Context, companyId string, courtId string, cancellation entity.CourtCancellation) ([]entity.CancellationResult, error)
 	}
 
 	bookingUsecaseImpl struct {
@@ -27,6 +36,7 @@ type (
 		paymentUsecase    PaymentUsecase
 		companyUsecase    CompanyUsecase
 		courtUsecase      CourtUseCase
+		pricingEngine     pricing.Engine
 	}
 )
 
@@ -35,17 +45,20 @@ func NewBookingUsecase(
 	paymentUsecase PaymentUsecase,
 	companyUsecase CompanyUsecase,
 	courtUsecase CourtUseCase,
+	pricingEngine pricing.Engine,
 ) BookingUsecase {
 	return &bookingUsecaseImpl{
 		bookingRepository: bookingRepository,
 		paymentUsecase:    paymentUsecase,
 		companyUsecase:    companyUsecase,
 		courtUsecase:      courtUsecase,
+		pricingEngine:     pricingEngine,
 	}
 }
 
 func (u *bookingUsecaseImpl) Create(ctx context.Context, booking entity.Booking) (string, error) {
 	booking.Status = entity.StatusPending
+	booking.PaymentMethod = entity.PaymentMethodPix
 	booking.VerificationCode = entity.GenerateVerificationCode()
 
 	court, err := u.courtUsecase.FindByID(ctx, booking.CourtId)
@@ -53,8 +66,26 @@ func (u *bookingUsecaseImpl) Create(ctx context.Context, booking entity.Booking)
 		return "", err
 	}
 
-	total := float64(court.HourlyPrice) * booking.DurationInHours()
-	booking.TotalPrice = int64(math.Round(total))
+	if err := u.companyUsecase.CheckActive(ctx, court.CompanyId); err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	if err := court.ValidateBooking(booking, now); err != nil {
+		return "", err
+	}
+
+	if err := court.Policy().CheckWindow(booking.StartTime, now); err != nil {
+		return "", err
+	}
+
+	if err := u.checkBlackouts(ctx, booking); err != nil {
+		return "", err
+	}
+
+	booking.TotalPrice = u.bookingPrice(court, booking)
+	booking.HoldExpiresAt = time.Now().Add(entity.BookingHoldDuration)
+	booking.Court = &court
 
 	id, err := u.bookingRepository.Create(ctx, booking)
 	if err != nil {
@@ -64,6 +95,47 @@ func (u *bookingUsecaseImpl) Create(ctx context.Context, booking entity.Booking)
 	booking.ID = id
 
 	err = u.paymentUsecase.CreateCharge(ctx, court.CompanyId, booking)
+	if err != nil {
+		if cancelErr := u.bookingRepository.CancelBooking(ctx, id); cancelErr != nil {
+			log.Printf("BookingUsecase.Create - failed to release booking %s after charge error: %v", id, cancelErr)
+		}
+
+		return "", err
+	}
+
+	return id, nil
+}
+
+// CreateManual books a court for a walk-in or phone customer who pays at the
+// front desk, so the booking is confirmed right away and no charge is created.
+func (u *bookingUsecaseImpl) CreateManual(ctx context.Context, companyId string, booking entity.Booking) (string, error) {
+	if !booking.PaymentMethod.IsManual() {
+		return "", entity.ErrInvalidPaymentMethod
+	}
+
+	court, err := u.courtUsecase.FindByID(ctx, booking.CourtId)
+	if err != nil {
+		return "", err
+	}
+
+	if court.CompanyId != companyId {
+		return "", entity.ErrCourtNotFound
+	}
+
+	if err := court.ValidateBooking(booking, time.Now()); err != nil {
+		return "", err
+	}
+
+	if err := u.checkBlackouts(ctx, booking); err != nil {
+		return "", err
+	}
+
+	booking.Status = entity.StatusConfirmed
+	booking.VerificationCode = entity.GenerateVerificationCode()
+	booking.TotalPrice = u.bookingPrice(court, booking)
+	booking.Court = &court
+
+	id, err := u.bookingRepository.Create(ctx, booking)
 	if err != nil {
 		return "", err
 	}
@@ -130,23 +202,43 @@ func (u *bookingUsecaseImpl) CancelBooking(ctx context.Context, bookingId string
 		return fmt.Errorf("BookingUsecase.CancelBooking - Invalid cancel token")
 	}
 
-	orig := time.Now()
-	now := time.Date(
-		orig.Year(), orig.Month(), orig.Day(),
-		orig.Hour(), orig.Minute(), orig.Second(), orig.Nanosecond(),
-		time.UTC,
-	)
-	expires := booking.CancelTokenHashExpiresAt.UTC()
-	if now.After(expires) {
-		return fmt.Errorf("BookingUsecase.CancelBooking - The time to cancel the booking has expired")
+	if booking.Status == entity.StatusCancelled {
+		return entity.ErrBookingAlreadyCancelled
+	}
+
+	policy, err := u.companyUsecase.GetCancellationPolicy(ctx, booking.Court.CompanyId)
+	if err != nil {
+		return err
 	}
 
-	err = u.paymentUsecase.RefundCharge(ctx, bookingId)
+	booking.ID = bookingId
+	decision, err := u.refundBooking(ctx, booking.Court.CompanyId, booking, policy)
 	if err != nil {
 		return err
 	}
+	log.Printf("BookingUsecase.CancelBooking - booking %s cancelled with %s of %d cents", bookingId, decision.Reason, decision.Amount)
+
+	// Refunds come with their own email. Without one the guest is told the
+	// booking was cancelled with nothing to get back.
+	messages := make([]entity.OutboxMessage, 0, 1)
+	if decision.Amount == 0 {
+		summary, err := u.bookingRepository.GetBookingSummary(ctx, bookingId)
+		if err != nil {
+			return err
+		}
+
+		if summary.GuestEmail != "" {
+			info := bookingEmailInfo(bookingId, summary)
+			info.RefundAmount = formatCents(0)
+			message, err := entity.NewBookingEmail(bookingCancellationTemplateName, bookingCancellationEmailSubject, info)
+			if err != nil {
+				return err
+			}
+			messages = append(messages, message)
+		}
+	}
 
-	err = u.bookingRepository.CancelBooking(ctx, bookingId)
+	err = u.bookingRepository.CancelBooking(ctx, bookingId, messages...)
 	if err != nil {
 		return err
 	}
@@ -171,3 +263,410 @@ func (u *bookingUsecaseImpl) Delete(ctx context.Context, id string) error {
 
 	return nil
 }
+
+func (u *bookingUsecaseImpl) ReleaseExpiredHolds(ctx context.Context) (int64, error) {
+	released, err := u.bookingRepository.ReleaseExpiredHolds(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return released, nil
+}
+
+// CreateSeries books every occurrence of a recurring series on a court of the
+// company. The booking window of the court applies to the first occurrence;
+// the later ones are booked ahead on purpose.
+func (u *bookingUsecaseImpl) CreateSeries(ctx context.Context, companyId string, series entity.BookingSeries) (entity.SeriesCreationResult, error) {
+	now := time.Now()
+	if err := series.Validate(now); err != nil {
+		return entity.SeriesCreationResult{}, err
+	}
+
+	court, err := u.courtUsecase.FindByID(ctx, series.CourtId)
+	if err != nil {
+		return entity.SeriesCreationResult{}, err
+	}
+
+	if court.CompanyId != companyId {
+		return entity.SeriesCreationResult{}, entity.ErrCourtNotFound
+	}
+
+	if err := u.companyUsecase.CheckActive(ctx, court.CompanyId); err != nil {
+		return entity.SeriesCreationResult{}, err
+	}
+
+	if !court.IsActive {
+		return entity.SeriesCreationResult{}, entity.ErrCourtInactive
+	}
+
+	series.CompanyId = court.CompanyId
+	series.Status = entity.SeriesActive
+
+	holdExpiresAt := now.Add(entity.BookingHoldDuration)
+	seriesHoldExpiresAt := now.Add(entity.SeriesHoldDuration)
+	occurrences := series.Occurrences()
+	if err := court.Policy().CheckWindow(occurrences[0].StartTime, now); err != nil {
+		return entity.SeriesCreationResult{}, err
+	}
+
+	for i := range occurrences {
+		occurrences[i].Status = entity.StatusPending
+		occurrences[i].PaymentMethod = entity.PaymentMethodPix
+		occurrences[i].VerificationCode = entity.GenerateVerificationCode()
+		occurrences[i].TotalPrice = u.bookingPrice(court, occurrences[i])
+		occurrences[i].Court = &court
+
+		// Per occurrence charges stay payable until the occurrence starts, for
+		// SeriesHoldDuration at most; upfront charges follow the regular
+		// booking hold.
+		occurrences[i].HoldExpiresAt = occurrences[i].StartTime
+		if occurrences[i].HoldExpiresAt.After(seriesHoldExpiresAt) {
+			occurrences[i].HoldExpiresAt = seriesHoldExpiresAt
+		}
+		if series.PaymentMode == entity.PaymentUpfront {
+			occurrences[i].HoldExpiresAt = holdExpiresAt
+		}
+	}
+
+	blackouts, err := u.courtUsecase.ListBlackoutsInRange(ctx, series.CourtId, occurrences[0].StartTime, occurrences[len(occurrences)-1].EndTime)
+	if err != nil {
+		return entity.SeriesCreationResult{}, err
+	}
+
+	bookable := make([]entity.Booking, 0, len(occurrences))
+	rejected := make([]entity.Booking, 0)
+	for _, occurrence := range occurrences {
+		if court.ValidateBooking(occurrence, now) != nil || overlapsAny(occurrence.StartTime, occurrence.EndTime, blackouts) {
+			rejected = append(rejected, occurrence)
+			continue
+		}
+		bookable = append(bookable, occurrence)
+	}
+
+	if len(bookable) == 0 {
+		return entity.SeriesCreationResult{Series: series, Conflicts: rejected}, entity.ErrSlotUnavailable
+	}
+
+	result, err := u.bookingRepository.CreateSeries(ctx, series, bookable)
+	result.Conflicts = append(rejected, result.Conflicts...)
+	if err != nil {
+		return result, err
+	}
+
+	err = u.chargeSeries(ctx, court.CompanyId, series.PaymentMode, result.Created)
+	if err != nil {
+		if cancelErr := u.bookingRepository.CancelSeries(ctx, court.CompanyId, result.Series.ID); cancelErr != nil {
+			log.Printf("BookingUsecase.CreateSeries - failed to release series %s after charge error: %v", result.Series.ID, cancelErr)
+		}
+
+		return entity.SeriesCreationResult{}, err
+	}
+
+	return result, nil
+}
+
+// chargeSeries creates the charges of the series. When a per occurrence
+// charge fails, the charges already created are cancelled so the guest can't
+// pay for a series that was released.
+func (u *bookingUsecaseImpl) chargeSeries(ctx context.Context, companyId string, mode entity.SeriesPaymentMode, bookings []entity.Booking) error {
+	if mode == entity.PaymentPerOccurrence {
+		for i, booking := range bookings {
+			if err := u.paymentUsecase.CreateCharge(ctx, companyId, booking); err != nil {
+				for _, charged := range bookings[:i] {
+					if cancelErr := u.paymentUsecase.CancelCharge(ctx, charged.ID); cancelErr != nil {
+						log.Printf("BookingUsecase.CreateSeries - failed to cancel the charge of booking %s: %v", charged.ID, cancelErr)
+					}
+				}
+
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	// The upfront charge is attached to the first occurrence and confirms the
+	// whole series once paid.
+	charged := bookings[0]
+	charged.TotalPrice = 0
+	for _, booking := range bookings {
+		charged.TotalPrice += booking.TotalPrice
+	}
+
+	return u.paymentUsecase.CreateCharge(ctx, companyId, charged)
+}
+
+func (u *bookingUsecaseImpl) FindSeriesByID(ctx context.Context, companyId string, id string) (entity.BookingSeries, error) {
+	series, err := u.bookingRepository.FindSeriesByID(ctx, companyId, id)
+	if err != nil {
+		return entity.BookingSeries{}, err
+	}
+
+	return series, nil
+}
+
+// CancelSeries cancels the upcoming occurrences of the series, refunding what
+// was paid for them as the company's cancellation policy allows.
+func (u *bookingUsecaseImpl) CancelSeries(ctx context.Context, companyId string, id string) error {
+	series, err := u.bookingRepository.FindSeriesByID(ctx, companyId, id)
+	if err != nil {
+		return err
+	}
+
+	if series.Status == entity.SeriesCancelled {
+		return entity.ErrSeriesAlreadyCancelled
+	}
+
+	policy, err := u.companyUsecase.GetCancellationPolicy(ctx, companyId)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var upcoming []entity.Booking
+	for _, occurrence := range series.Bookings {
+		if occurrence.Status != entity.StatusCancelled && now.Before(occurrence.StartTime) {
+			upcoming = append(upcoming, occurrence)
+		}
+	}
+
+	err = u.refundSeries(ctx, series, upcoming, policy)
+	if err != nil {
+		return err
+	}
+
+	err = u.bookingRepository.CancelSeries(ctx, companyId, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CancelSeriesOccurrence cancels one upcoming occurrence of the series,
+// refunding what was paid for it as the company's cancellation policy allows.
+// An occurrence of a series paid upfront can't be cancelled alone while the
+// payment is pending, since the charge covers the whole series.
+func (u *bookingUsecaseImpl) CancelSeriesOccurrence(ctx context.Context, companyId string, seriesId string, bookingId string) error {
+	series, err := u.bookingRepository.FindSeriesByID(ctx, companyId, seriesId)
+	if err != nil {
+		return err
+	}
+
+	var occurrence *entity.Booking
+	for i := range series.Bookings {
+		if series.Bookings[i].ID == bookingId {
+			occurrence = &series.Bookings[i]
+			break
+		}
+	}
+
+	if occurrence == nil {
+		return entity.ErrBookingNotFound
+	}
+
+	if occurrence.Status == entity.StatusCancelled {
+		return entity.ErrBookingAlreadyCancelled
+	}
+
+	if !time.Now().Before(occurrence.StartTime) {
+		return entity.ErrCancellationClosed
+	}
+
+	if series.PaymentMode == entity.PaymentUpfront && occurrence.Status == entity.StatusPending {
+		return entity.ErrSeriesPaymentPending
+	}
+
+	policy, err := u.companyUsecase.GetCancellationPolicy(ctx, companyId)
+	if err != nil {
+		return err
+	}
+
+	err = u.refundSeries(ctx, series, []entity.Booking{*occurrence}, policy)
+	if err != nil {
+		return err
+	}
+
+	err = u.bookingRepository.CancelSeriesOccurrence(ctx, companyId, seriesId, bookingId)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// refundSeries refunds the cancelled occurrences of the series. Occurrences
+// paid one by one are refunded like single bookings; the upfront payment is
+// refunded once for all of them.
+func (u *bookingUsecaseImpl) refundSeries(ctx context.Context, series entity.BookingSeries, cancelled []entity.Booking, policy entity.CancellationPolicy) error {
+	if series.PaymentMode == entity.PaymentUpfront {
+		_, err := u.paymentUsecase.RefundSeries(ctx, series, cancelled, policy)
+		return err
+	}
+
+	for _, occurrence := range cancelled {
+		_, err := u.paymentUsecase.RefundCharge(ctx, occurrence.ID, policy)
+		if err != nil {
+			return fmt.Errorf("BookingUsecase.refundSeries - booking %s: %w", occurrence.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// CancelByCompany cancels a booking on behalf of the club, refunding it as
+// the cancellation policy allows, or fully when requested, and notifying the
+// guest with the reason.
+func (u *bookingUsecaseImpl) CancelByCompany(ctx context.Context, companyId string, bookingId string, cancellation entity.CompanyCancellation) (entity.CancellationResult, error) {
+	if err := cancellation.Validate(); err != nil {
+		return entity.CancellationResult{}, err
+	}
+
+	booking, err := u.bookingRepository.FindCancellable(ctx, companyId, bookingId)
+	if err != nil {
+		return entity.CancellationResult{}, err
+	}
+
+	policy, err := u.companyUsecase.GetCancellationPolicy(ctx, companyId)
+	if err != nil {
+		return entity.CancellationResult{}, err
+	}
+
+	return u.cancelByCompany(ctx, companyId, booking, cancellation, cancellation.Policy(policy))
+}
+
+// CancelCourtBookings cancels every upcoming booking of the court in the range.
+// Each booking is cancelled on its own, so a failed refund is reported in the
+// results without stopping the others.
+func (u *bookingUsecaseImpl) CancelCourtBookings(ctx context.Context, companyId string, courtId string, cancellation entity.CourtCancellation) ([]entity.CancellationResult, error) {
+	if err := cancellation.Validate(); err != nil {
+		return nil, err
+	}
+
+	bookings, err := u.bookingRepository.ListCancellableByCourt(ctx, companyId, courtId, cancellation.From, cancellation.To)
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := u.companyUsecase.GetCancellationPolicy(ctx, companyId)
+	if err != nil {
+		return nil, err
+	}
+	policy = cancellation.Policy(policy)
+
+	results := make([]entity.CancellationResult, 0, len(bookings))
+	for _, booking := range bookings {
+		result, err := u.cancelByCompany(ctx, companyId, booking, cancellation.CompanyCancellation, policy)
+		if err != nil {
+			log.Printf("BookingUsecase.CancelCourtBookings - failed to cancel booking %s: %v", booking.ID, err)
+			result = entity.CancellationResult{BookingId: booking.ID, Error: err.Error()}
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+func (u *bookingUsecaseImpl) cancelByCompany(
+	ctx context.Context,
+	companyId string,
+	booking entity.Booking,
+	cancellation entity.CompanyCancellation,
+	policy entity.CancellationPolicy,
+) (entity.CancellationResult, error) {
+	if booking.Status == entity.StatusCancelled {
+		return entity.CancellationResult{}, entity.ErrBookingAlreadyCancelled
+	}
+
+	if !time.Now().Before(booking.StartTime) {
+		return entity.CancellationResult{}, entity.ErrCancellationClosed
+	}
+
+	summary, err := u.bookingRepository.GetBookingSummary(ctx, booking.ID)
+	if err != nil {
+		return entity.CancellationResult{}, err
+	}
+
+	result := entity.CancellationResult{BookingId: booking.ID}
+	if !booking.PaymentMethod.IsManual() {
+		decision, err := u.refundBooking(ctx, companyId, booking, policy)
+		if err != nil {
+			return entity.CancellationResult{}, err
+		}
+		result.Refund = &decision
+	}
+
+	messages := make([]entity.OutboxMessage, 0, 1)
+	if summary.GuestEmail != "" {
+		info := bookingEmailInfo(booking.ID, summary)
+		info.CancellationReason = cancellation.Reason
+		info.RefundAmount = formatCents(0)
+		if result.Refund != nil {
+			info.RefundAmount = formatCents(result.Refund.Amount)
+			info.RefundPercent = result.Refund.Percent
+		}
+
+		message, err := entity.NewBookingEmail(bookingCancellationTemplateName, bookingCancellationEmailSubject, info)
+		if err != nil {
+			return entity.CancellationResult{}, err
+		}
+		messages = append(messages, message)
+	}
+
+	err = u.bookingRepository.CancelByCompany(ctx, companyId, booking.ID, cancellation.Reason, messages...)
+	if err != nil {
+		return entity.CancellationResult{}, err
+	}
+	result.Cancelled = true
+
+	return result, nil
+}
+
+// refundBooking refunds a booking cancelled on its own. An occurrence of a
+// series paid upfront has no payment of its own, so it gets its share of the
+// series payment back instead.
+func (u *bookingUsecaseImpl) refundBooking(ctx context.Context, companyId string, booking entity.Booking, policy entity.CancellationPolicy) (entity.RefundDecision, error) {
+	if booking.SeriesId == nil {
+		return u.paymentUsecase.RefundCharge(ctx, booking.ID, policy)
+	}
+
+	series, err := u.bookingRepository.FindSeriesByID(ctx, companyId, *booking.SeriesId)
+	if err != nil {
+		return entity.RefundDecision{}, err
+	}
+
+	if series.PaymentMode != entity.PaymentUpfront {
+		return u.paymentUsecase.RefundCharge(ctx, booking.ID, policy)
+	}
+
+	for _, occurrence := range series.Bookings {
+		if occurrence.ID != booking.ID {
+			continue
+		}
+
+		if occurrence.Status == entity.StatusPending {
+			return entity.RefundDecision{}, entity.ErrSeriesPaymentPending
+		}
+
+		return u.paymentUsecase.RefundSeries(ctx, series, []entity.Booking{occurrence}, policy)
+	}
+
+	return entity.RefundDecision{}, entity.ErrBookingNotFound
+}
+
+func (u *bookingUsecaseImpl) checkBlackouts(ctx context.Context, booking entity.Booking) error {
+	blackouts, err := u.courtUsecase.ListBlackoutsInRange(ctx, booking.CourtId, booking.StartTime, booking.EndTime)
+	if err != nil {
+		return err
+	}
+
+	if overlapsAny(booking.StartTime, booking.EndTime, blackouts) {
+		return entity.ErrCourtBlackedOut
+	}
+
+	return nil
+}
+
+func (u *bookingUsecaseImpl) bookingPrice(court entity.Court, booking entity.Booking) int64 {
+	return u.pricingEngine.Quote(court, booking.StartTime, booking.EndTime).CourtPrice
+}
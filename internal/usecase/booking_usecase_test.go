@@ -0,0 +1,180 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dinizgab/booking-mvp/internal/entity"
+	"github.com/dinizgab/booking-mvp/internal/repository"
+)
+
+// stubCourts serves a single court. The embedded interface is left nil, so
+// any other call fails the test with a panic.
+type stubCourts struct {
+	CourtUseCase
+	court entity.Court
+}
+
+func (s stubCourts) FindByID(ctx context.Context, id string) (entity.Court, error) {
+	if id != s.court.ID {
+		return entity.Court{}, entity.ErrCourtNotFound
+	}
+
+	return s.court, nil
+}
+
+// stubCompanies reports the companies in suspended as suspended and shares
+// one cancellation policy.
+type stubCompanies struct {
+	CompanyUsecase
+	suspended map[string]bool
+	policy    entity.CancellationPolicy
+}
+
+func (s stubCompanies) GetCancellationPolicy(ctx context.Context, companyId string) (entity.CancellationPolicy, error) {
+	return s.policy, nil
+}
+
+func (s stubCompanies) CheckActive(ctx context.Context, companyId string) error {
+	if s.suspended[companyId] {
+		return entity.ErrCompanySuspended
+	}
+
+	return nil
+}
+
+func TestCreateSeriesChecksTheCourtCompany(t *testing.T) {
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+	court := entity.Court{ID: "court-1", CompanyId: testCompanyID, IsActive: true}
+	series := entity.BookingSeries{
+		CourtId:     court.ID,
+		Frequency:   entity.FrequencyWeekly,
+		StartTime:   start,
+		EndTime:     start.Add(time.Hour),
+		Count:       4,
+		PaymentMode: entity.PaymentUpfront,
+	}
+
+	tests := []struct {
+		name      string
+		companyId string
+		suspended bool
+		policy    *entity.BookingPolicy
+		want      error
+	}{
+		{name: "court of another company", companyId: "company-2", want: entity.ErrCourtNotFound},
+		{name: "suspended company", companyId: testCompanyID, suspended: true, want: entity.ErrCompanySuspended},
+		{name: "starts too far ahead", companyId: testCompanyID, policy: &entity.BookingPolicy{MaxAdvanceDays: 1}, want: entity.ErrBookingTooFarAhead},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			court := court
+			court.BookingPolicy = tt.policy
+
+			// The booking repository and the payment usecase are nil: the
+			// series must be refused before anything is stored or charged.
+			uc := NewBookingUsecase(nil, nil,
+				stubCompanies{suspended: map[string]bool{testCompanyID: tt.suspended}},
+				stubCourts{court: court}, nil)
+
+			_, err := uc.CreateSeries(context.Background(), tt.companyId, series)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("CreateSeries() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+// seriesBookings serves the occurrences of one series and records the
+// company cancellations.
+type seriesBookings struct {
+	repository.BookingRepository
+	summaries *memoryBookings
+	series    entity.BookingSeries
+	cancelled []string
+}
+
+func (s *seriesBookings) GetBookingSummary(ctx context.Context, bookingId string) (entity.Booking, error) {
+	return s.summaries.GetBookingSummary(ctx, bookingId)
+}
+
+func (s *seriesBookings) FindSeriesByID(ctx context.Context, companyId string, id string) (entity.BookingSeries, error) {
+	if companyId != s.series.CompanyId || id != s.series.ID {
+		return entity.BookingSeries{}, entity.ErrSeriesNotFound
+	}
+
+	return s.series, nil
+}
+
+func (s *seriesBookings) FindCancellable(ctx context.Context, companyId string, id string) (entity.Booking, error) {
+	for _, occurrence := range s.series.Bookings {
+		if occurrence.ID == id && companyId == s.series.CompanyId {
+			return occurrence, nil
+		}
+	}
+
+	return entity.Booking{}, entity.ErrBookingNotFound
+}
+
+func (s *seriesBookings) CancelByCompany(ctx context.Context, companyId string, id string, reason string, messages ...entity.OutboxMessage) error {
+	s.cancelled = append(s.cancelled, id)
+	return nil
+}
+
+func TestCancelByCompanyRefundsTheShareOfAnUpfrontOccurrence(t *testing.T) {
+	ctx := context.Background()
+	seriesId := "series-1"
+	occurrences := make([]entity.Booking, 3)
+	for i := range occurrences {
+		occurrences[i] = newTestBooking(time.Duration(i+1) * 7 * 24 * time.Hour)
+		occurrences[i].SeriesId = &seriesId
+		occurrences[i].PaymentMethod = entity.PaymentMethodPix
+	}
+
+	p := newPaymentTest(t, testPlan, occurrences...)
+	charged := occurrences[0]
+	charged.TotalPrice = 3 * testCourtPrice
+	correlationId := p.charge(t, charged)
+	if err := p.uc.ConfirmPayment(ctx, p.pay(t, correlationId)); err != nil {
+		t.Fatalf("ConfirmPayment: %v", err)
+	}
+	for i := range occurrences {
+		occurrences[i].Status = entity.StatusConfirmed
+	}
+
+	bookings := &seriesBookings{
+		summaries: p.bookings,
+		series: entity.BookingSeries{
+			ID:          seriesId,
+			CompanyId:   testCompanyID,
+			PaymentMode: entity.PaymentUpfront,
+			Status:      entity.SeriesActive,
+			Bookings:    occurrences,
+		},
+	}
+	policy := entity.CancellationPolicy{Tiers: []entity.CancellationTier{{MinHoursBefore: 24, RefundPercent: 100}}}
+	uc := NewBookingUsecase(bookings, p.uc, stubCompanies{policy: policy}, nil, nil)
+
+	result, err := uc.CancelByCompany(ctx, testCompanyID, occurrences[1].ID, entity.CompanyCancellation{Reason: "Court maintenance"})
+	if err != nil {
+		t.Fatalf("CancelByCompany: %v", err)
+	}
+
+	// Only the occurrence price goes back; the rest of the series stays paid.
+	if result.Refund == nil || result.Refund.Amount != testCourtPrice {
+		t.Fatalf("CancelByCompany() refund = %+v, want %d", result.Refund, testCourtPrice)
+	}
+	payment := p.payment(t, correlationId)
+	if payment.RefundedValue != testCourtPrice {
+		t.Errorf("payment refunded %d, want %d", payment.RefundedValue, testCourtPrice)
+	}
+	if status := p.bookings.status(occurrences[0].ID); status != entity.StatusConfirmed {
+		t.Errorf("first occurrence status = %s, want %s", status, entity.StatusConfirmed)
+	}
+	if len(bookings.cancelled) != 1 || bookings.cancelled[0] != occurrences[1].ID {
+		t.Errorf("cancelled bookings = %v, want only %s", bookings.cancelled, occurrences[1].ID)
+	}
+}
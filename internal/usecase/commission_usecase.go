@@ -0,0 +1,82 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+
+	"github.com/dinizgab/booking-mvp/internal/entity"
+	"github.com/dinizgab/booking-mvp/internal/repository"
+)
+
+type CommissionUsecase interface {
+	ListPlans(ctx context.Context) ([]entity.CommissionPlan, error)
+	CreatePlan(ctx context.Context, plan entity.CommissionPlan) (entity.CommissionPlan, error)
+	UpdatePlan(ctx context.Context, plan entity.CommissionPlan) (entity.CommissionPlan, error)
+	GetCompanyPlan(ctx context.Context, companyId string) (entity.CommissionPlan, error)
+	AssignCompanyPlan(ctx context.Context, companyId string, planId string) (entity.CommissionPlan, error)
+}
+
+type commissionUsecaseImpl struct {
+	repo repository.CommissionPlanRepository
+}
+
+func NewCommissionUsecase(repo repository.CommissionPlanRepository) CommissionUsecase {
+	return &commissionUsecaseImpl{
+		repo: repo,
+	}
+}
+
+func (uc *commissionUsecaseImpl) ListPlans(ctx context.Context) ([]entity.CommissionPlan, error) {
+	return uc.repo.List(ctx)
+}
+
+func (uc *commissionUsecaseImpl) CreatePlan(ctx context.Context, plan entity.CommissionPlan) (entity.CommissionPlan, error) {
+	plan.Name = strings.TrimSpace(plan.Name)
+	if err := plan.Validate(); err != nil {
+		return entity.CommissionPlan{}, err
+	}
+
+	return uc.repo.Create(ctx, plan)
+}
+
+// UpdatePlan changes the plan for the bookings charged from now on; existing
+// payments keep the commission they were created with.
+func (uc *commissionUsecaseImpl) UpdatePlan(ctx context.Context, plan entity.CommissionPlan) (entity.CommissionPlan, error) {
+	plan.Name = strings.TrimSpace(plan.Name)
+	if err := plan.Validate(); err != nil {
+		return entity.CommissionPlan{}, err
+	}
+
+	current, err := uc.repo.FindByID(ctx, plan.ID)
+	if err != nil {
+		return entity.CommissionPlan{}, err
+	}
+
+	// Unsetting the default would leave companies without a plan; another
+	// plan must be made the default instead.
+	if current.IsDefault && !plan.IsDefault {
+		return entity.CommissionPlan{}, entity.ErrInvalidCommissionPlan
+	}
+
+	return uc.repo.Update(ctx, plan)
+}
+
+func (uc *commissionUsecaseImpl) GetCompanyPlan(ctx context.Context, companyId string) (entity.CommissionPlan, error) {
+	return uc.repo.GetCompanyCommissionPlan(ctx, companyId)
+}
+
+// AssignCompanyPlan attaches the plan to the company, or moves it back to the
+// default plan when planId is empty.
+func (uc *commissionUsecaseImpl) AssignCompanyPlan(ctx context.Context, companyId string, planId string) (entity.CommissionPlan, error) {
+	if planId != "" {
+		if _, err := uc.repo.FindByID(ctx, planId); err != nil {
+			return entity.CommissionPlan{}, err
+		}
+	}
+
+	if err := uc.repo.SetCompanyCommissionPlan(ctx, companyId, planId); err != nil {
+		return entity.CommissionPlan{}, err
+	}
+
+	return uc.repo.GetCompanyCommissionPlan(ctx, companyId)
+}
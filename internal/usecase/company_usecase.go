@@ -8,23 +8,26 @@ import (
 	"github.com/dinizgab/booking-mvp/internal/auth"
 	"github.com/dinizgab/booking-mvp/internal/entity"
 	"github.com/dinizgab/booking-mvp/internal/repository"
-	"github.com/jackc/pgx/v5"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type (
 	CompanyUsecase interface {
-		Login(ctx context.Context, email, password string) (string, error)
 		Create(ctx context.Context, company entity.Company) (string, error)
 		GetDashboardInfo(ctx context.Context, companyId string) (entity.CompanyDashboard, error)
 		FindByID(ctx context.Context, id string) (entity.Company, error)
 		Update(ctx context.Context, id string, company entity.Company) error
 		Delete(ctx context.Context, id string) error
         FindByIDShowcase(ctx context.Context, id string) (entity.Company, error)
+		GetCancellationPolicy(ctx context.Context, companyId string) (entity.CancellationPolicy, error)
+		UpdateCancellationPolicy(ctx context.Context, policy entity.CancellationPolicy) (entity.CancellationPolicy, error)
+		// CheckActive returns entity.ErrCompanySuspended for suspended companies.
+		CheckActive(ctx context.Context, companyId string) error
 	}
 
 	companyUsecaseImpl struct {
 		companyRepository repository.CompanyRepository
+		userRepository    repository.UserRepository
 		authService       auth.AuthService
 		paymentUsecase    PaymentUsecase
 	}
@@ -32,11 +35,13 @@ type (
 
 func NewCompanyUsecase(
 	companyRepository repository.CompanyRepository,
+	userRepository repository.UserRepository,
 	authService auth.AuthService,
 	paymentUsecase PaymentUsecase,
 ) CompanyUsecase {
 	return &companyUsecaseImpl{
 		companyRepository: companyRepository,
+		userRepository:    userRepository,
 		authService:       authService,
 		paymentUsecase:    paymentUsecase,
 	}
@@ -44,12 +49,15 @@ func NewCompanyUsecase(
 
 // TODO - Make this function atomic (create company and subaccount in one transaction)
 func (u *companyUsecaseImpl) Create(ctx context.Context, company entity.Company) (string, error) {
+	if company.PaymentProvider != "" && !company.PaymentProvider.IsValid() {
+		return "", entity.ErrUnsupportedProvider
+	}
+
 	hash, err := bcrypt.GenerateFromPassword([]byte(company.Password), 14)
 	if err != nil {
 		return "", fmt.Errorf("CompanyUsecase.Create - failed to hash password: %w", err)
 	}
 
-	company.Password = string(hash)
 	company.Slug = strings.ToLower(strings.ReplaceAll(company.Name, " ", "-"))
 
 	company, err = u.companyRepository.Create(ctx, company)
@@ -57,37 +65,28 @@ func (u *companyUsecaseImpl) Create(ctx context.Context, company entity.Company)
 		return "", err
 	}
 
-	err = u.paymentUsecase.CreateSubaccount(ctx, company)
+	// The company signs up with the login of its first owner.
+	owner, err := u.userRepository.Create(ctx, entity.User{
+		CompanyID: company.ID,
+		Name:      company.Name,
+		Email:     company.Email,
+		Password:  string(hash),
+		Role:      entity.RoleOwner,
+	})
 	if err != nil {
-        _ = u.companyRepository.Delete(ctx, company.ID)
+		_ = u.companyRepository.Delete(ctx, company.ID)
 
 		return "", err
 	}
 
-	token, err := u.authService.GenerateToken(company.ID)
-	if err != nil {
-		return "", err
-	}
-
-	return token, nil
-}
-
-func (u *companyUsecaseImpl) Login(ctx context.Context, email, password string) (string, error) {
-	company, err := u.companyRepository.FindByEmail(ctx, email)
+	err = u.paymentUsecase.CreateSubaccount(ctx, company)
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return "", entity.ErrInvalidCredentials
-		}
+        _ = u.companyRepository.Delete(ctx, company.ID)
 
 		return "", err
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(company.Password), []byte(password))
-	if err != nil {
-		return "", entity.ErrInvalidCredentials
-	}
-
-	token, err := u.authService.GenerateToken(company.ID)
+	token, err := u.authService.GenerateToken(owner)
 	if err != nil {
 		return "", err
 	}
@@ -116,6 +115,17 @@ func (u *companyUsecaseImpl) FindByID(ctx context.Context, id string) (entity.Co
 func (u *companyUsecaseImpl) Update(ctx context.Context, id string, company entity.Company) error {
 	company.Slug = strings.ToLower(strings.ReplaceAll(company.Name, " ", "-"))
 
+	if company.PaymentProvider != "" {
+		if !company.PaymentProvider.IsValid() {
+			return entity.ErrUnsupportedProvider
+		}
+
+		err := u.switchPaymentProvider(ctx, id, company.PaymentProvider)
+		if err != nil {
+			return err
+		}
+	}
+
 	err := u.companyRepository.Update(ctx, id, company)
 	if err != nil {
 		return err
@@ -124,6 +134,23 @@ func (u *companyUsecaseImpl) Update(ctx context.Context, id string, company enti
 	return nil
 }
 
+// switchPaymentProvider opens the company account on the new provider before
+// the company is moved to it, so charges never reach a provider without an
+// account. Payments already made keep using the provider that processed them.
+func (u *companyUsecaseImpl) switchPaymentProvider(ctx context.Context, id string, provider entity.PaymentProvider) error {
+	current, err := u.companyRepository.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if current.PaymentProvider == provider {
+		return nil
+	}
+
+	current.PaymentProvider = provider
+	return u.paymentUsecase.CreateSubaccount(ctx, current)
+}
+
 func (u *companyUsecaseImpl) Delete(ctx context.Context, id string) error {
 	err := u.companyRepository.Delete(ctx, id)
 	if err != nil {
@@ -142,3 +169,39 @@ func (u *companyUsecaseImpl) FindByIDShowcase(ctx context.Context, id string) (e
     return company, nil
 }
 
+
+func (u *companyUsecaseImpl) GetCancellationPolicy(ctx context.Context, companyId string) (entity.CancellationPolicy, error) {
+	policy, err := u.companyRepository.GetCancellationPolicy(ctx, companyId)
+	if err != nil {
+		return entity.CancellationPolicy{}, err
+	}
+
+	return policy.Sorted(), nil
+}
+
+func (u *companyUsecaseImpl) UpdateCancellationPolicy(ctx context.Context, policy entity.CancellationPolicy) (entity.CancellationPolicy, error) {
+	if err := policy.Validate(); err != nil {
+		return entity.CancellationPolicy{}, err
+	}
+
+	policy = policy.Sorted()
+	err := u.companyRepository.SaveCancellationPolicy(ctx, policy)
+	if err != nil {
+		return entity.CancellationPolicy{}, err
+	}
+
+	return policy, nil
+}
+
+func (u *companyUsecaseImpl) CheckActive(ctx context.Context, companyId string) error {
+	suspended, err := u.companyRepository.IsSuspended(ctx, companyId)
+	if err != nil {
+		return err
+	}
+
+	if suspended {
+		return entity.ErrCompanySuspended
+	}
+
+	return nil
+}
@@ -3,12 +3,15 @@ package usecase
 import (
 	"context"
 	"fmt"
+	"github.com/dinizgab/booking-mvp/internal/services/pricing"
 	"github.com/dinizgab/booking-mvp/internal/services/storage"
 	"github.com/google/uuid"
 	"mime/multipart"
 	"strings"
+	"time"
 
 	"github.com/dinizgab/booking-mvp/internal/entity"
+	"github.com/dinizgab/booking-mvp/internal/ports"
 	"github.com/dinizgab/booking-mvp/internal/repository"
 )
 
@@ -16,6 +19,9 @@ type (
 	courtUseCaseImpl struct {
 		courtRepository repository.CourtRepository
 		uploadStorage   storage.StorageUploader
+		pricingEngine   pricing.Engine
+		feeCalculator   pricing.FeeCalculator
+		plans           ports.CommissionPlanReader
 	}
 
 	CourtUseCase interface {
@@ -25,20 +31,43 @@ type (
 		ListCompanyCourtsShowcase(ctx context.Context, companyID string) ([]entity.Court, error)
 		ListBookingsByID(ctx context.Context, id string) ([]entity.Booking, error)
 		ListAvailableBookingSlots(ctx context.Context, id string, date string) ([]entity.Booking, error)
+		ListFreeBookingSlots(ctx context.Context, id string, date string, duration time.Duration) ([]entity.TimeSlot, error)
 		Update(ctx context.Context, id string, court entity.Court) error
 		Delete(ctx context.Context, id string) error
 		UpdateCourtStatus(ctx context.Context, id string, court entity.Court) error
+		CreateBlackout(ctx context.Context, companyId string, blackout entity.CourtBlackout) (string, error)
+		UpdateBlackout(ctx context.Context, companyId string, blackout entity.CourtBlackout) error
+		DeleteBlackout(ctx context.Context, companyId string, courtId *string, id string) error
+		ListCourtBlackouts(ctx context.Context, companyId string, courtId string) ([]entity.CourtBlackout, error)
+		ListCompanyBlackouts(ctx context.Context, companyId string) ([]entity.CourtBlackout, error)
+		ListBlackoutsInRange(ctx context.Context, courtId string, start time.Time, end time.Time) ([]entity.CourtBlackout, error)
+		ListPricingRules(ctx context.Context, companyId string, courtId string) ([]entity.PricingRule, error)
+		ReplacePricingRules(ctx context.Context, companyId string, courtId string, rules []entity.PricingRule) ([]entity.PricingRule, error)
+		Quote(ctx context.Context, courtId string, start time.Time, end time.Time) (entity.PriceQuote, error)
 	}
 )
 
-func NewCourtUseCase(courtRepository repository.CourtRepository, uploadStorage storage.StorageUploader) CourtUseCase {
+func NewCourtUseCase(
+	courtRepository repository.CourtRepository,
+	uploadStorage storage.StorageUploader,
+	pricingEngine pricing.Engine,
+	feeCalculator pricing.FeeCalculator,
+	plans ports.CommissionPlanReader,
+) CourtUseCase {
 	return &courtUseCaseImpl{
 		courtRepository: courtRepository,
 		uploadStorage:   uploadStorage,
+		pricingEngine:   pricingEngine,
+		feeCalculator:   feeCalculator,
+		plans:           plans,
 	}
 }
 
 func (u *courtUseCaseImpl) Create(ctx context.Context, court entity.Court, photos []*multipart.FileHeader) error {
+	if err := court.Policy().Validate(); err != nil {
+		return err
+	}
+
 	courtId, err := u.courtRepository.Create(ctx, &court)
 	if err != nil {
 		return err
@@ -130,7 +159,93 @@ func (u *courtUseCaseImpl) ListAvailableBookingSlots(ctx context.Context, id str
 	return bookings, nil
 }
 
+// ListFreeBookingSlots computes the bookable slots of the court on the given
+// date. A zero duration falls back to the court minimum booking duration.
+// Slots start every granularity step of the court policy, or back to back
+// when the court has none. Inactive courts have no free slots.
+func (u *courtUseCaseImpl) ListFreeBookingSlots(ctx context.Context, id string, date string, duration time.Duration) ([]entity.TimeSlot, error) {
+	if duration < 0 || duration > 24*time.Hour {
+		return nil, entity.ErrInvalidGranularity
+	}
+
+	day, err := time.ParseInLocation(time.DateOnly, date, entity.CourtLocation)
+	if err != nil {
+		return nil, entity.ErrInvalidDate
+	}
+
+	court, err := u.courtRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := court.Policy()
+	if duration == 0 {
+		duration = policy.SlotDuration()
+	}
+
+	if err := policy.CheckDuration(duration); err != nil {
+		return nil, err
+	}
+
+	step := policy.Granularity()
+	if step == 0 {
+		step = duration
+	}
+
+	slots := make([]entity.TimeSlot, 0)
+	if !court.IsActive {
+		return slots, nil
+	}
+
+	schedule, ok := court.ScheduleFor(day.Weekday())
+	if !ok || !schedule.IsOpen {
+		return slots, nil
+	}
+
+	opening, closing := schedule.OpeningHours(day)
+	bookings, err := u.courtRepository.ListBookingsInRange(ctx, id, opening, closing)
+	if err != nil {
+		return nil, err
+	}
+
+	blackouts, err := u.courtRepository.ListBlackoutsInRange(ctx, id, opening, closing)
+	if err != nil {
+		return nil, err
+	}
+
+	first := opening
+	if policy.CheckAlignment(first) != nil {
+		first = day.Add(step * (opening.Sub(day)/step + 1))
+	}
+
+	now := time.Now()
+	for start := first; !start.Add(duration).After(closing); start = start.Add(step) {
+		end := start.Add(duration)
+		if start.Before(now) || policy.CheckWindow(start, now) != nil {
+			continue
+		}
+
+		if overlapsAny(start, end, bookings) || overlapsAny(start, end, blackouts) {
+			continue
+		}
+
+		slots = append(slots, entity.TimeSlot{
+			StartTime: start,
+			EndTime:   end,
+			Price:     u.pricingEngine.Quote(court, start, end).CourtPrice,
+		})
+	}
+
+	return slots, nil
+}
+
 func (u *courtUseCaseImpl) Update(ctx context.Context, id string, court entity.Court) error {
+	if court.BookingPolicy != nil {
+		if err := court.BookingPolicy.Validate(); err != nil {
+			return err
+		}
+	}
+
 	err := u.courtRepository.Update(ctx, id, court)
 	if err != nil {
 		return err
@@ -156,3 +271,179 @@ func (u *courtUseCaseImpl) UpdateCourtStatus(ctx context.Context, id string, cou
 
 	return nil
 }
+
+func (u *courtUseCaseImpl) CreateBlackout(ctx context.Context, companyId string, blackout entity.CourtBlackout) (string, error) {
+	if err := u.checkBlackoutOwnership(ctx, companyId, blackout.CourtId); err != nil {
+		return "", err
+	}
+
+	if err := blackout.Normalize(); err != nil {
+		return "", err
+	}
+
+	blackout.CompanyId = companyId
+	id, err := u.courtRepository.CreateBlackout(ctx, blackout)
+	if err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (u *courtUseCaseImpl) UpdateBlackout(ctx context.Context, companyId string, blackout entity.CourtBlackout) error {
+	if err := u.checkBlackoutOwnership(ctx, companyId, blackout.CourtId); err != nil {
+		return err
+	}
+
+	if err := blackout.Normalize(); err != nil {
+		return err
+	}
+
+	blackout.CompanyId = companyId
+	err := u.courtRepository.UpdateBlackout(ctx, blackout)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (u *courtUseCaseImpl) DeleteBlackout(ctx context.Context, companyId string, courtId *string, id string) error {
+	err := u.courtRepository.DeleteBlackout(ctx, companyId, courtId, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (u *courtUseCaseImpl) ListCourtBlackouts(ctx context.Context, companyId string, courtId string) ([]entity.CourtBlackout, error) {
+	if err := u.checkBlackoutOwnership(ctx, companyId, &courtId); err != nil {
+		return nil, err
+	}
+
+	blackouts, err := u.courtRepository.ListCourtBlackouts(ctx, courtId)
+	if err != nil {
+		return nil, err
+	}
+
+	return blackouts, nil
+}
+
+func (u *courtUseCaseImpl) ListCompanyBlackouts(ctx context.Context, companyId string) ([]entity.CourtBlackout, error) {
+	blackouts, err := u.courtRepository.ListCompanyBlackouts(ctx, companyId)
+	if err != nil {
+		return nil, err
+	}
+
+	return blackouts, nil
+}
+
+func (u *courtUseCaseImpl) ListBlackoutsInRange(ctx context.Context, courtId string, start time.Time, end time.Time) ([]entity.CourtBlackout, error) {
+	blackouts, err := u.courtRepository.ListBlackoutsInRange(ctx, courtId, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	return blackouts, nil
+}
+
+// checkBlackoutOwnership makes sure a court scoped blackout targets a court of
+// the company. Company wide blackouts have no court to check.
+func (u *courtUseCaseImpl) checkBlackoutOwnership(ctx context.Context, companyId string, courtId *string) error {
+	if courtId == nil {
+		return nil
+	}
+
+	court, err := u.courtRepository.FindByID(ctx, *courtId)
+	if err != nil {
+		return err
+	}
+
+	if court.CompanyId != companyId {
+		return entity.ErrCourtNotFound
+	}
+
+	return nil
+}
+
+func (u *courtUseCaseImpl) ListPricingRules(ctx context.Context, companyId string, courtId string) ([]entity.PricingRule, error) {
+	if err := u.checkBlackoutOwnership(ctx, companyId, &courtId); err != nil {
+		return nil, err
+	}
+
+	rules, err := u.courtRepository.ListPricingRules(ctx, courtId)
+	if err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+func (u *courtUseCaseImpl) ReplacePricingRules(ctx context.Context, companyId string, courtId string, rules []entity.PricingRule) ([]entity.PricingRule, error) {
+	if err := u.checkBlackoutOwnership(ctx, companyId, &courtId); err != nil {
+		return nil, err
+	}
+
+	for _, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := u.courtRepository.ReplacePricingRules(ctx, courtId, rules); err != nil {
+		return nil, err
+	}
+
+	return u.courtRepository.ListPricingRules(ctx, courtId)
+}
+
+// Quote prices a prospective booking without creating anything, applying the
+// same validation and platform fee used when the guest commits to it.
+func (u *courtUseCaseImpl) Quote(ctx context.Context, courtId string, start time.Time, end time.Time) (entity.PriceQuote, error) {
+	if start.IsZero() || end.IsZero() {
+		return entity.PriceQuote{}, entity.ErrInvalidQuote
+	}
+
+	court, err := u.courtRepository.FindByID(ctx, courtId)
+	if err != nil {
+		return entity.PriceQuote{}, err
+	}
+
+	now := time.Now()
+	booking := entity.Booking{StartTime: start, EndTime: end}
+	if err := court.ValidateBooking(booking, now); err != nil {
+		return entity.PriceQuote{}, err
+	}
+
+	if err := court.Policy().CheckWindow(start, now); err != nil {
+		return entity.PriceQuote{}, err
+	}
+
+	plan, err := u.plans.GetCompanyCommissionPlan(ctx, court.CompanyId)
+	if err != nil {
+		return entity.PriceQuote{}, err
+	}
+
+	quote := u.pricingEngine.Quote(court, start, end)
+	quote.ApplyFee(u.feeCalculator.Commission(plan, quote.CourtPrice).GuestFee())
+
+	return quote, nil
+}
+
+// period is a booking or a blackout.
+type period interface {
+	Overlaps(start time.Time, end time.Time) bool
+}
+
+// overlapsAny reports whether the period from start to end intersects any of
+// the bookings or blackouts.
+func overlapsAny[T period](start time.Time, end time.Time, periods []T) bool {
+	for _, p := range periods {
+		if p.Overlaps(start, end) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,611 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dinizgab/booking-mvp/internal/entity"
+	"github.com/dinizgab/booking-mvp/internal/ports"
+	"github.com/dinizgab/booking-mvp/internal/repository"
+	"github.com/google/uuid"
+)
+
+// memoryBookings serves booking summaries from memory. The payment fake
+// updates the booking statuses the way the payment statements do.
+type memoryBookings struct {
+	mu       sync.Mutex
+	bookings map[string]entity.Booking
+}
+
+var _ ports.BookingSummaryReader = (*memoryBookings)(nil)
+
+func newMemoryBookings(bookings ...entity.Booking) *memoryBookings {
+	m := &memoryBookings{bookings: make(map[string]entity.Booking)}
+	for _, booking := range bookings {
+		m.bookings[booking.ID] = booking
+	}
+
+	return m
+}
+
+func (m *memoryBookings) GetBookingSummary(ctx context.Context, bookingId string) (entity.Booking, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	booking, ok := m.bookings[bookingId]
+	if !ok {
+		return entity.Booking{}, entity.ErrBookingNotFound
+	}
+
+	return booking, nil
+}
+
+// setStatus moves the booking to status when it is in one of the from
+// statuses.
+func (m *memoryBookings) setStatus(bookingId string, status entity.BookingStatus, from ...entity.BookingStatus) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	booking, ok := m.bookings[bookingId]
+	if !ok {
+		return
+	}
+	for _, current := range from {
+		if booking.Status == current {
+			booking.Status = status
+			m.bookings[bookingId] = booking
+			return
+		}
+	}
+}
+
+func (m *memoryBookings) status(bookingId string) entity.BookingStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.bookings[bookingId].Status
+}
+
+type staticCommissionPlans struct {
+	plan entity.CommissionPlan
+}
+
+var _ ports.CommissionPlanReader = staticCommissionPlans{}
+
+func (s staticCommissionPlans) GetCompanyCommissionPlan(ctx context.Context, companyId string) (entity.CommissionPlan, error) {
+	return s.plan, nil
+}
+
+// memoryPaymentRepository keeps payments, withdrawals and the outbox in
+// memory, following the rules of the SQL statements of the real repository.
+type memoryPaymentRepository struct {
+	mu          sync.Mutex
+	bookings    *memoryBookings
+	providers   map[string]entity.PaymentProvider
+	accounts    map[string]string
+	payments    map[string]*entity.Payment
+	outbox      []entity.OutboxMessage
+	withdrawals []entity.Withdrawal
+	schedules   map[string]entity.PayoutSchedule
+}
+
+var _ repository.PaymentRepository = (*memoryPaymentRepository)(nil)
+
+func newMemoryPaymentRepository(bookings *memoryBookings) *memoryPaymentRepository {
+	return &memoryPaymentRepository{
+		bookings:  bookings,
+		providers: make(map[string]entity.PaymentProvider),
+		accounts:  make(map[string]string),
+		payments:  make(map[string]*entity.Payment),
+		schedules: make(map[string]entity.PayoutSchedule),
+	}
+}
+
+func accountKey(companyId string, provider entity.PaymentProvider) string {
+	return companyId + "/" + string(provider)
+}
+
+// payment returns a copy of the payment with the correlation id.
+func (r *memoryPaymentRepository) payment(correlationId string) (entity.Payment, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	payment, ok := r.payments[correlationId]
+	if !ok {
+		return entity.Payment{}, false
+	}
+
+	return *payment, true
+}
+
+func (r *memoryPaymentRepository) platformRefund(bookingId string) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if payment := r.findByBookingID(bookingId); payment != nil {
+		return payment.RefundedPlatformValue
+	}
+
+	return 0
+}
+
+// messages returns the outbox messages enqueued so far.
+func (r *memoryPaymentRepository) messages() []entity.OutboxMessage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]entity.OutboxMessage(nil), r.outbox...)
+}
+
+func (r *memoryPaymentRepository) findByBookingID(bookingId string) *entity.Payment {
+	for _, payment := range r.payments {
+		if payment.BookingID == bookingId {
+			return payment
+		}
+	}
+
+	return nil
+}
+
+func (r *memoryPaymentRepository) CreateSubaccount(ctx context.Context, subaccount entity.Subaccount) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.accounts[accountKey(subaccount.CompanyID, entity.ProviderOpenPix)] = subaccount.PixKey
+
+	return nil
+}
+
+func (r *memoryPaymentRepository) GetPaymentAccount(ctx context.Context, companyId string, provider entity.PaymentProvider) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	accountId, ok := r.accounts[accountKey(companyId, provider)]
+	if !ok {
+		return "", entity.ErrPaymentAccountNotFound
+	}
+
+	return accountId, nil
+}
+
+func (r *memoryPaymentRepository) SavePaymentAccount(ctx context.Context, companyId string, provider entity.PaymentProvider, accountId string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.accounts[accountKey(companyId, provider)] = accountId
+
+	return nil
+}
+
+func (r *memoryPaymentRepository) GetCompanyPaymentProvider(ctx context.Context, companyId string) (entity.PaymentProvider, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	provider, ok := r.providers[companyId]
+	if !ok {
+		return entity.ProviderOpenPix, nil
+	}
+
+	return provider, nil
+}
+
+func (r *memoryPaymentRepository) CreateCharge(ctx context.Context, companyId string, charge entity.Charge, commission entity.Commission) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.payments[charge.CorrelationID] = &entity.Payment{
+		ID:               uuid.NewString(),
+		BookingID:        strings.TrimPrefix(charge.CorrelationID, "booking-"),
+		CompanyID:        companyId,
+		CorrelationID:    charge.CorrelationID,
+		ChargeID:         charge.ChargeID,
+		BrCode:           charge.BrCode,
+		ValueTotal:       charge.Value,
+		ValueCommission:  commission.Fee,
+		ValueCompany:     charge.Value - commission.Fee,
+		CommissionPaidBy: commission.PaidBy,
+		Provider:         charge.Provider,
+		Status:           entity.PaymentStatusPending,
+		ExpiresAt:        charge.ExpiresAt,
+		CreatedAt:        time.Now(),
+	}
+
+	return nil
+}
+
+func (r *memoryPaymentRepository) ConfirmPayment(ctx context.Context, charge entity.Charge, cancelTokenHash string, messages ...entity.OutboxMessage) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	payment, ok := r.payments[charge.CorrelationID]
+	if !ok || payment.Status != entity.PaymentStatusPending {
+		return false, nil
+	}
+
+	payment.Status = entity.PaymentStatusPaid
+	payment.PaidAt = paidAt(charge)
+	r.bookings.setStatus(payment.BookingID, entity.StatusConfirmed, entity.StatusPending)
+	r.outbox = append(r.outbox, messages...)
+
+	return true, nil
+}
+
+func (r *memoryPaymentRepository) GetBookingPaymentStatusByID(ctx context.Context, id string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	payment := r.findByBookingID(id)
+	if payment == nil {
+		return "", fmt.Errorf("memoryPaymentRepository.GetBookingPaymentStatusByID - booking %s has no payment", id)
+	}
+
+	return payment.Status, nil
+}
+
+func (r *memoryPaymentRepository) CreateWithdrawRequest(ctx context.Context, companyId string, withdraw entity.Withdrawal) (entity.Withdrawal, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	withdraw.ID = uuid.NewString()
+	withdraw.CompanyID = companyId
+	withdraw.CreatedAt = time.Now()
+	r.withdrawals = append(r.withdrawals, withdraw)
+
+	return withdraw, nil
+}
+
+func (r *memoryPaymentRepository) ExpirePayment(ctx context.Context, charge entity.Charge) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	payment, ok := r.payments[charge.CorrelationID]
+	if !ok {
+		return nil
+	}
+
+	payment.Status = entity.PaymentStatusExpired
+	r.bookings.setStatus(payment.BookingID, entity.StatusCancelled, entity.StatusPending, entity.StatusConfirmed)
+
+	return nil
+}
+
+func (r *memoryPaymentRepository) ExpireStalePayments(ctx context.Context) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var expired int64
+	for _, payment := range r.payments {
+		if payment.Status == entity.PaymentStatusPending && payment.ExpiresAt.Before(time.Now()) {
+			payment.Status = entity.PaymentStatusExpired
+			r.bookings.setStatus(payment.BookingID, entity.StatusCancelled, entity.StatusPending)
+			expired++
+		}
+	}
+
+	return expired, nil
+}
+
+func (r *memoryPaymentRepository) GetBookingChargeInformation(ctx context.Context, id string) (entity.Payment, error) {
+	return r.GetPaymentByBookingID(ctx, id)
+}
+
+func (r *memoryPaymentRepository) GetPaymentByBookingID(ctx context.Context, id string) (entity.Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	payment := r.findByBookingID(id)
+	if payment == nil {
+		return entity.Payment{}, fmt.Errorf("memoryPaymentRepository.GetPaymentByBookingID - booking %s has no payment", id)
+	}
+
+	return *payment, nil
+}
+
+func (r *memoryPaymentRepository) SaveRefundRequest(ctx context.Context, bookingId string, refund entity.Refund, messages ...entity.OutboxMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.saveRefund(bookingId, refund, true)
+	r.outbox = append(r.outbox, messages...)
+
+	return nil
+}
+
+// saveRefund follows save_refund_request.sql.
+func (r *memoryPaymentRepository) saveRefund(bookingId string, refund entity.Refund, cancelBooking bool) {
+	payment := r.findByBookingID(bookingId)
+	if payment == nil {
+		return
+	}
+
+	payment.RefundRequestedAt = time.Now()
+	if refund.PaymentStatus() == entity.PaymentStatusRefunded {
+		payment.RefundedAt = refund.RefundedAt
+	}
+	payment.RefundEndToEndID = refund.EndToEndID
+	payment.RefundedValue += refund.Value
+	payment.RefundCorrelationID = refund.CorrelationID
+	payment.RefundFailureReason = refund.FailureReason
+	payment.Status = refund.PaymentStatus()
+	payment.RefundedPlatformValue += refund.PlatformValue
+
+	if cancelBooking {
+		r.bookings.setStatus(bookingId, entity.StatusCancelled, entity.StatusConfirmed, entity.StatusPending)
+	}
+}
+
+func (r *memoryPaymentRepository) GetPaymentByRefundCorrelationID(ctx context.Context, correlationId string) (entity.Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, payment := range r.payments {
+		if payment.RefundCorrelationID == correlationId {
+			return *payment, nil
+		}
+	}
+
+	return entity.Payment{}, fmt.Errorf("memoryPaymentRepository.GetPaymentByRefundCorrelationID - refund %s not found", correlationId)
+}
+
+func (r *memoryPaymentRepository) ListPendingRefunds(ctx context.Context, olderThan time.Duration) ([]entity.Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var payments []entity.Payment
+	for _, payment := range r.payments {
+		if payment.Status == entity.PaymentStatusRefunding && payment.RefundRequestedAt.Before(time.Now().Add(-olderThan)) {
+			payments = append(payments, *payment)
+		}
+	}
+
+	return payments, nil
+}
+
+func (r *memoryPaymentRepository) FinishRefund(ctx context.Context, refund entity.Refund, messages ...entity.OutboxMessage) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, payment := range r.payments {
+		if payment.RefundCorrelationID != refund.CorrelationID || payment.Status != entity.PaymentStatusRefunding {
+			continue
+		}
+
+		payment.Status = refund.PaymentStatus()
+		if payment.Status == entity.PaymentStatusRefunded {
+			payment.RefundedAt = refund.RefundedAt
+		}
+		payment.RefundFailureReason = refund.FailureReason
+		r.outbox = append(r.outbox, messages...)
+
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func (r *memoryPaymentRepository) ListPaymentsForReconciliation(ctx context.Context, provider entity.PaymentProvider, from time.Time, to time.Time) ([]entity.Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var payments []entity.Payment
+	for _, payment := range r.payments {
+		if payment.Provider == provider && !payment.CreatedAt.Before(from) && payment.CreatedAt.Before(to) {
+			payments = append(payments, *payment)
+		}
+	}
+
+	return payments, nil
+}
+
+func (r *memoryPaymentRepository) GetPaymentByCorrelationID(ctx context.Context, correlationId string) (entity.Payment, error) {
+	payment, ok := r.payment(correlationId)
+	if !ok {
+		return entity.Payment{}, fmt.Errorf("memoryPaymentRepository.GetPaymentByCorrelationID - payment %s not found", correlationId)
+	}
+
+	return payment, nil
+}
+
+func (r *memoryPaymentRepository) MarkPaymentMismatch(ctx context.Context, charge entity.Charge, messages ...entity.OutboxMessage) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	payment, ok := r.payments[charge.CorrelationID]
+	if !ok || payment.Status != entity.PaymentStatusPending {
+		return false, nil
+	}
+
+	payment.Status = entity.PaymentStatusMismatch
+	payment.ValueReceived = charge.ValueReceived
+	payment.ValueDelta = charge.ValueReceived - payment.ValueTotal
+	payment.PaidAt = paidAt(charge)
+	r.outbox = append(r.outbox, messages...)
+
+	return true, nil
+}
+
+func (r *memoryPaymentRepository) RefundLatePayment(ctx context.Context, bookingId string, charge entity.Charge, refund entity.Refund, messages ...entity.OutboxMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	payment, ok := r.payments[charge.CorrelationID]
+	if ok && (payment.Status == entity.PaymentStatusPending || payment.Status == entity.PaymentStatusExpired) {
+		payment.PaidAt = paidAt(charge)
+		payment.ValueReceived = charge.ValueReceived
+	}
+	r.saveRefund(bookingId, refund, true)
+	r.outbox = append(r.outbox, messages...)
+
+	return nil
+}
+
+func (r *memoryPaymentRepository) SaveSeriesRefund(ctx context.Context, bookingId string, refund entity.Refund, messages ...entity.OutboxMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.saveRefund(bookingId, refund, false)
+	r.outbox = append(r.outbox, messages...)
+
+	return nil
+}
+
+func (r *memoryPaymentRepository) ListPaymentMismatches(ctx context.Context, companyId string) ([]entity.PaymentMismatch, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var mismatches []entity.PaymentMismatch
+	for _, payment := range r.payments {
+		if payment.CompanyID == companyId && payment.Status == entity.PaymentStatusMismatch {
+			mismatches = append(mismatches, toMismatch(*payment))
+		}
+	}
+
+	return mismatches, nil
+}
+
+func (r *memoryPaymentRepository) GetPaymentMismatch(ctx context.Context, companyId string, paymentId string) (entity.PaymentMismatch, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, payment := range r.payments {
+		if payment.ID == paymentId && payment.CompanyID == companyId && payment.Status == entity.PaymentStatusMismatch {
+			return toMismatch(*payment), nil
+		}
+	}
+
+	return entity.PaymentMismatch{}, entity.ErrPaymentMismatchNotFound
+}
+
+func (r *memoryPaymentRepository) AcceptPaymentMismatch(ctx context.Context, companyId string, mismatch entity.PaymentMismatch, cancelTokenHash string, messages ...entity.OutboxMessage) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	payment, ok := r.payments[mismatch.CorrelationID]
+	if !ok || payment.CompanyID != companyId || payment.Status != entity.PaymentStatusMismatch {
+		return false, nil
+	}
+
+	payment.Status = entity.PaymentStatusPaid
+	r.bookings.setStatus(payment.BookingID, entity.StatusConfirmed, entity.StatusPending)
+	r.outbox = append(r.outbox, messages...)
+
+	return true, nil
+}
+
+func (r *memoryPaymentRepository) ListWithdrawals(ctx context.Context, companyId string) ([]entity.Withdrawal, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	withdrawals := make([]entity.Withdrawal, 0)
+	for _, withdrawal := range r.withdrawals {
+		if withdrawal.CompanyID == companyId {
+			withdrawals = append(withdrawals, withdrawal)
+		}
+	}
+
+	return withdrawals, nil
+}
+
+func (r *memoryPaymentRepository) ListPendingWithdrawals(ctx context.Context, olderThan time.Duration) ([]entity.Withdrawal, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var withdrawals []entity.Withdrawal
+	for _, withdrawal := range r.withdrawals {
+		if withdrawal.Status == entity.WithdrawalPending && withdrawal.CreatedAt.Before(time.Now().Add(-olderThan)) {
+			withdrawals = append(withdrawals, withdrawal)
+		}
+	}
+
+	return withdrawals, nil
+}
+
+func (r *memoryPaymentRepository) UpdateWithdrawalStatus(ctx context.Context, withdrawal entity.Withdrawal) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, stored := range r.withdrawals {
+		if stored.ID == withdrawal.ID && stored.Status == entity.WithdrawalPending {
+			r.withdrawals[i].Status = withdrawal.Status
+			r.withdrawals[i].FailureReason = withdrawal.FailureReason
+			r.withdrawals[i].CompletedAt = withdrawal.CompletedAt
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (r *memoryPaymentRepository) GetPayoutSchedule(ctx context.Context, companyId string) (entity.PayoutSchedule, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	schedule, ok := r.schedules[companyId]
+	if !ok {
+		return entity.PayoutSchedule{CompanyId: companyId, Weekday: time.Monday}, nil
+	}
+
+	return schedule, nil
+}
+
+func (r *memoryPaymentRepository) SavePayoutSchedule(ctx context.Context, schedule entity.PayoutSchedule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	schedule.LastPayoutAt = r.schedules[schedule.CompanyId].LastPayoutAt
+	r.schedules[schedule.CompanyId] = schedule
+
+	return nil
+}
+
+func (r *memoryPaymentRepository) ClaimDuePayoutSchedules(ctx context.Context, weekday time.Weekday, dayStart time.Time) ([]entity.PayoutSchedule, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var claimed []entity.PayoutSchedule
+	for companyId, schedule := range r.schedules {
+		if schedule.Enabled && schedule.Weekday == weekday && schedule.LastPayoutAt.Before(dayStart) {
+			claimed = append(claimed, schedule)
+			schedule.LastPayoutAt = time.Now()
+			r.schedules[companyId] = schedule
+		}
+	}
+
+	return claimed, nil
+}
+
+func (r *memoryPaymentRepository) ReleasePayoutSchedule(ctx context.Context, schedule entity.PayoutSchedule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	claimed := r.schedules[schedule.CompanyId]
+	claimed.LastPayoutAt = schedule.LastPayoutAt
+	r.schedules[schedule.CompanyId] = claimed
+
+	return nil
+}
+
+func paidAt(charge entity.Charge) time.Time {
+	if charge.PaidAt.IsZero() {
+		return time.Now()
+	}
+
+	return charge.PaidAt
+}
+
+func toMismatch(payment entity.Payment) entity.PaymentMismatch {
+	return entity.PaymentMismatch{
+		PaymentID:       payment.ID,
+		BookingID:       payment.BookingID,
+		CorrelationID:   payment.CorrelationID,
+		Provider:        payment.Provider,
+		ValueTotal:      payment.ValueTotal,
+		ValueCommission: payment.ValueCommission,
+		ValueReceived:   payment.ValueReceived,
+		ValueDelta:      payment.ValueDelta,
+		PaidAt:          payment.PaidAt,
+	}
+}
@@ -0,0 +1,155 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dinizgab/booking-mvp/internal/auth"
+	"github.com/dinizgab/booking-mvp/internal/entity"
+	"github.com/dinizgab/booking-mvp/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type OperatorUsecase interface {
+	Login(ctx context.Context, email, password string) (string, error)
+	Create(ctx context.Context, operator entity.Operator) (entity.Operator, error)
+	ListCompanies(ctx context.Context, filter entity.CompanyFilter) (entity.CompanyPage, error)
+	GetCompany(ctx context.Context, id string) (entity.CompanyOverview, error)
+	SuspendCompany(ctx context.Context, id string, reason string) (entity.CompanyOverview, error)
+	ReactivateCompany(ctx context.Context, id string) (entity.CompanyOverview, error)
+	// Impersonate returns a company token the operator uses to reach the
+	// admin routes of the company for support.
+	Impersonate(ctx context.Context, operatorId string, companyId string, reason string) (string, error)
+	GetRevenue(ctx context.Context, from, to time.Time) (entity.PlatformRevenue, error)
+	RecordAction(ctx context.Context, entry entity.AuditLogEntry) error
+	ListAuditLog(ctx context.Context, filter entity.AuditLogFilter) ([]entity.AuditLogEntry, error)
+}
+
+type operatorUsecaseImpl struct {
+	repo        repository.OperatorRepository
+	authService auth.AuthService
+}
+
+func NewOperatorUsecase(repo repository.OperatorRepository, authService auth.AuthService) OperatorUsecase {
+	return &operatorUsecaseImpl{
+		repo:        repo,
+		authService: authService,
+	}
+}
+
+func (uc *operatorUsecaseImpl) Login(ctx context.Context, email, password string) (string, error) {
+	operator, err := uc.repo.FindByEmail(ctx, strings.TrimSpace(email))
+	if err != nil {
+		return "", err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(operator.Password), []byte(password)); err != nil {
+		return "", entity.ErrInvalidCredentials
+	}
+
+	return uc.authService.GenerateOperatorToken(operator.ID)
+}
+
+func (uc *operatorUsecaseImpl) Create(ctx context.Context, operator entity.Operator) (entity.Operator, error) {
+	operator.Name = strings.TrimSpace(operator.Name)
+	operator.Email = strings.TrimSpace(operator.Email)
+	if operator.Name == "" || operator.Email == "" || operator.Password == "" {
+		return entity.Operator{}, entity.ErrInvalidOperator
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(operator.Password), 14)
+	if err != nil {
+		return entity.Operator{}, fmt.Errorf("OperatorUsecase.Create - failed to hash password: %w", err)
+	}
+	operator.Password = string(hash)
+
+	operator, err = uc.repo.Create(ctx, operator)
+	if err != nil {
+		return entity.Operator{}, err
+	}
+
+	operator.Password = ""
+	return operator, nil
+}
+
+func (uc *operatorUsecaseImpl) ListCompanies(ctx context.Context, filter entity.CompanyFilter) (entity.CompanyPage, error) {
+	filter.Query = strings.TrimSpace(filter.Query)
+	if err := filter.Validate(); err != nil {
+		return entity.CompanyPage{}, err
+	}
+
+	return uc.repo.ListCompanies(ctx, filter)
+}
+
+func (uc *operatorUsecaseImpl) GetCompany(ctx context.Context, id string) (entity.CompanyOverview, error) {
+	return uc.repo.FindCompany(ctx, id)
+}
+
+// SuspendCompany stops the company from logging in, using the admin API and
+// taking new bookings. Bookings already paid are kept.
+func (uc *operatorUsecaseImpl) SuspendCompany(ctx context.Context, id string, reason string) (entity.CompanyOverview, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return entity.CompanyOverview{}, entity.ErrReasonRequired
+	}
+
+	if err := uc.repo.SuspendCompany(ctx, id, reason); err != nil {
+		return entity.CompanyOverview{}, err
+	}
+
+	return uc.repo.FindCompany(ctx, id)
+}
+
+func (uc *operatorUsecaseImpl) ReactivateCompany(ctx context.Context, id string) (entity.CompanyOverview, error) {
+	if err := uc.repo.ReactivateCompany(ctx, id); err != nil {
+		return entity.CompanyOverview{}, err
+	}
+
+	return uc.repo.FindCompany(ctx, id)
+}
+
+// Impersonate works for suspended companies too, so support can look into
+// them.
+func (uc *operatorUsecaseImpl) Impersonate(ctx context.Context, operatorId string, companyId string, reason string) (string, error) {
+	if strings.TrimSpace(reason) == "" {
+		return "", entity.ErrReasonRequired
+	}
+
+	if _, err := uc.repo.FindCompany(ctx, companyId); err != nil {
+		return "", err
+	}
+
+	return uc.authService.GenerateImpersonationToken(companyId, operatorId, entity.ImpersonationDuration)
+}
+
+func (uc *operatorUsecaseImpl) GetRevenue(ctx context.Context, from, to time.Time) (entity.PlatformRevenue, error) {
+	if !from.Before(to) || to.Sub(from) > entity.MaxStatementPeriod {
+		return entity.PlatformRevenue{}, entity.ErrInvalidRevenuePeriod
+	}
+
+	providers, err := uc.repo.GetRevenue(ctx, from, to)
+	if err != nil {
+		return entity.PlatformRevenue{}, err
+	}
+
+	return entity.NewPlatformRevenue(from, to, providers), nil
+}
+
+func (uc *operatorUsecaseImpl) RecordAction(ctx context.Context, entry entity.AuditLogEntry) error {
+	if entry.OperatorID == "" {
+		return errors.New("OperatorUsecase.RecordAction - missing operator")
+	}
+
+	return uc.repo.CreateAuditLogEntry(ctx, entry)
+}
+
+func (uc *operatorUsecaseImpl) ListAuditLog(ctx context.Context, filter entity.AuditLogFilter) ([]entity.AuditLogEntry, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	return uc.repo.ListAuditLog(ctx, filter)
+}
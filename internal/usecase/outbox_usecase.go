@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/dinizgab/booking-mvp/internal/entity"
+	"github.com/dinizgab/booking-mvp/internal/repository"
+	"github.com/dinizgab/booking-mvp/internal/services/notification"
+)
+
+// outboxBatchSize is the number of messages delivered per dispatcher run.
+const outboxBatchSize = 50
+
+type (
+	OutboxUsecase interface {
+		DispatchPending(ctx context.Context) (int, error)
+		ListBookingNotifications(ctx context.Context, companyId string, bookingId string) ([]entity.OutboxMessage, error)
+	}
+
+	outboxUsecaseImpl struct {
+		outboxRepository    repository.OutboxRepository
+		notificationService notification.Sender
+	}
+)
+
+func NewOutboxUsecase(outboxRepository repository.OutboxRepository, notificationService notification.Sender) OutboxUsecase {
+	return &outboxUsecaseImpl{
+		outboxRepository:    outboxRepository,
+		notificationService: notificationService,
+	}
+}
+
+// DispatchPending delivers the due messages. Failed deliveries are retried
+// with exponential backoff and dead-lettered after entity.MaxOutboxAttempts.
+func (u *outboxUsecaseImpl) DispatchPending(ctx context.Context) (int, error) {
+	messages, err := u.outboxRepository.ListDue(ctx, outboxBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, message := range messages {
+		if err := u.deliver(ctx, message); err != nil {
+			if markErr := u.markFailed(ctx, message, err); markErr != nil {
+				return sent, markErr
+			}
+			continue
+		}
+
+		if err := u.outboxRepository.MarkSent(ctx, message.ID); err != nil {
+			return sent, err
+		}
+		sent++
+	}
+
+	return sent, nil
+}
+
+func (u *outboxUsecaseImpl) ListBookingNotifications(ctx context.Context, companyId string, bookingId string) ([]entity.OutboxMessage, error) {
+	messages, err := u.outboxRepository.ListByBooking(ctx, companyId, bookingId)
+	if err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+func (u *outboxUsecaseImpl) deliver(ctx context.Context, message entity.OutboxMessage) error {
+	var info entity.BookingConfirmationInfo
+	if err := json.Unmarshal(message.Payload, &info); err != nil {
+		return fmt.Errorf("decode payload: %w", err)
+	}
+
+	return u.notificationService.Send(ctx, message.Template, message.Subject, info, message.Recipient)
+}
+
+func (u *outboxUsecaseImpl) markFailed(ctx context.Context, message entity.OutboxMessage, cause error) error {
+	attempts := message.Attempts + 1
+	status := entity.OutboxPending
+	if attempts >= entity.MaxOutboxAttempts {
+		status = entity.OutboxDead
+		log.Printf("OutboxUsecase.DispatchPending - message %s dead-lettered after %d attempts: %v", message.ID, attempts, cause)
+	}
+
+	nextAttemptAt := time.Now().Add(entity.OutboxBackoff(attempts))
+	return u.outboxRepository.MarkFailed(ctx, message.ID, status, cause.Error(), nextAttemptAt)
+}
@@ -2,15 +2,16 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
 	"strings"
 	"time"
 
 	"github.com/dinizgab/booking-mvp/internal/entity"
-	"github.com/dinizgab/booking-mvp/internal/gateway/openpix"
 	"github.com/dinizgab/booking-mvp/internal/ports"
 	"github.com/dinizgab/booking-mvp/internal/repository"
-	"github.com/dinizgab/booking-mvp/internal/services/notification"
+	"github.com/dinizgab/booking-mvp/internal/services/pricing"
 )
 
 const (
@@ -19,62 +20,149 @@ const (
 
     bookingConfirmationTemplateName = "booking_confirmation.html"
     refundTemplateName = "refund_request_confirmation.html"
+
+    bookingCancellationEmailSubject = "Reserva cancelada"
+    bookingCancellationTemplateName = "booking_cancellation.html"
+
+    refundCompletedEmailSubject = "Reembolso concluído"
+    refundFailedEmailSubject = "Não foi possível concluir seu reembolso"
+    refundStatusTemplateName = "refund_status.html"
+
+    paymentMismatchEmailSubject = "Pagamento recebido com valor divergente"
+    paymentMismatchTemplateName = "payment_mismatch.html"
+
+    // pendingRefundGracePeriod leaves time for the refund webhook to arrive
+    // before the gateway is polled.
+    pendingRefundGracePeriod = 10 * time.Minute
+
+    // pendingWithdrawalGracePeriod skips withdrawals the gateway is still
+    // likely to be processing.
+    pendingWithdrawalGracePeriod = 5 * time.Minute
 )
 
 type PaymentUsecase interface {
 	CreateSubaccount(ctx context.Context, company entity.Company) error
 	CreateCharge(ctx context.Context, companyId string, booking entity.Booking) error
-	ConfirmPayment(ctx context.Context, charge openpix.Charge) error
+	// CancelCharge stops the pending charge of the booking from being paid
+	// and expires its payment. Payments no longer pending are left as they
+	// are.
+	CancelCharge(ctx context.Context, bookingId string) error
+	ConfirmPayment(ctx context.Context, charge entity.Charge) error
 	GetBookingPaymentStatusByID(ctx context.Context, id string) (string, error)
 	GetCompanyBalance(ctx context.Context, id string) (int64, error)
-	CreateWithdrawRequest(ctx context.Context, companyId string) error
-	ExpirePayment(ctx context.Context, charge openpix.Charge) error
+	CreateWithdrawRequest(ctx context.Context, companyId string, request entity.WithdrawalRequest) (entity.Withdrawal, error)
+	ListWithdrawals(ctx context.Context, companyId string) ([]entity.Withdrawal, error)
+	ReconcilePendingWithdrawals(ctx context.Context) (int, error)
+	GetPayoutSchedule(ctx context.Context, companyId string) (entity.PayoutSchedule, error)
+	UpdatePayoutSchedule(ctx context.Context, schedule entity.PayoutSchedule) (entity.PayoutSchedule, error)
+	RunScheduledPayouts(ctx context.Context) (int, error)
+	ExpirePayment(ctx context.Context, charge entity.Charge) error
+	ExpireStalePayments(ctx context.Context) (int64, error)
 	GetBookingChargeInformation(ctx context.Context, id string) (entity.Payment, error)
-	RefundCharge(ctx context.Context, bookingId string) error
+	RefundCharge(ctx context.Context, bookingId string, policy entity.CancellationPolicy) (entity.RefundDecision, error)
+	RefundSeries(ctx context.Context, series entity.BookingSeries, cancelled []entity.Booking, policy entity.CancellationPolicy) (entity.RefundDecision, error)
+	UpdateRefundStatus(ctx context.Context, refund entity.Refund) error
+	ReconcilePendingRefunds(ctx context.Context) (int, error)
+	ListPaymentMismatches(ctx context.Context, companyId string) ([]entity.PaymentMismatch, error)
+	ResolvePaymentMismatch(ctx context.Context, companyId string, paymentId string, resolution entity.MismatchResolution) error
 }
 
-type pixGatewayUsecaseImpl struct {
-	pixClient           openpix.OpenPixClient
-	summaryReader       ports.BookingSummaryReader
-	tokenWriter         ports.BookingCancelTokenWriter
-	repo                repository.PaymentRepository
-	notificationService notification.Sender
+type paymentUsecaseImpl struct {
+	gateways      map[entity.PaymentProvider]ports.PaymentGateway
+	summaryReader ports.BookingSummaryReader
+	repo          repository.PaymentRepository
+	feeCalculator pricing.FeeCalculator
+	plans         ports.CommissionPlanReader
+	refunds       pricing.RefundCalculator
 }
 
-func NewPixGatewayService(
-	pixClient openpix.OpenPixClient,
+func NewPaymentUsecase(
+	gateways []ports.PaymentGateway,
 	summaryReader ports.BookingSummaryReader,
-	tokenWriter ports.BookingCancelTokenWriter,
 	repo repository.PaymentRepository,
-	notificationService notification.Sender,
+	feeCalculator pricing.FeeCalculator,
+	plans ports.CommissionPlanReader,
+	refunds pricing.RefundCalculator,
 ) PaymentUsecase {
-	return &pixGatewayUsecaseImpl{
-		pixClient:           pixClient,
-		summaryReader:       summaryReader,
-		tokenWriter:         tokenWriter,
-		repo:                repo,
-		notificationService: notificationService,
+	return &paymentUsecaseImpl{
+		gateways:      gatewaysByProvider(gateways),
+		summaryReader: summaryReader,
+		repo:          repo,
+		feeCalculator: feeCalculator,
+		plans:         plans,
+		refunds:       refunds,
+	}
+}
+
+func gatewaysByProvider(gateways []ports.PaymentGateway) map[entity.PaymentProvider]ports.PaymentGateway {
+	byProvider := make(map[entity.PaymentProvider]ports.PaymentGateway, len(gateways))
+	for _, gateway := range gateways {
+		byProvider[gateway.Provider()] = gateway
+	}
+
+	return byProvider
+}
+
+func (uc *paymentUsecaseImpl) gateway(provider entity.PaymentProvider) (ports.PaymentGateway, error) {
+	gateway, ok := uc.gateways[provider]
+	if !ok {
+		return nil, fmt.Errorf("PaymentUsecase.gateway - %s: %w", provider, entity.ErrUnsupportedProvider)
+	}
+
+	return gateway, nil
+}
+
+// companyAccount returns the gateway configured for the company and the id of
+// the company account on it.
+func (uc *paymentUsecaseImpl) companyAccount(ctx context.Context, companyId string) (ports.PaymentGateway, string, error) {
+	provider, err := uc.repo.GetCompanyPaymentProvider(ctx, companyId)
+	if err != nil {
+		return nil, "", err
+	}
+
+	gateway, err := uc.gateway(provider)
+	if err != nil {
+		return nil, "", err
 	}
+
+	accountId, err := uc.repo.GetPaymentAccount(ctx, companyId, provider)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return gateway, accountId, nil
 }
 
-func (uc *pixGatewayUsecaseImpl) CreateSubaccount(ctx context.Context, company entity.Company) error {
-	subaccount := openpix.Subaccount{
-		Name:   company.Slug,
-		PixKey: company.PixKey,
+func (uc *paymentUsecaseImpl) CreateSubaccount(ctx context.Context, company entity.Company) error {
+	provider := company.PaymentProvider
+	if provider == "" {
+		provider = entity.ProviderOpenPix
 	}
 
-	subaccount, err := uc.pixClient.CreateSubaccount(ctx, subaccount)
+	gateway, err := uc.gateway(provider)
 	if err != nil {
 		return err
 	}
 
-	subaccountEntity := entity.Subaccount{
-		CompanyID: company.ID,
-		PixKey:    subaccount.PixKey,
-        PixKeyType: company.PixKeyType,
+	accountId, err := gateway.CreateAccount(ctx, company)
+	if err != nil {
+		return err
 	}
 
-	err = uc.repo.CreateSubaccount(ctx, subaccountEntity)
+	if provider == entity.ProviderOpenPix {
+		subaccountEntity := entity.Subaccount{
+			CompanyID:  company.ID,
+			PixKey:     accountId,
+			PixKeyType: company.PixKeyType,
+		}
+
+		err = uc.repo.CreateSubaccount(ctx, subaccountEntity)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = uc.repo.SavePaymentAccount(ctx, company.ID, provider, accountId)
 	if err != nil {
 		return err
 	}
@@ -82,18 +170,24 @@ func (uc *pixGatewayUsecaseImpl) CreateSubaccount(ctx context.Context, company e
 	return nil
 }
 
-func (uc *pixGatewayUsecaseImpl) CreateCharge(ctx context.Context, companyId string, booking entity.Booking) error {
-	subaccountPixKey, err := uc.repo.GetSubaccountPixKeyByCompanyID(ctx, companyId)
+func (uc *paymentUsecaseImpl) CreateCharge(ctx context.Context, companyId string, booking entity.Booking) error {
+	gateway, accountId, err := uc.companyAccount(ctx, companyId)
 	if err != nil {
 		return err
 	}
 
-	charge, err := uc.pixClient.CreateCharge(ctx, subaccountPixKey, booking)
+	plan, err := uc.plans.GetCompanyCommissionPlan(ctx, companyId)
 	if err != nil {
 		return err
 	}
 
-	err = uc.repo.CreateCharge(ctx, companyId, charge)
+	commission := uc.feeCalculator.Commission(plan, booking.TotalPrice)
+	charge, err := gateway.CreateCharge(ctx, accountId, booking, commission)
+	if err != nil {
+		return err
+	}
+
+	err = uc.repo.CreateCharge(ctx, companyId, charge, commission)
 	if err != nil {
 		return err
 	}
@@ -101,51 +195,140 @@ func (uc *pixGatewayUsecaseImpl) CreateCharge(ctx context.Context, companyId str
 	return nil
 }
 
-func (uc *pixGatewayUsecaseImpl) ConfirmPayment(ctx context.Context, charge openpix.Charge) error {
-	err := uc.repo.ConfirmPayment(ctx, charge)
+func (uc *paymentUsecaseImpl) CancelCharge(ctx context.Context, bookingId string) error {
+	payment, err := uc.repo.GetPaymentByBookingID(ctx, bookingId)
 	if err != nil {
 		return err
 	}
 
+	if payment.Status != entity.PaymentStatusPending {
+		return nil
+	}
+
+	gateway, err := uc.gateway(payment.Provider)
+	if err != nil {
+		return err
+	}
+
+	if err := gateway.CancelCharge(ctx, payment); err != nil {
+		return err
+	}
+
+	return uc.repo.ExpirePayment(ctx, entity.Charge{CorrelationID: payment.CorrelationID})
+}
+
+// ConfirmPayment marks the charge paid and enqueues the confirmation email in
+// the same transaction, so a delivery failure never loses the verification
+// code. Charges paid with a value other than the booking total are flagged as
+// a mismatch instead, leaving the booking pending until the company resolves
+// it. Charges paid after the payment expired or the booking was cancelled are
+// refunded in full. Charges whose received value the gateway didn't report
+// are refused with entity.ErrChargeValueUnknown.
+func (uc *paymentUsecaseImpl) ConfirmPayment(ctx context.Context, charge entity.Charge) error {
 	bookingId := strings.TrimPrefix(charge.CorrelationID, "booking-")
 	booking, err := uc.summaryReader.GetBookingSummary(ctx, bookingId)
 	if err != nil {
 		return err
 	}
 
+	payment, err := uc.repo.GetPaymentByCorrelationID(ctx, charge.CorrelationID)
+	if err != nil {
+		return err
+	}
+
+	// The booking is only confirmed once the amount received is known. The
+	// event fails and can be replayed, and reconciliation fetches the amount
+	// from the gateway.
+	if charge.ValueReceived == 0 {
+		return fmt.Errorf("PaymentUsecase.ConfirmPayment - charge %s: %w", charge.CorrelationID, entity.ErrChargeValueUnknown)
+	}
+	if payment.Status == entity.PaymentStatusExpired ||
+		payment.Status == entity.PaymentStatusPending && booking.Status == entity.StatusCancelled {
+		return uc.refundLatePayment(ctx, bookingId, booking, payment, charge)
+	}
+	if charge.ValueReceived != payment.ValueTotal {
+		return uc.flagPaymentMismatch(ctx, bookingId, booking, payment, charge)
+	}
+
 	token, err := entity.GenerateCancelToken()
 	if err != nil {
 		return err
 	}
-	tokenHash := entity.HashCancelToken(token)
-	if err := uc.tokenWriter.SetCancelTokenHash(ctx, bookingId, tokenHash); err != nil {
+
+	info := bookingEmailInfo(bookingId, booking)
+	info.CancelToken = token
+	message, err := entity.NewBookingEmail(bookingConfirmationTemplateName, bookingConfirmationEmailSubject, info)
+	if err != nil {
 		return err
 	}
 
-    loc := time.FixedZone("BRT", -3*3600)
-	bookingEmailInfo := entity.BookingConfirmationInfo{
-		ID:               bookingId,
-		GuestName:        booking.GuestName,
-		GuestPhone:       booking.GuestPhone,
-		GuestEmail:       booking.GuestEmail,
-		CourtName:        booking.Court.Name,
-		CourtAddress:     booking.Court.Company.Address,
-		BookingDate:      booking.StartTime.In(loc).Format("02-01-2006"),
-		BookingInterval:  fmt.Sprintf("%s - %s", booking.StartTime.In(loc).Format("15:04"), booking.EndTime.In(loc).Format("15:04")),
-		TotalPrice:       fmt.Sprintf("%.2f", float64(booking.TotalPrice)/100),
-		VerificationCode: booking.VerificationCode,
-		CancelToken:      token,
+	confirmed, err := uc.repo.ConfirmPayment(ctx, charge, entity.HashCancelToken(token), message)
+	if err != nil {
+		return err
+	}
+
+	if !confirmed {
+		log.Printf("PaymentUsecase.ConfirmPayment - charge %s was not pending, skipping confirmation", charge.CorrelationID)
+	}
+
+	return nil
+}
+
+// refundLatePayment gives back everything received for a booking that is no
+// longer held, since the court may have been booked by someone else.
+func (uc *paymentUsecaseImpl) refundLatePayment(ctx context.Context, bookingId string, booking entity.Booking, payment entity.Payment, charge entity.Charge) error {
+	gateway, err := uc.gateway(payment.Provider)
+	if err != nil {
+		return err
+	}
+
+	info := bookingEmailInfo(bookingId, booking)
+	info.RefundAmount = formatCents(charge.ValueReceived)
+	info.RefundPercent = 100
+	message, err := entity.NewBookingEmail(refundTemplateName, refundEmailSubject, info)
+	if err != nil {
+		return err
+	}
+
+	refund, err := gateway.RefundCharge(ctx, payment, charge.ValueReceived)
+	if err != nil {
+		return err
 	}
+	refund.Value = charge.ValueReceived
+	refund.PlatformValue = min(payment.ValueCommission, charge.ValueReceived)
 
-	err = uc.notificationService.Send(ctx, bookingConfirmationTemplateName, bookingConfirmationEmailSubject, bookingEmailInfo, booking.GuestEmail)
+	log.Printf("PaymentUsecase.ConfirmPayment - charge %s paid after the booking was released, refunding %d", charge.CorrelationID, charge.ValueReceived)
+
+	return uc.repo.RefundLatePayment(ctx, bookingId, charge, refund, message)
+}
+
+// flagPaymentMismatch moves the payment to the mismatch status and alerts the
+// company.
+func (uc *paymentUsecaseImpl) flagPaymentMismatch(ctx context.Context, bookingId string, booking entity.Booking, payment entity.Payment, charge entity.Charge) error {
+	info := bookingEmailInfo(bookingId, booking)
+	info.ExpectedAmount = formatCents(payment.ValueTotal)
+	info.ReceivedAmount = formatCents(charge.ValueReceived)
+	info.AmountDelta = formatCents(charge.ValueReceived - payment.ValueTotal)
+	message, err := entity.NewCompanyEmail(paymentMismatchTemplateName, paymentMismatchEmailSubject, booking.Court.Company.Email, info)
 	if err != nil {
 		return err
 	}
 
+	flagged, err := uc.repo.MarkPaymentMismatch(ctx, charge, message)
+	if err != nil {
+		return err
+	}
+
+	if flagged {
+		log.Printf("PaymentUsecase.ConfirmPayment - charge %s paid %d instead of %d, flagged as mismatch", charge.CorrelationID, charge.ValueReceived, payment.ValueTotal)
+	} else {
+		log.Printf("PaymentUsecase.ConfirmPayment - charge %s was not pending, skipping mismatch", charge.CorrelationID)
+	}
+
 	return nil
 }
 
-func (uc *pixGatewayUsecaseImpl) GetBookingPaymentStatusByID(ctx context.Context, id string) (string, error) {
+func (uc *paymentUsecaseImpl) GetBookingPaymentStatusByID(ctx context.Context, id string) (string, error) {
 	status, err := uc.repo.GetBookingPaymentStatusByID(ctx, id)
 	if err != nil {
 		return "", err
@@ -154,13 +337,13 @@ func (uc *pixGatewayUsecaseImpl) GetBookingPaymentStatusByID(ctx context.Context
 	return status, nil
 }
 
-func (uc *pixGatewayUsecaseImpl) GetCompanyBalance(ctx context.Context, id string) (int64, error) {
-	pixKey, err := uc.repo.GetSubaccountPixKeyByCompanyID(ctx, id)
+func (uc *paymentUsecaseImpl) GetCompanyBalance(ctx context.Context, id string) (int64, error) {
+	gateway, accountId, err := uc.companyAccount(ctx, id)
 	if err != nil {
 		return 0, err
 	}
 
-	total, err := uc.pixClient.GetCompanyBalance(ctx, pixKey)
+	total, err := gateway.GetBalance(ctx, accountId)
 	if err != nil {
 		return 0, err
 	}
@@ -168,26 +351,163 @@ func (uc *pixGatewayUsecaseImpl) GetCompanyBalance(ctx context.Context, id strin
 	return total, nil
 }
 
-func (uc *pixGatewayUsecaseImpl) CreateWithdrawRequest(ctx context.Context, companyId string) error {
-	pixKey, err := uc.repo.GetSubaccountPixKeyByCompanyID(ctx, companyId)
+func (uc *paymentUsecaseImpl) CreateWithdrawRequest(ctx context.Context, companyId string, request entity.WithdrawalRequest) (entity.Withdrawal, error) {
+	if err := request.Validate(); err != nil {
+		return entity.Withdrawal{}, err
+	}
+
+	return uc.withdraw(ctx, companyId, request.Amount, entity.WithdrawalManual)
+}
+
+// withdraw sends amount cents of the company balance to its bank account, or
+// the whole balance when amount is zero, and records the withdrawal.
+func (uc *paymentUsecaseImpl) withdraw(ctx context.Context, companyId string, amount int64, trigger string) (entity.Withdrawal, error) {
+	gateway, accountId, err := uc.companyAccount(ctx, companyId)
 	if err != nil {
-		return err
+		return entity.Withdrawal{}, err
 	}
 
-	withdraw, err := uc.pixClient.WithdrawSubaccount(ctx, pixKey)
+	balance, err := gateway.GetBalance(ctx, accountId)
 	if err != nil {
-		return err
+		return entity.Withdrawal{}, err
+	}
+
+	if amount == 0 {
+		amount = balance
+	}
+	if amount < entity.MinWithdrawalAmount {
+		return entity.Withdrawal{}, fmt.Errorf("PaymentUsecase.withdraw - %s of %s: %w", formatCents(amount), formatCents(entity.MinWithdrawalAmount), entity.ErrWithdrawalBelowMinimum)
+	}
+	if amount > balance {
+		return entity.Withdrawal{}, fmt.Errorf("PaymentUsecase.withdraw - %s of %s: %w", formatCents(amount), formatCents(balance), entity.ErrInsufficientBalance)
 	}
 
-	err = uc.repo.CreateWithdrawRequest(ctx, companyId, withdraw)
+	withdraw, err := gateway.Withdraw(ctx, accountId, amount)
 	if err != nil {
-		return fmt.Errorf("failed to create withdraw request: %w", err)
+		return entity.Withdrawal{}, err
+	}
+	withdraw.Provider = gateway.Provider()
+	withdraw.Trigger = trigger
+	if withdraw.Status == "" {
+		withdraw.Status = entity.WithdrawalPending
+	}
+	if withdraw.Status == entity.WithdrawalCompleted && withdraw.CompletedAt.IsZero() {
+		withdraw.CompletedAt = time.Now()
 	}
 
-	return nil
+	withdraw, err = uc.repo.CreateWithdrawRequest(ctx, companyId, withdraw)
+	if err != nil {
+		return entity.Withdrawal{}, fmt.Errorf("failed to create withdraw request: %w", err)
+	}
+
+	return withdraw, nil
 }
 
-func (uc *pixGatewayUsecaseImpl) ExpirePayment(ctx context.Context, charge openpix.Charge) error {
+func (uc *paymentUsecaseImpl) ListWithdrawals(ctx context.Context, companyId string) ([]entity.Withdrawal, error) {
+	withdrawals, err := uc.repo.ListWithdrawals(ctx, companyId)
+	if err != nil {
+		return nil, err
+	}
+
+	return withdrawals, nil
+}
+
+// ReconcilePendingWithdrawals asks the gateways about withdrawals still in
+// progress and records the ones that finished.
+func (uc *paymentUsecaseImpl) ReconcilePendingWithdrawals(ctx context.Context) (int, error) {
+	withdrawals, err := uc.repo.ListPendingWithdrawals(ctx, pendingWithdrawalGracePeriod)
+	if err != nil {
+		return 0, err
+	}
+
+	var updated int
+	for _, withdrawal := range withdrawals {
+		gateway, err := uc.gateway(withdrawal.Provider)
+		if err != nil {
+			log.Printf("PaymentUsecase.ReconcilePendingWithdrawals - withdrawal %s: %v", withdrawal.ID, err)
+			continue
+		}
+
+		remote, err := gateway.GetWithdrawal(ctx, withdrawal)
+		if err != nil {
+			log.Printf("PaymentUsecase.ReconcilePendingWithdrawals - failed to get withdrawal %s: %v", withdrawal.ID, err)
+			continue
+		}
+
+		if remote.Status == entity.WithdrawalPending {
+			continue
+		}
+
+		withdrawal.Status = remote.Status
+		withdrawal.FailureReason = remote.FailureReason
+		withdrawal.CompletedAt = remote.CompletedAt
+		ok, err := uc.repo.UpdateWithdrawalStatus(ctx, withdrawal)
+		if err != nil {
+			log.Printf("PaymentUsecase.ReconcilePendingWithdrawals - failed to update withdrawal %s: %v", withdrawal.ID, err)
+			continue
+		}
+		if ok {
+			updated++
+		}
+	}
+
+	return updated, nil
+}
+
+func (uc *paymentUsecaseImpl) GetPayoutSchedule(ctx context.Context, companyId string) (entity.PayoutSchedule, error) {
+	schedule, err := uc.repo.GetPayoutSchedule(ctx, companyId)
+	if err != nil {
+		return entity.PayoutSchedule{}, err
+	}
+
+	return schedule, nil
+}
+
+func (uc *paymentUsecaseImpl) UpdatePayoutSchedule(ctx context.Context, schedule entity.PayoutSchedule) (entity.PayoutSchedule, error) {
+	if err := schedule.Validate(); err != nil {
+		return entity.PayoutSchedule{}, err
+	}
+
+	if err := uc.repo.SavePayoutSchedule(ctx, schedule); err != nil {
+		return entity.PayoutSchedule{}, err
+	}
+
+	return uc.repo.GetPayoutSchedule(ctx, schedule.CompanyId)
+}
+
+// RunScheduledPayouts withdraws the whole balance of the companies whose
+// weekly payout falls on today, in the court time zone. Balances below the
+// minimum withdrawal amount wait for the next week; payouts that fail are
+// released so the next run tries again.
+func (uc *paymentUsecaseImpl) RunScheduledPayouts(ctx context.Context) (int, error) {
+	now := time.Now().In(entity.CourtLocation)
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, entity.CourtLocation)
+
+	schedules, err := uc.repo.ClaimDuePayoutSchedules(ctx, now.Weekday(), dayStart)
+	if err != nil {
+		return 0, err
+	}
+
+	var paid int
+	for _, schedule := range schedules {
+		_, err := uc.withdraw(ctx, schedule.CompanyId, 0, entity.WithdrawalAutomatic)
+		if err != nil {
+			if errors.Is(err, entity.ErrWithdrawalBelowMinimum) {
+				continue
+			}
+			log.Printf("PaymentUsecase.RunScheduledPayouts - failed to pay out company %s: %v", schedule.CompanyId, err)
+			if releaseErr := uc.repo.ReleasePayoutSchedule(ctx, schedule); releaseErr != nil {
+				log.Printf("PaymentUsecase.RunScheduledPayouts - failed to release the schedule of company %s: %v", schedule.CompanyId, releaseErr)
+			}
+			continue
+		}
+		paid++
+	}
+
+	return paid, nil
+}
+
+func (uc *paymentUsecaseImpl) ExpirePayment(ctx context.Context, charge entity.Charge) error {
 	err := uc.repo.ExpirePayment(ctx, charge)
 	if err != nil {
 		return err
@@ -196,7 +516,16 @@ func (uc *pixGatewayUsecaseImpl) ExpirePayment(ctx context.Context, charge openp
 	return nil
 }
 
-func (uc *pixGatewayUsecaseImpl) GetBookingChargeInformation(ctx context.Context, id string) (entity.Payment, error) {
+func (uc *paymentUsecaseImpl) ExpireStalePayments(ctx context.Context) (int64, error) {
+	expired, err := uc.repo.ExpireStalePayments(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return expired, nil
+}
+
+func (uc *paymentUsecaseImpl) GetBookingChargeInformation(ctx context.Context, id string) (entity.Payment, error) {
 	payment, err := uc.repo.GetBookingChargeInformation(ctx, id)
 	if err != nil {
 		return entity.Payment{}, err
@@ -205,45 +534,373 @@ func (uc *pixGatewayUsecaseImpl) GetBookingChargeInformation(ctx context.Context
 	return payment, nil
 }
 
-func (uc *pixGatewayUsecaseImpl) RefundCharge(ctx context.Context, bookingId string) error {
+// RefundCharge refunds the amount allowed by the cancellation policy through
+// the gateway that processed the payment, which may differ from the provider
+// the company uses today. Nothing is sent to the gateway when the policy grants
+// no refund.
+func (uc *paymentUsecaseImpl) RefundCharge(ctx context.Context, bookingId string, policy entity.CancellationPolicy) (entity.RefundDecision, error) {
+	payment, err := uc.repo.GetPaymentByBookingID(ctx, bookingId)
+	if err != nil {
+		return entity.RefundDecision{}, err
+	}
+
+	booking, err := uc.summaryReader.GetBookingSummary(ctx, bookingId)
+	if err != nil {
+		return entity.RefundDecision{}, err
+	}
+
+	if payment.Status == entity.PaymentStatusMismatch {
+		return entity.RefundDecision{}, fmt.Errorf("PaymentUsecase.RefundCharge - booking %s: %w", bookingId, entity.ErrPaymentMismatchUnresolved)
+	}
+
+	if payment.Status != entity.PaymentStatusPaid {
+		return entity.RefundDecision{Reason: entity.RefundReasonNone}, nil
+	}
+
+	decision, err := uc.refunds.Refund(policy, payment, booking.StartTime, time.Now())
+	if err != nil {
+		return entity.RefundDecision{}, err
+	}
+
+	if decision.Amount == 0 {
+		return decision, nil
+	}
+
+	gateway, err := uc.gateway(payment.Provider)
+	if err != nil {
+		return entity.RefundDecision{}, err
+	}
+
+	info := bookingEmailInfo(bookingId, booking)
+	info.RefundAmount = formatCents(decision.Amount)
+	info.RefundPercent = decision.Percent
+	message, err := entity.NewBookingEmail(refundTemplateName, refundEmailSubject, info)
+	if err != nil {
+		return entity.RefundDecision{}, err
+	}
+
+	refund, err := gateway.RefundCharge(ctx, payment, decision.Amount)
+	if err != nil {
+		return entity.RefundDecision{}, err
+	}
+	refund.Value = decision.Amount
+	refund.PlatformValue = decision.PlatformAmount
+
+	err = uc.repo.SaveRefundRequest(ctx, bookingId, refund, message)
+	if err != nil {
+		return entity.RefundDecision{}, err
+	}
+
+	return decision, nil
+}
+
+// RefundSeries refunds the share of the upfront series payment that paid for
+// the cancelled occurrences, each one following the policy at its own start.
+// The payment is held by the first occurrence and can only be refunded once.
+func (uc *paymentUsecaseImpl) RefundSeries(ctx context.Context, series entity.BookingSeries, cancelled []entity.Booking, policy entity.CancellationPolicy) (entity.RefundDecision, error) {
+	if len(series.Bookings) == 0 || len(cancelled) == 0 {
+		return entity.RefundDecision{Reason: entity.RefundReasonNone}, nil
+	}
+
+	bookingId := series.Bookings[0].ID
 	payment, err := uc.repo.GetPaymentByBookingID(ctx, bookingId)
 	if err != nil {
+		return entity.RefundDecision{}, err
+	}
+
+	if payment.Status == entity.PaymentStatusMismatch {
+		return entity.RefundDecision{}, fmt.Errorf("PaymentUsecase.RefundSeries - series %s: %w", series.ID, entity.ErrPaymentMismatchUnresolved)
+	}
+
+	if payment.Status == entity.PaymentStatusPending || payment.Status == entity.PaymentStatusExpired {
+		return entity.RefundDecision{Reason: entity.RefundReasonNone}, nil
+	}
+
+	decision, err := uc.seriesRefund(policy, payment, series.Bookings, cancelled, time.Now())
+	if err != nil {
+		return entity.RefundDecision{}, err
+	}
+
+	// Earlier cancellations of the series may have refunded part of the
+	// payment already, only what is left can be refunded.
+	paid := payment.ValueReceived
+	if paid == 0 {
+		paid = payment.ValueTotal
+	}
+	decision.Amount = min(decision.Amount, max(paid-payment.RefundedValue, 0))
+	decision.PlatformAmount = min(decision.PlatformAmount, max(payment.ValueCommission-payment.RefundedPlatformValue, 0))
+
+	if decision.Amount == 0 {
+		return decision, nil
+	}
+
+	if payment.Status == entity.PaymentStatusRefunding {
+		return entity.RefundDecision{}, fmt.Errorf("PaymentUsecase.RefundSeries - series %s: %w", series.ID, entity.ErrSeriesRefundPending)
+	}
+
+	booking, err := uc.summaryReader.GetBookingSummary(ctx, bookingId)
+	if err != nil {
+		return entity.RefundDecision{}, err
+	}
+
+	gateway, err := uc.gateway(payment.Provider)
+	if err != nil {
+		return entity.RefundDecision{}, err
+	}
+
+	info := bookingEmailInfo(bookingId, booking)
+	info.RefundAmount = formatCents(decision.Amount)
+	info.RefundPercent = decision.Percent
+	message, err := entity.NewBookingEmail(refundTemplateName, refundEmailSubject, info)
+	if err != nil {
+		return entity.RefundDecision{}, err
+	}
+
+	refund, err := gateway.RefundCharge(ctx, payment, decision.Amount)
+	if err != nil {
+		return entity.RefundDecision{}, err
+	}
+	refund.Value = decision.Amount
+	refund.PlatformValue = decision.PlatformAmount
+
+	err = uc.repo.SaveSeriesRefund(ctx, bookingId, refund, message)
+	if err != nil {
+		return entity.RefundDecision{}, err
+	}
+
+	return decision, nil
+}
+
+// seriesRefund adds up the refunds of the cancelled occurrences. Each one
+// refunds its share of the payment, in proportion to its price, and the
+// percent is the average weighted by price.
+func (uc *paymentUsecaseImpl) seriesRefund(policy entity.CancellationPolicy, payment entity.Payment, occurrences []entity.Booking, cancelled []entity.Booking, now time.Time) (entity.RefundDecision, error) {
+	var seriesTotal int64
+	for _, occurrence := range occurrences {
+		seriesTotal += occurrence.TotalPrice
+	}
+
+	var amount, platformAmount, weighted, cancelledTotal int64
+	for _, occurrence := range cancelled {
+		share := payment
+		if seriesTotal > 0 {
+			share.ValueTotal = payment.ValueTotal * occurrence.TotalPrice / seriesTotal
+			share.ValueCompany = payment.ValueCompany * occurrence.TotalPrice / seriesTotal
+			share.ValueCommission = payment.ValueCommission * occurrence.TotalPrice / seriesTotal
+		}
+
+		decision, err := uc.refunds.Refund(policy, share, occurrence.StartTime, now)
+		if err != nil {
+			return entity.RefundDecision{}, err
+		}
+
+		amount += decision.Amount
+		platformAmount += decision.PlatformAmount
+		weighted += int64(decision.Percent) * occurrence.TotalPrice
+		cancelledTotal += occurrence.TotalPrice
+	}
+
+	decision := entity.RefundDecision{Amount: amount, PlatformAmount: platformAmount}
+	if cancelledTotal > 0 {
+		decision.Percent = int(weighted / cancelledTotal)
+	}
+
+	switch {
+	case decision.Amount == 0:
+		decision.Reason = entity.RefundReasonNone
+	case decision.Percent == 100:
+		decision.Reason = entity.RefundReasonFull
+	default:
+		decision.Reason = entity.RefundReasonPartial
+	}
+
+	return decision, nil
+}
+
+// UpdateRefundStatus finishes a refund the gateway reported as completed or
+// failed and tells the guest. Refunds still in progress are left untouched.
+func (uc *paymentUsecaseImpl) UpdateRefundStatus(ctx context.Context, refund entity.Refund) error {
+	if refund.Status != entity.RefundCompleted && refund.Status != entity.RefundFailed {
+		return nil
+	}
+
+	payment, err := uc.repo.GetPaymentByRefundCorrelationID(ctx, refund.CorrelationID)
+	if err != nil {
+		return err
+	}
+
+	if payment.Status != entity.PaymentStatusRefunding {
+		log.Printf("PaymentUsecase.UpdateRefundStatus - refund %s is already %s, skipping", refund.CorrelationID, payment.Status)
+		return nil
+	}
+
+	booking, err := uc.summaryReader.GetBookingSummary(ctx, payment.BookingID)
+	if err != nil {
+		return err
+	}
+
+	info := bookingEmailInfo(payment.BookingID, booking)
+	info.RefundAmount = formatCents(payment.RefundedValue)
+	info.RefundFailed = refund.Status == entity.RefundFailed
+
+	subject := refundCompletedEmailSubject
+	if info.RefundFailed {
+		subject = refundFailedEmailSubject
+	}
+
+	message, err := entity.NewBookingEmail(refundStatusTemplateName, subject, info)
+	if err != nil {
+		return err
+	}
+
+	finished, err := uc.repo.FinishRefund(ctx, refund, message)
+	if err != nil {
+		return err
+	}
+
+	if !finished {
+		log.Printf("PaymentUsecase.UpdateRefundStatus - refund %s was finished concurrently, skipping", refund.CorrelationID)
+	}
+
+	return nil
+}
+
+// ReconcilePendingRefunds asks the gateways about refunds whose webhook never
+// arrived and finishes the ones that are no longer in progress.
+func (uc *paymentUsecaseImpl) ReconcilePendingRefunds(ctx context.Context) (int, error) {
+	payments, err := uc.repo.ListPendingRefunds(ctx, pendingRefundGracePeriod)
+	if err != nil {
+		return 0, err
+	}
+
+	var updated int
+	for _, payment := range payments {
+		gateway, err := uc.gateway(payment.Provider)
+		if err != nil {
+			log.Printf("PaymentUsecase.ReconcilePendingRefunds - refund %s: %v", payment.RefundCorrelationID, err)
+			continue
+		}
+
+		refund, err := gateway.GetRefund(ctx, payment)
+		if err != nil {
+			log.Printf("PaymentUsecase.ReconcilePendingRefunds - failed to get refund %s: %v", payment.RefundCorrelationID, err)
+			continue
+		}
+
+		if refund.Status == entity.RefundPending {
+			continue
+		}
+
+		refund.CorrelationID = payment.RefundCorrelationID
+		if err := uc.UpdateRefundStatus(ctx, refund); err != nil {
+			log.Printf("PaymentUsecase.ReconcilePendingRefunds - failed to update refund %s: %v", payment.RefundCorrelationID, err)
+			continue
+		}
+		updated++
+	}
+
+	return updated, nil
+}
+
+func (uc *paymentUsecaseImpl) ListPaymentMismatches(ctx context.Context, companyId string) ([]entity.PaymentMismatch, error) {
+	mismatches, err := uc.repo.ListPaymentMismatches(ctx, companyId)
+	if err != nil {
+		return nil, err
+	}
+
+	return mismatches, nil
+}
+
+// ResolvePaymentMismatch settles a payment received with the wrong value,
+// either keeping the amount and confirming the booking or refunding all of it
+// and cancelling the booking.
+func (uc *paymentUsecaseImpl) ResolvePaymentMismatch(ctx context.Context, companyId string, paymentId string, resolution entity.MismatchResolution) error {
+	if err := resolution.Validate(); err != nil {
 		return err
 	}
 
-	refund, err := uc.pixClient.RefundCharge(ctx, payment)
+	mismatch, err := uc.repo.GetPaymentMismatch(ctx, companyId, paymentId)
 	if err != nil {
 		return err
 	}
 
-	err = uc.repo.SaveRefundRequest(ctx, bookingId, refund)
+	booking, err := uc.summaryReader.GetBookingSummary(ctx, mismatch.BookingID)
 	if err != nil {
 		return err
 	}
 
-    booking, err := uc.summaryReader.GetBookingSummary(ctx, bookingId)
-    if err != nil {
-        return err
-    }
+	if resolution.Action == entity.MismatchRefund {
+		return uc.refundPaymentMismatch(ctx, mismatch, booking)
+	}
 
-    loc := time.FixedZone("BRT", -3*3600)
-	bookingEmailInfo := entity.BookingConfirmationInfo{
+	token, err := entity.GenerateCancelToken()
+	if err != nil {
+		return err
+	}
+
+	info := bookingEmailInfo(mismatch.BookingID, booking)
+	info.CancelToken = token
+	message, err := entity.NewBookingEmail(bookingConfirmationTemplateName, bookingConfirmationEmailSubject, info)
+	if err != nil {
+		return err
+	}
+
+	accepted, err := uc.repo.AcceptPaymentMismatch(ctx, companyId, mismatch, entity.HashCancelToken(token), message)
+	if err != nil {
+		return err
+	}
+
+	if !accepted {
+		return fmt.Errorf("PaymentUsecase.ResolvePaymentMismatch - payment %s: %w", paymentId, entity.ErrPaymentMismatchNotFound)
+	}
+
+	return nil
+}
+
+func (uc *paymentUsecaseImpl) refundPaymentMismatch(ctx context.Context, mismatch entity.PaymentMismatch, booking entity.Booking) error {
+	gateway, err := uc.gateway(mismatch.Provider)
+	if err != nil {
+		return err
+	}
+
+	info := bookingEmailInfo(mismatch.BookingID, booking)
+	info.RefundAmount = formatCents(mismatch.ValueReceived)
+	info.RefundPercent = 100
+	message, err := entity.NewBookingEmail(refundTemplateName, refundEmailSubject, info)
+	if err != nil {
+		return err
+	}
+
+	refund, err := gateway.RefundCharge(ctx, mismatch.Payment(), mismatch.ValueReceived)
+	if err != nil {
+		return err
+	}
+	refund.Value = mismatch.ValueReceived
+	refund.PlatformValue = min(mismatch.ValueCommission, mismatch.ValueReceived)
+
+	return uc.repo.SaveRefundRequest(ctx, mismatch.BookingID, refund, message)
+}
+
+func bookingEmailInfo(bookingId string, booking entity.Booking) entity.BookingConfirmationInfo {
+	info := entity.BookingConfirmationInfo{
 		ID:               bookingId,
 		GuestName:        booking.GuestName,
 		GuestPhone:       booking.GuestPhone,
 		GuestEmail:       booking.GuestEmail,
 		CourtName:        booking.Court.Name,
 		CourtAddress:     booking.Court.Company.Address,
-		BookingDate:      booking.StartTime.In(loc).Format("02-01-2006"),
-		BookingInterval:  fmt.Sprintf("%s - %s", booking.StartTime.In(loc).Format("15:04"), booking.EndTime.In(loc).Format("15:04")),
-		TotalPrice:       fmt.Sprintf("%.2f", float64(booking.TotalPrice)/100),
+		BookingDate:      booking.StartTime.In(entity.CourtLocation).Format("02-01-2006"),
+		BookingInterval:  fmt.Sprintf("%s - %s", booking.StartTime.In(entity.CourtLocation).Format("15:04"), booking.EndTime.In(entity.CourtLocation).Format("15:04")),
+		TotalPrice:       formatCents(booking.TotalPrice),
 		VerificationCode: booking.VerificationCode,
 	}
-
-	err = uc.notificationService.Send(ctx, refundTemplateName, refundEmailSubject, bookingEmailInfo, booking.GuestEmail)
-	if err != nil {
-		return err
+	if booking.PlatformFee > 0 {
+		info.PlatformFee = formatCents(booking.PlatformFee)
 	}
 
-	return nil
+	return info
+}
+
+func formatCents(value int64) string {
+	return fmt.Sprintf("%.2f", float64(value)/100)
 }
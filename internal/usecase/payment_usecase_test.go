@@ -0,0 +1,483 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dinizgab/booking-mvp/internal/entity"
+	"github.com/dinizgab/booking-mvp/internal/gateway/fake"
+	"github.com/dinizgab/booking-mvp/internal/ports"
+	"github.com/dinizgab/booking-mvp/internal/services/pricing"
+	"github.com/google/uuid"
+)
+
+const (
+	testCompanyID    = "company-1"
+	testCompanyEmail = "club@example.com"
+	testCourtPrice   = 10000
+)
+
+// testPlan charges the guest 10% on top of the court price.
+var testPlan = entity.CommissionPlan{Name: "default", PercentBps: 1000, PaidBy: entity.CommissionPaidByGuest}
+
+// paymentTest runs the payment usecase against the fake gateway and the
+// in-memory repositories.
+type paymentTest struct {
+	uc       PaymentUsecase
+	gateway  *fake.Gateway
+	repo     *memoryPaymentRepository
+	bookings *memoryBookings
+	account  string
+}
+
+func newPaymentTest(t *testing.T, plan entity.CommissionPlan, bookings ...entity.Booking) *paymentTest {
+	t.Helper()
+
+	gateway := fake.NewGateway(entity.ProviderOpenPix)
+	account, err := gateway.CreateAccount(context.Background(), entity.Company{ID: testCompanyID})
+	if err != nil {
+		t.Fatalf("CreateAccount: %v", err)
+	}
+
+	memory := newMemoryBookings(bookings...)
+	repo := newMemoryPaymentRepository(memory)
+	if err := repo.SavePaymentAccount(context.Background(), testCompanyID, entity.ProviderOpenPix, account); err != nil {
+		t.Fatalf("SavePaymentAccount: %v", err)
+	}
+
+	return &paymentTest{
+		uc: NewPaymentUsecase(
+			[]ports.PaymentGateway{gateway},
+			memory,
+			repo,
+			pricing.NewFeeCalculator(),
+			staticCommissionPlans{plan: plan},
+			pricing.NewRefundCalculator(),
+		),
+		gateway:  gateway,
+		repo:     repo,
+		bookings: memory,
+		account:  account,
+	}
+}
+
+func newTestBooking(startsIn time.Duration) entity.Booking {
+	start := time.Now().Add(startsIn).Truncate(time.Minute)
+
+	return entity.Booking{
+		ID:         uuid.NewString(),
+		GuestName:  "Guest",
+		GuestEmail: "guest@example.com",
+		StartTime:  start,
+		EndTime:    start.Add(time.Hour),
+		TotalPrice: testCourtPrice,
+		Status:     entity.StatusPending,
+		Court: &entity.Court{
+			CompanyId: testCompanyID,
+			Name:      "Court 1",
+			Company:   &entity.Company{ID: testCompanyID, Email: testCompanyEmail},
+		},
+	}
+}
+
+// charge creates the charge of the booking and returns its correlation id.
+func (p *paymentTest) charge(t *testing.T, booking entity.Booking) string {
+	t.Helper()
+
+	if err := p.uc.CreateCharge(context.Background(), testCompanyID, booking); err != nil {
+		t.Fatalf("CreateCharge: %v", err)
+	}
+
+	return "booking-" + booking.ID
+}
+
+// pay pays the charge on the gateway and returns it as the webhook reports it.
+func (p *paymentTest) pay(t *testing.T, correlationId string) entity.Charge {
+	t.Helper()
+
+	payload, err := p.gateway.Pay(correlationId, p.account)
+	if err != nil {
+		t.Fatalf("Pay: %v", err)
+	}
+	charge, err := p.gateway.DecodeChargeEvent(payload)
+	if err != nil {
+		t.Fatalf("DecodeChargeEvent: %v", err)
+	}
+
+	return charge
+}
+
+func (p *paymentTest) payment(t *testing.T, correlationId string) entity.Payment {
+	t.Helper()
+
+	payment, ok := p.repo.payment(correlationId)
+	if !ok {
+		t.Fatalf("payment %s not found", correlationId)
+	}
+
+	return payment
+}
+
+func templates(messages []entity.OutboxMessage) []string {
+	names := make([]string, 0, len(messages))
+	for _, message := range messages {
+		names = append(names, message.Template)
+	}
+
+	return names
+}
+
+func TestPaymentCreateConfirmRefund(t *testing.T) {
+	ctx := context.Background()
+	booking := newTestBooking(72 * time.Hour)
+	p := newPaymentTest(t, testPlan, booking)
+
+	correlationId := p.charge(t, booking)
+	payment := p.payment(t, correlationId)
+	if payment.Status != entity.PaymentStatusPending || payment.ValueTotal != 11000 || payment.ValueCommission != 1000 {
+		t.Fatalf("created payment = %s, total %d, commission %d; want pending, 11000, 1000", payment.Status, payment.ValueTotal, payment.ValueCommission)
+	}
+
+	charge := p.pay(t, correlationId)
+	if err := p.uc.ConfirmPayment(ctx, charge); err != nil {
+		t.Fatalf("ConfirmPayment: %v", err)
+	}
+	// The gateway delivers the webhook again when it misses the response.
+	if err := p.uc.ConfirmPayment(ctx, charge); err != nil {
+		t.Fatalf("ConfirmPayment redelivery: %v", err)
+	}
+
+	if status := p.payment(t, correlationId).Status; status != entity.PaymentStatusPaid {
+		t.Errorf("payment status = %s, want %s", status, entity.PaymentStatusPaid)
+	}
+	if status := p.bookings.status(booking.ID); status != entity.StatusConfirmed {
+		t.Errorf("booking status = %s, want %s", status, entity.StatusConfirmed)
+	}
+	if got := templates(p.repo.messages()); len(got) != 1 || got[0] != bookingConfirmationTemplateName {
+		t.Fatalf("messages = %v, want one confirmation", got)
+	}
+
+	policy := entity.CancellationPolicy{Tiers: []entity.CancellationTier{{MinHoursBefore: 24, RefundPercent: 100}}}
+	decision, err := p.uc.RefundCharge(ctx, booking.ID, policy)
+	if err != nil {
+		t.Fatalf("RefundCharge: %v", err)
+	}
+
+	// The platform keeps its commission unless the policy refunds it.
+	want := entity.RefundDecision{Amount: testCourtPrice, Percent: 100, Reason: entity.RefundReasonFull}
+	if decision != want {
+		t.Errorf("RefundCharge() = %+v, want %+v", decision, want)
+	}
+	if refunds := p.gateway.Refunds(); len(refunds) != 1 || refunds[0].Value != testCourtPrice {
+		t.Errorf("gateway refunds = %+v, want one of %d", refunds, testCourtPrice)
+	}
+	payment = p.payment(t, correlationId)
+	if payment.Status != entity.PaymentStatusRefunded || payment.RefundedValue != testCourtPrice {
+		t.Errorf("payment = %s with %d refunded, want %s with %d", payment.Status, payment.RefundedValue, entity.PaymentStatusRefunded, testCourtPrice)
+	}
+	if status := p.bookings.status(booking.ID); status != entity.StatusCancelled {
+		t.Errorf("booking status = %s, want %s", status, entity.StatusCancelled)
+	}
+	if got := templates(p.repo.messages()); len(got) != 2 || got[1] != refundTemplateName {
+		t.Errorf("messages = %v, want the refund email after the confirmation", got)
+	}
+}
+
+func TestRefundChargeFollowsPolicy(t *testing.T) {
+	tiers := []entity.CancellationTier{
+		{MinHoursBefore: 24, RefundPercent: 100},
+		{MinHoursBefore: 6, RefundPercent: 50},
+	}
+
+	tests := []struct {
+		name         string
+		startsIn     time.Duration
+		policy       entity.CancellationPolicy
+		want         entity.RefundDecision
+		wantPlatform int64
+	}{
+		{
+			name:     "full refund keeps the commission",
+			startsIn: 48 * time.Hour,
+			policy:   entity.CancellationPolicy{Tiers: tiers},
+			want:     entity.RefundDecision{Amount: 10000, Percent: 100, Reason: entity.RefundReasonFull},
+		},
+		{
+			name:         "platform fee refunded",
+			startsIn:     48 * time.Hour,
+			policy:       entity.CancellationPolicy{Tiers: tiers, RefundPlatformFee: true},
+			want:         entity.RefundDecision{Amount: 11000, Percent: 100, Reason: entity.RefundReasonFull, PlatformAmount: 1000},
+			wantPlatform: 1000,
+		},
+		{
+			name:         "partial refund with the platform fee",
+			startsIn:     12 * time.Hour,
+			policy:       entity.CancellationPolicy{Tiers: tiers, RefundPlatformFee: true},
+			want:         entity.RefundDecision{Amount: 5500, Percent: 50, Reason: entity.RefundReasonPartial, PlatformAmount: 500},
+			wantPlatform: 500,
+		},
+		{
+			name:     "too late for a refund",
+			startsIn: 2 * time.Hour,
+			policy:   entity.CancellationPolicy{Tiers: tiers},
+			want:     entity.RefundDecision{Percent: 0, Reason: entity.RefundReasonNone},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			booking := newTestBooking(tt.startsIn)
+			p := newPaymentTest(t, testPlan, booking)
+			correlationId := p.charge(t, booking)
+			if err := p.uc.ConfirmPayment(ctx, p.pay(t, correlationId)); err != nil {
+				t.Fatalf("ConfirmPayment: %v", err)
+			}
+
+			decision, err := p.uc.RefundCharge(ctx, booking.ID, tt.policy)
+			if err != nil {
+				t.Fatalf("RefundCharge: %v", err)
+			}
+			if decision != tt.want {
+				t.Errorf("RefundCharge() = %+v, want %+v", decision, tt.want)
+			}
+
+			if tt.want.Amount == 0 {
+				if refunds := p.gateway.Refunds(); len(refunds) != 0 {
+					t.Errorf("gateway refunds = %+v, want none", refunds)
+				}
+				if status := p.payment(t, correlationId).Status; status != entity.PaymentStatusPaid {
+					t.Errorf("payment status = %s, want %s", status, entity.PaymentStatusPaid)
+				}
+				return
+			}
+			if got := p.repo.platformRefund(booking.ID); got != tt.wantPlatform {
+				t.Errorf("platform refund = %d, want %d", got, tt.wantPlatform)
+			}
+		})
+	}
+}
+
+func TestConfirmPaymentFlagsMismatch(t *testing.T) {
+	ctx := context.Background()
+	booking := newTestBooking(72 * time.Hour)
+	p := newPaymentTest(t, testPlan, booking)
+	correlationId := p.charge(t, booking)
+
+	charge := p.pay(t, correlationId)
+	charge.ValueReceived -= 500
+	if err := p.uc.ConfirmPayment(ctx, charge); err != nil {
+		t.Fatalf("ConfirmPayment: %v", err)
+	}
+
+	payment := p.payment(t, correlationId)
+	if payment.Status != entity.PaymentStatusMismatch || payment.ValueDelta != -500 {
+		t.Errorf("payment = %s with delta %d, want %s with -500", payment.Status, payment.ValueDelta, entity.PaymentStatusMismatch)
+	}
+	if status := p.bookings.status(booking.ID); status != entity.StatusPending {
+		t.Errorf("booking status = %s, want %s", status, entity.StatusPending)
+	}
+	messages := p.repo.messages()
+	if len(messages) != 1 || messages[0].Template != paymentMismatchTemplateName || messages[0].Recipient != testCompanyEmail {
+		t.Errorf("messages = %v, want one mismatch alert to the company", templates(messages))
+	}
+
+	_, err := p.uc.RefundCharge(ctx, booking.ID, entity.DefaultCancellationPolicy(testCompanyID))
+	if !errors.Is(err, entity.ErrPaymentMismatchUnresolved) {
+		t.Errorf("RefundCharge() error = %v, want %v", err, entity.ErrPaymentMismatchUnresolved)
+	}
+}
+
+func TestConfirmPaymentRefundsLatePayments(t *testing.T) {
+	tests := []struct {
+		name    string
+		release func(t *testing.T, p *paymentTest, booking entity.Booking, correlationId string)
+	}{
+		{
+			name: "payment expired",
+			release: func(t *testing.T, p *paymentTest, booking entity.Booking, correlationId string) {
+				if err := p.uc.ExpirePayment(context.Background(), entity.Charge{CorrelationID: correlationId}); err != nil {
+					t.Fatalf("ExpirePayment: %v", err)
+				}
+			},
+		},
+		{
+			name: "booking cancelled",
+			release: func(t *testing.T, p *paymentTest, booking entity.Booking, correlationId string) {
+				p.bookings.setStatus(booking.ID, entity.StatusCancelled, entity.StatusPending)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			booking := newTestBooking(72 * time.Hour)
+			p := newPaymentTest(t, testPlan, booking)
+			correlationId := p.charge(t, booking)
+			tt.release(t, p, booking, correlationId)
+
+			if err := p.uc.ConfirmPayment(context.Background(), p.pay(t, correlationId)); err != nil {
+				t.Fatalf("ConfirmPayment: %v", err)
+			}
+
+			if refunds := p.gateway.Refunds(); len(refunds) != 1 || refunds[0].Value != 11000 {
+				t.Errorf("gateway refunds = %+v, want one of 11000", refunds)
+			}
+			payment := p.payment(t, correlationId)
+			if payment.Status != entity.PaymentStatusRefunded || payment.ValueReceived != 11000 {
+				t.Errorf("payment = %s with %d received, want %s with 11000", payment.Status, payment.ValueReceived, entity.PaymentStatusRefunded)
+			}
+			if got := p.repo.platformRefund(booking.ID); got != 1000 {
+				t.Errorf("platform refund = %d, want the whole commission", got)
+			}
+			if status := p.bookings.status(booking.ID); status != entity.StatusCancelled {
+				t.Errorf("booking status = %s, want %s", status, entity.StatusCancelled)
+			}
+		})
+	}
+}
+
+func TestConfirmPaymentRequiresValueReceived(t *testing.T) {
+	booking := newTestBooking(72 * time.Hour)
+	p := newPaymentTest(t, testPlan, booking)
+	correlationId := p.charge(t, booking)
+
+	charge := p.pay(t, correlationId)
+	charge.ValueReceived = 0
+	err := p.uc.ConfirmPayment(context.Background(), charge)
+	if !errors.Is(err, entity.ErrChargeValueUnknown) {
+		t.Fatalf("ConfirmPayment() error = %v, want %v", err, entity.ErrChargeValueUnknown)
+	}
+
+	if status := p.payment(t, correlationId).Status; status != entity.PaymentStatusPending {
+		t.Errorf("payment status = %s, want %s", status, entity.PaymentStatusPending)
+	}
+}
+
+func TestCreateChargeUsesCompanyProvider(t *testing.T) {
+	ctx := context.Background()
+	booking := newTestBooking(72 * time.Hour)
+	p := newPaymentTest(t, testPlan, booking)
+
+	p.repo.providers[testCompanyID] = entity.ProviderCard
+	err := p.uc.CreateCharge(ctx, testCompanyID, booking)
+	if !errors.Is(err, entity.ErrUnsupportedProvider) {
+		t.Fatalf("CreateCharge() error = %v, want %v", err, entity.ErrUnsupportedProvider)
+	}
+
+	card := fake.NewGateway(entity.ProviderCard)
+	uc := NewPaymentUsecase(
+		[]ports.PaymentGateway{p.gateway, card},
+		p.bookings,
+		p.repo,
+		pricing.NewFeeCalculator(),
+		staticCommissionPlans{plan: testPlan},
+		pricing.NewRefundCalculator(),
+	)
+	if err := p.repo.SavePaymentAccount(ctx, testCompanyID, entity.ProviderCard, "acct_card"); err != nil {
+		t.Fatalf("SavePaymentAccount: %v", err)
+	}
+	if err := uc.CreateCharge(ctx, testCompanyID, booking); err != nil {
+		t.Fatalf("CreateCharge: %v", err)
+	}
+
+	if payment := p.payment(t, "booking-"+booking.ID); payment.Provider != entity.ProviderCard {
+		t.Errorf("payment provider = %s, want %s", payment.Provider, entity.ProviderCard)
+	}
+	if charges := card.Charges(); len(charges) != 1 {
+		t.Errorf("card charges = %d, want 1", len(charges))
+	}
+	if charges := p.gateway.Charges(); len(charges) != 0 {
+		t.Errorf("openpix charges = %d, want 0", len(charges))
+	}
+}
+
+func TestRefundSeriesRefundsEachCancellation(t *testing.T) {
+	ctx := context.Background()
+	occurrences := []entity.Booking{
+		newTestBooking(7 * 24 * time.Hour),
+		newTestBooking(14 * 24 * time.Hour),
+		newTestBooking(21 * 24 * time.Hour),
+	}
+	series := entity.BookingSeries{ID: "series-1", PaymentMode: entity.PaymentUpfront, Bookings: occurrences}
+	p := newPaymentTest(t, testPlan, occurrences...)
+
+	// The upfront charge of the series is attached to its first occurrence.
+	charged := occurrences[0]
+	charged.TotalPrice = 3 * testCourtPrice
+	correlationId := p.charge(t, charged)
+	if err := p.uc.ConfirmPayment(ctx, p.pay(t, correlationId)); err != nil {
+		t.Fatalf("ConfirmPayment: %v", err)
+	}
+
+	policy := entity.CancellationPolicy{
+		Tiers:             []entity.CancellationTier{{MinHoursBefore: 24, RefundPercent: 100}},
+		RefundPlatformFee: true,
+	}
+	steps := []struct {
+		name         string
+		cancelled    []entity.Booking
+		wantAmount   int64
+		wantRefunded int64
+		wantPlatform int64
+	}{
+		{name: "first cancellation", cancelled: occurrences[1:2], wantAmount: 11000, wantRefunded: 11000, wantPlatform: 1000},
+		{name: "second cancellation", cancelled: occurrences[2:3], wantAmount: 11000, wantRefunded: 22000, wantPlatform: 2000},
+		{name: "only what is left", cancelled: occurrences, wantAmount: 11000, wantRefunded: 33000, wantPlatform: 3000},
+		{name: "nothing left", cancelled: occurrences[:1], wantAmount: 0, wantRefunded: 33000, wantPlatform: 3000},
+	}
+
+	for _, step := range steps {
+		decision, err := p.uc.RefundSeries(ctx, series, step.cancelled, policy)
+		if err != nil {
+			t.Fatalf("%s: RefundSeries: %v", step.name, err)
+		}
+		if decision.Amount != step.wantAmount {
+			t.Errorf("%s: refund = %d, want %d", step.name, decision.Amount, step.wantAmount)
+		}
+
+		payment := p.payment(t, correlationId)
+		if payment.RefundedValue != step.wantRefunded || payment.RefundedPlatformValue != step.wantPlatform {
+			t.Errorf("%s: refunded %d with %d of commission, want %d and %d",
+				step.name, payment.RefundedValue, payment.RefundedPlatformValue, step.wantRefunded, step.wantPlatform)
+		}
+	}
+
+	refunds := p.gateway.Refunds()
+	if len(refunds) != 3 {
+		t.Fatalf("gateway refunds = %+v, want 3", refunds)
+	}
+	if refunds[0].CorrelationID == refunds[1].CorrelationID || refunds[1].CorrelationID == refunds[2].CorrelationID {
+		t.Errorf("refund correlation ids = %s, %s, %s; want one per refund",
+			refunds[0].CorrelationID, refunds[1].CorrelationID, refunds[2].CorrelationID)
+	}
+}
+
+func TestRunScheduledPayoutsRetriesFailedPayouts(t *testing.T) {
+	ctx := context.Background()
+	booking := newTestBooking(72 * time.Hour)
+	p := newPaymentTest(t, testPlan, booking)
+	if err := p.uc.ConfirmPayment(ctx, p.pay(t, p.charge(t, booking))); err != nil {
+		t.Fatalf("ConfirmPayment: %v", err)
+	}
+
+	today := time.Now().In(entity.CourtLocation).Weekday()
+	if err := p.repo.SavePayoutSchedule(ctx, entity.PayoutSchedule{CompanyId: testCompanyID, Enabled: true, Weekday: today}); err != nil {
+		t.Fatalf("SavePayoutSchedule: %v", err)
+	}
+
+	p.gateway.FailNext(errors.New("gateway unavailable"))
+	if paid, err := p.uc.RunScheduledPayouts(ctx); err != nil || paid != 0 {
+		t.Fatalf("RunScheduledPayouts() = %d, %v; want 0 with the gateway down", paid, err)
+	}
+
+	// The failed payout doesn't count as today's, so the next run pays it.
+	if paid, err := p.uc.RunScheduledPayouts(ctx); err != nil || paid != 1 {
+		t.Fatalf("RunScheduledPayouts() = %d, %v; want 1 on the next run", paid, err)
+	}
+	if paid, err := p.uc.RunScheduledPayouts(ctx); err != nil || paid != 0 {
+		t.Errorf("RunScheduledPayouts() = %d, %v; want 0 once paid today", paid, err)
+	}
+}
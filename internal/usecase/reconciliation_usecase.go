@@ -0,0 +1,192 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/dinizgab/booking-mvp/internal/entity"
+	"github.com/dinizgab/booking-mvp/internal/ports"
+	"github.com/dinizgab/booking-mvp/internal/repository"
+	"github.com/jackc/pgx/v5"
+)
+
+type (
+	ReconciliationUsecase interface {
+		Reconcile(ctx context.Context, from time.Time, to time.Time) (entity.ReconciliationReport, error)
+	}
+
+	reconciliationUsecaseImpl struct {
+		gateways       []ports.PaymentGateway
+		repo           repository.PaymentRepository
+		paymentUsecase PaymentUsecase
+	}
+)
+
+func NewReconciliationUsecase(gateways []ports.PaymentGateway, repo repository.PaymentRepository, paymentUsecase PaymentUsecase) ReconciliationUsecase {
+	return &reconciliationUsecaseImpl{
+		gateways:       gateways,
+		repo:           repo,
+		paymentUsecase: paymentUsecase,
+	}
+}
+
+// Reconcile compares the charges each gateway created in [from, to) with the
+// payments stored for them. Charges paid on the gateway whose payment is still
+// pending are confirmed; every other difference is only reported. A provider
+// that cannot be reached is recorded in the report and skipped.
+func (u *reconciliationUsecaseImpl) Reconcile(ctx context.Context, from time.Time, to time.Time) (entity.ReconciliationReport, error) {
+	report := entity.ReconciliationReport{
+		From: from,
+		To:   to,
+	}
+
+	for _, gateway := range u.gateways {
+		if err := u.reconcileProvider(ctx, gateway, &report); err != nil {
+			log.Printf("ReconciliationUsecase.Reconcile - %v", err)
+			report.Errors = append(report.Errors, err.Error())
+		}
+	}
+
+	for _, item := range report.Items {
+		if item.Fixed() {
+			report.Fixed++
+		}
+	}
+
+	return report, nil
+}
+
+func (u *reconciliationUsecaseImpl) reconcileProvider(ctx context.Context, gateway ports.PaymentGateway, report *entity.ReconciliationReport) error {
+	provider := gateway.Provider()
+
+	charges, err := gateway.ListCharges(ctx, report.From, report.To)
+	if err != nil {
+		return fmt.Errorf("failed to list %s charges: %w", provider, err)
+	}
+
+	payments, err := u.repo.ListPaymentsForReconciliation(ctx, provider, report.From, report.To)
+	if err != nil {
+		return fmt.Errorf("failed to list %s payments: %w", provider, err)
+	}
+
+	local := make(map[string]entity.Payment, len(payments))
+	for _, payment := range payments {
+		local[payment.CorrelationID] = payment
+	}
+
+	for _, charge := range charges {
+		report.Checked++
+
+		payment, ok := local[charge.CorrelationID]
+		if ok {
+			delete(local, charge.CorrelationID)
+		} else {
+			// The payment may have been stored just outside the window.
+			payment, err = u.repo.GetPaymentByCorrelationID(ctx, charge.CorrelationID)
+			if errors.Is(err, pgx.ErrNoRows) {
+				report.Items = append(report.Items, entity.ReconciliationItem{
+					Provider:      provider,
+					CorrelationID: charge.CorrelationID,
+					Issue:         entity.ReconciliationMissingLocally,
+					RemoteStatus:  charge.Status,
+					RemoteValue:   charge.ValueReceived,
+				})
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("failed to get %s payment %s: %w", provider, charge.CorrelationID, err)
+			}
+		}
+
+		if item, ok := u.compare(ctx, provider, payment, charge); ok {
+			report.Items = append(report.Items, item)
+		}
+	}
+
+	for _, payment := range local {
+		report.Checked++
+		report.Items = append(report.Items, entity.ReconciliationItem{
+			Provider:      provider,
+			CorrelationID: payment.CorrelationID,
+			PaymentID:     payment.ID,
+			Issue:         entity.ReconciliationMissingRemotely,
+			LocalStatus:   payment.Status,
+			LocalValue:    payment.ValueTotal,
+		})
+	}
+
+	return nil
+}
+
+// compare returns the item describing how the payment differs from the charge,
+// applying the safe fixes, and false when both agree.
+func (u *reconciliationUsecaseImpl) compare(ctx context.Context, provider entity.PaymentProvider, payment entity.Payment, charge entity.Charge) (entity.ReconciliationItem, bool) {
+	item := entity.ReconciliationItem{
+		Provider:       provider,
+		CorrelationID:  charge.CorrelationID,
+		PaymentID:      payment.ID,
+		LocalStatus:    payment.Status,
+		RemoteStatus:   charge.Status,
+		LocalValue:     payment.ValueTotal,
+		RemoteValue:    charge.ValueReceived,
+		RemoteRefunded: charge.ValueRefunded,
+	}
+
+	paidLocally := payment.Status != entity.PaymentStatusPending &&
+		payment.Status != entity.PaymentStatusExpired
+
+	switch {
+	case payment.Status == entity.PaymentStatusMismatch:
+		item.Issue = entity.ReconciliationValueMismatch
+	case payment.Status == entity.PaymentStatusPaid && charge.ValueRefunded > 0:
+		// Refunds made on the gateway bypass the refund flow, so the booking
+		// is still confirmed and the company balance still counts the money.
+		item.Issue = entity.ReconciliationRefundedRemotely
+	case charge.Status == entity.ChargeCompleted && charge.ValueReceived == 0:
+		// Without the amount received nothing can be confirmed or compared.
+		item.Issue = entity.ReconciliationValueUnknown
+	case charge.Status == entity.ChargeCompleted && charge.ValueReceived != payment.ValueTotal:
+		item.Issue = entity.ReconciliationValueMismatch
+		if payment.Status == entity.PaymentStatusPending {
+			// Confirming flags the mismatch and alerts the company.
+			if err := u.paymentUsecase.ConfirmPayment(ctx, charge); err != nil {
+				item.Error = err.Error()
+			} else {
+				item.Action = entity.ReconciliationActionMarkedMismatch
+				item.LocalStatus = entity.PaymentStatusMismatch
+			}
+		}
+	case charge.Status == entity.ChargeCompleted && payment.Status == entity.PaymentStatusPending:
+		item.Issue = entity.ReconciliationPaidRemotely
+		if err := u.paymentUsecase.ConfirmPayment(ctx, charge); err != nil {
+			item.Error = err.Error()
+		} else {
+			item.Action = entity.ReconciliationActionConfirmed
+			item.LocalStatus = entity.PaymentStatusPaid
+		}
+	case charge.Status == entity.ChargeCompleted && payment.Status == entity.PaymentStatusExpired:
+		item.Issue = entity.ReconciliationPaidAfterExpiry
+		// Confirming refunds payments received after the hold was released.
+		if err := u.paymentUsecase.ConfirmPayment(ctx, charge); err != nil {
+			item.Error = err.Error()
+		} else {
+			item.Action = entity.ReconciliationActionRefunded
+			item.LocalStatus = entity.PaymentStatusRefunding
+		}
+	case charge.Status != entity.ChargeCompleted && paidLocally:
+		item.Issue = entity.ReconciliationNotPaidRemotely
+	case charge.Status == entity.ChargeExpired && payment.Status == entity.PaymentStatusPending:
+		item.Issue = entity.ReconciliationExpiredRemotely
+	default:
+		return entity.ReconciliationItem{}, false
+	}
+
+	if item.Error != "" {
+		log.Printf("ReconciliationUsecase.Reconcile - failed to fix %s payment %s: %s", provider, charge.CorrelationID, item.Error)
+	}
+
+	return item, true
+}
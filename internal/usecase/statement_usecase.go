@@ -0,0 +1,45 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/dinizgab/booking-mvp/internal/entity"
+	"github.com/dinizgab/booking-mvp/internal/repository"
+)
+
+type StatementUsecase interface {
+	GetStatement(ctx context.Context, companyId string, filter entity.StatementFilter) (entity.Statement, error)
+}
+
+type statementUsecaseImpl struct {
+	repo repository.LedgerRepository
+}
+
+func NewStatementUsecase(repo repository.LedgerRepository) StatementUsecase {
+	return &statementUsecaseImpl{
+		repo: repo,
+	}
+}
+
+// GetStatement returns the company ledger entries of the period with their
+// running balance, starting from the balance accumulated before it.
+func (uc *statementUsecaseImpl) GetStatement(ctx context.Context, companyId string, filter entity.StatementFilter) (entity.Statement, error) {
+	if err := filter.Validate(); err != nil {
+		return entity.Statement{}, err
+	}
+
+	name, openingBalance, err := uc.repo.GetBalance(ctx, companyId, filter.From)
+	if err != nil {
+		return entity.Statement{}, err
+	}
+
+	entries, err := uc.repo.ListEntries(ctx, companyId, filter)
+	if err != nil {
+		return entity.Statement{}, err
+	}
+
+	statement := entity.NewStatement(companyId, filter, openingBalance, entries)
+	statement.CompanyName = name
+
+	return statement, nil
+}
@@ -0,0 +1,260 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dinizgab/booking-mvp/internal/auth"
+	"github.com/dinizgab/booking-mvp/internal/entity"
+	"github.com/dinizgab/booking-mvp/internal/repository"
+	"github.com/dinizgab/booking-mvp/internal/services/notification"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	userInvitationTemplateName = "user_invitation.html"
+	userInvitationEmailSubject = "Convite para a equipe - Courtly"
+)
+
+type UserUsecase interface {
+	Login(ctx context.Context, email, password string) (string, error)
+	Me(ctx context.Context, companyId string, userId string) (entity.User, error)
+	ListStaff(ctx context.Context, companyId string) (entity.Staff, error)
+	// Invite emails a link to join the company with the role. Inviting an
+	// email again sends a new link and voids the previous one.
+	Invite(ctx context.Context, companyId string, invitedBy string, email string, role entity.UserRole) (entity.Invitation, error)
+	RevokeInvitation(ctx context.Context, companyId string, id string) error
+	// AcceptInvitation creates the account of the invited user and returns
+	// its token.
+	AcceptInvitation(ctx context.Context, token string, name string, password string) (string, error)
+	UpdateRole(ctx context.Context, companyId string, id string, role entity.UserRole) (entity.User, error)
+	Remove(ctx context.Context, companyId string, id string) error
+	CurrentRole(ctx context.Context, companyId string, userId string) (entity.UserRole, error)
+}
+
+type userUsecaseImpl struct {
+	userRepository    repository.UserRepository
+	companyRepository repository.CompanyRepository
+	authService       auth.AuthService
+	emailService      notification.Sender
+}
+
+func NewUserUsecase(
+	userRepository repository.UserRepository,
+	companyRepository repository.CompanyRepository,
+	authService auth.AuthService,
+	emailService notification.Sender,
+) UserUsecase {
+	return &userUsecaseImpl{
+		userRepository:    userRepository,
+		companyRepository: companyRepository,
+		authService:       authService,
+		emailService:      emailService,
+	}
+}
+
+func (u *userUsecaseImpl) Login(ctx context.Context, email, password string) (string, error) {
+	user, err := u.userRepository.FindByEmail(ctx, strings.TrimSpace(email))
+	if err != nil {
+		if errors.Is(err, entity.ErrUserNotFound) {
+			return "", entity.ErrInvalidCredentials
+		}
+
+		return "", err
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
+	if err != nil {
+		return "", entity.ErrInvalidCredentials
+	}
+
+	suspended, err := u.companyRepository.IsSuspended(ctx, user.CompanyID)
+	if err != nil {
+		return "", err
+	}
+	if suspended {
+		return "", entity.ErrCompanySuspended
+	}
+
+	return u.authService.GenerateToken(user)
+}
+
+func (u *userUsecaseImpl) Me(ctx context.Context, companyId string, userId string) (entity.User, error) {
+	user, err := u.userRepository.FindByID(ctx, companyId, userId)
+	if err != nil {
+		return entity.User{}, err
+	}
+
+	user.Password = ""
+	return user, nil
+}
+
+func (u *userUsecaseImpl) ListStaff(ctx context.Context, companyId string) (entity.Staff, error) {
+	users, err := u.userRepository.List(ctx, companyId)
+	if err != nil {
+		return entity.Staff{}, err
+	}
+
+	invitations, err := u.userRepository.ListInvitations(ctx, companyId)
+	if err != nil {
+		return entity.Staff{}, err
+	}
+
+	return entity.Staff{Users: users, Invitations: invitations}, nil
+}
+
+func (u *userUsecaseImpl) Invite(ctx context.Context, companyId string, invitedBy string, email string, role entity.UserRole) (entity.Invitation, error) {
+	email = entity.NormalizeEmail(email)
+	if email == "" {
+		return entity.Invitation{}, entity.ErrInvalidUser
+	}
+	if !role.IsStaff() {
+		return entity.Invitation{}, entity.ErrInvalidUserRole
+	}
+
+	_, err := u.userRepository.FindByEmail(ctx, email)
+	if err == nil {
+		return entity.Invitation{}, entity.ErrEmailAlreadyInUse
+	}
+	if !errors.Is(err, entity.ErrUserNotFound) {
+		return entity.Invitation{}, err
+	}
+
+	company, err := u.companyRepository.FindByID(ctx, companyId)
+	if err != nil {
+		return entity.Invitation{}, err
+	}
+
+	token, err := entity.GenerateInvitationToken()
+	if err != nil {
+		return entity.Invitation{}, fmt.Errorf("UserUsecase.Invite - failed to generate token: %w", err)
+	}
+
+	invitation, err := u.userRepository.SaveInvitation(ctx, entity.Invitation{
+		CompanyID: companyId,
+		Email:     email,
+		Role:      role,
+		InvitedBy: invitedBy,
+		ExpiresAt: time.Now().Add(entity.InvitationDuration),
+		TokenHash: entity.HashInvitationToken(token),
+	})
+	if err != nil {
+		return entity.Invitation{}, err
+	}
+
+	info := entity.InvitationEmailInfo{
+		CompanyName: company.Name,
+		Role:        role.Label(),
+		Token:       token,
+		ExpiresAt:   invitation.ExpiresAt.In(entity.CourtLocation).Format("02/01/2006 15:04"),
+	}
+	err = u.emailService.Send(ctx, userInvitationTemplateName, userInvitationEmailSubject, info, email)
+	if err != nil {
+		return entity.Invitation{}, fmt.Errorf("UserUsecase.Invite - failed to send invitation: %w", err)
+	}
+
+	return invitation, nil
+}
+
+func (u *userUsecaseImpl) RevokeInvitation(ctx context.Context, companyId string, id string) error {
+	return u.userRepository.DeleteInvitation(ctx, companyId, id)
+}
+
+func (u *userUsecaseImpl) AcceptInvitation(ctx context.Context, token string, name string, password string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || password == "" {
+		return "", entity.ErrInvalidUser
+	}
+
+	invitation, err := u.userRepository.FindInvitationByToken(ctx, entity.HashInvitationToken(token))
+	if err != nil {
+		return "", err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), 14)
+	if err != nil {
+		return "", fmt.Errorf("UserUsecase.AcceptInvitation - failed to hash password: %w", err)
+	}
+
+	user, err := u.userRepository.AcceptInvitation(ctx, invitation, entity.User{
+		CompanyID: invitation.CompanyID,
+		Name:      name,
+		Email:     invitation.Email,
+		Password:  string(hash),
+		Role:      invitation.Role,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return u.authService.GenerateToken(user)
+}
+
+// UpdateRole changes the role of the user. The last owner of the company
+// can't be demoted.
+func (u *userUsecaseImpl) UpdateRole(ctx context.Context, companyId string, id string, role entity.UserRole) (entity.User, error) {
+	if !role.IsStaff() {
+		return entity.User{}, entity.ErrInvalidUserRole
+	}
+
+	user, err := u.userRepository.FindByID(ctx, companyId, id)
+	if err != nil {
+		return entity.User{}, err
+	}
+
+	if user.Role == entity.RoleOwner && role != entity.RoleOwner {
+		if err := u.checkOtherOwners(ctx, companyId); err != nil {
+			return entity.User{}, err
+		}
+	}
+
+	if err := u.userRepository.UpdateRole(ctx, companyId, id, role); err != nil {
+		return entity.User{}, err
+	}
+
+	user.Role = role
+	user.Password = ""
+	return user, nil
+}
+
+// Remove deletes the user account. The last owner of the company can't be
+// removed.
+func (u *userUsecaseImpl) Remove(ctx context.Context, companyId string, id string) error {
+	user, err := u.userRepository.FindByID(ctx, companyId, id)
+	if err != nil {
+		return err
+	}
+
+	if user.Role == entity.RoleOwner {
+		if err := u.checkOtherOwners(ctx, companyId); err != nil {
+			return err
+		}
+	}
+
+	return u.userRepository.Delete(ctx, companyId, id)
+}
+
+func (u *userUsecaseImpl) CurrentRole(ctx context.Context, companyId string, userId string) (entity.UserRole, error) {
+	user, err := u.userRepository.FindByID(ctx, companyId, userId)
+	if err != nil {
+		return "", err
+	}
+
+	return user.Role, nil
+}
+
+func (u *userUsecaseImpl) checkOtherOwners(ctx context.Context, companyId string) error {
+	owners, err := u.userRepository.CountOwners(ctx, companyId)
+	if err != nil {
+		return err
+	}
+
+	if owners <= 1 {
+		return entity.ErrLastOwner
+	}
+
+	return nil
+}
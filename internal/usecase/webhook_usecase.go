@@ -0,0 +1,238 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/dinizgab/booking-mvp/internal/entity"
+	"github.com/dinizgab/booking-mvp/internal/ports"
+	"github.com/dinizgab/booking-mvp/internal/repository"
+)
+
+// webhookProcessingTimeout is how long an event may stay in processing before
+// another delivery is allowed to take it over.
+const webhookProcessingTimeout = 5 * time.Minute
+
+type (
+	WebhookUsecase interface {
+		ProcessChargeEvent(ctx context.Context, provider entity.PaymentProvider, eventType string, payload []byte) error
+		ProcessRefundEvent(ctx context.Context, provider entity.PaymentProvider, payload []byte) error
+		ListEvents(ctx context.Context, companyId string, status *entity.WebhookEventStatus) ([]entity.WebhookEvent, error)
+		GetEvent(ctx context.Context, companyId string, id string) (entity.WebhookEvent, error)
+		Replay(ctx context.Context, companyId string, id string) error
+		ListPlatformEvents(ctx context.Context, status *entity.WebhookEventStatus) ([]entity.WebhookEvent, error)
+		GetPlatformEvent(ctx context.Context, id string) (entity.WebhookEvent, error)
+		ReplayPlatformEvent(ctx context.Context, id string) error
+	}
+
+	webhookUsecaseImpl struct {
+		webhookRepository repository.WebhookEventRepository
+		paymentUsecase    PaymentUsecase
+		gateways          map[entity.PaymentProvider]ports.PaymentGateway
+	}
+)
+
+func NewWebhookUsecase(webhookRepository repository.WebhookEventRepository, paymentUsecase PaymentUsecase, gateways []ports.PaymentGateway) WebhookUsecase {
+	return &webhookUsecaseImpl{
+		webhookRepository: webhookRepository,
+		paymentUsecase:    paymentUsecase,
+		gateways:          gatewaysByProvider(gateways),
+	}
+}
+
+// ProcessChargeEvent records the charge event and runs it once. Deliveries of
+// an event that was already processed are acknowledged without side effects.
+func (u *webhookUsecaseImpl) ProcessChargeEvent(ctx context.Context, provider entity.PaymentProvider, eventType string, payload []byte) error {
+	charge, err := u.decodeCharge(provider, payload)
+	if err != nil {
+		return err
+	}
+
+	// Gateways send test deliveries without a charge when the webhook is set up.
+	if charge.CorrelationID == "" {
+		log.Printf("WebhookUsecase.ProcessChargeEvent - ignoring %s %s event without correlation id", provider, eventType)
+		return nil
+	}
+
+	event, err := u.webhookRepository.Record(ctx, entity.WebhookEvent{
+		Provider:  provider,
+		EventKey:  charge.CorrelationID,
+		EventType: eventType,
+		Payload:   payload,
+	}, charge.CorrelationID)
+	if err != nil {
+		return err
+	}
+
+	if event.Status == entity.WebhookEventProcessed {
+		log.Printf("WebhookUsecase.ProcessChargeEvent - duplicate %s event %s acknowledged", eventType, event.ID)
+		return nil
+	}
+
+	return u.process(ctx, event)
+}
+
+// ProcessRefundEvent records the refund event and runs it once. Refunds that
+// are still being processed by the provider are acknowledged and ignored.
+func (u *webhookUsecaseImpl) ProcessRefundEvent(ctx context.Context, provider entity.PaymentProvider, payload []byte) error {
+	refund, err := u.decodeRefund(provider, payload)
+	if err != nil {
+		return err
+	}
+
+	var eventType string
+	switch refund.Status {
+	case entity.RefundCompleted:
+		eventType = entity.WebhookRefundCompleted
+	case entity.RefundFailed:
+		eventType = entity.WebhookRefundFailed
+	default:
+		log.Printf("WebhookUsecase.ProcessRefundEvent - ignoring %s refund %s in status %s", provider, refund.CorrelationID, refund.Status)
+		return nil
+	}
+
+	if refund.CorrelationID == "" {
+		log.Printf("WebhookUsecase.ProcessRefundEvent - ignoring %s %s event without correlation id", provider, eventType)
+		return nil
+	}
+
+	event, err := u.webhookRepository.Record(ctx, entity.WebhookEvent{
+		Provider:  provider,
+		EventKey:  refund.CorrelationID,
+		EventType: eventType,
+		Payload:   payload,
+	}, refund.CorrelationID)
+	if err != nil {
+		return err
+	}
+
+	if event.Status == entity.WebhookEventProcessed {
+		log.Printf("WebhookUsecase.ProcessRefundEvent - duplicate %s event %s acknowledged", eventType, event.ID)
+		return nil
+	}
+
+	return u.process(ctx, event)
+}
+
+func (u *webhookUsecaseImpl) ListEvents(ctx context.Context, companyId string, status *entity.WebhookEventStatus) ([]entity.WebhookEvent, error) {
+	events, err := u.webhookRepository.List(ctx, companyId, status)
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (u *webhookUsecaseImpl) GetEvent(ctx context.Context, companyId string, id string) (entity.WebhookEvent, error) {
+	return u.webhookRepository.FindByID(ctx, companyId, id)
+}
+
+// Replay runs a failed or never processed event of the company again.
+func (u *webhookUsecaseImpl) Replay(ctx context.Context, companyId string, id string) error {
+	event, err := u.webhookRepository.FindByID(ctx, companyId, id)
+	if err != nil {
+		return err
+	}
+
+	return u.replay(ctx, event)
+}
+
+// ListPlatformEvents lists the events of every company, for operators.
+func (u *webhookUsecaseImpl) ListPlatformEvents(ctx context.Context, status *entity.WebhookEventStatus) ([]entity.WebhookEvent, error) {
+	events, err := u.webhookRepository.ListAll(ctx, status)
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (u *webhookUsecaseImpl) GetPlatformEvent(ctx context.Context, id string) (entity.WebhookEvent, error) {
+	return u.webhookRepository.FindAnyByID(ctx, id)
+}
+
+// ReplayPlatformEvent runs a failed or never processed event of any company
+// again, for operators.
+func (u *webhookUsecaseImpl) ReplayPlatformEvent(ctx context.Context, id string) error {
+	event, err := u.webhookRepository.FindAnyByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return u.replay(ctx, event)
+}
+
+func (u *webhookUsecaseImpl) replay(ctx context.Context, event entity.WebhookEvent) error {
+	if event.Status != entity.WebhookEventFailed && event.Status != entity.WebhookEventPending {
+		return entity.ErrWebhookEventNotReplayable
+	}
+
+	return u.process(ctx, event)
+}
+
+func (u *webhookUsecaseImpl) process(ctx context.Context, event entity.WebhookEvent) error {
+	claimed, err := u.webhookRepository.Claim(ctx, event.ID, webhookProcessingTimeout)
+	if err != nil {
+		return err
+	}
+
+	if !claimed {
+		log.Printf("WebhookUsecase.process - event %s is already processed or in progress", event.ID)
+		return nil
+	}
+
+	if err := u.dispatch(ctx, event); err != nil {
+		if finishErr := u.webhookRepository.Finish(ctx, event.ID, entity.WebhookEventFailed, err.Error()); finishErr != nil {
+			log.Printf("WebhookUsecase.process - could not mark event %s as failed: %v", event.ID, finishErr)
+		}
+		return err
+	}
+
+	return u.webhookRepository.Finish(ctx, event.ID, entity.WebhookEventProcessed, "")
+}
+
+func (u *webhookUsecaseImpl) dispatch(ctx context.Context, event entity.WebhookEvent) error {
+	switch event.EventType {
+	case entity.WebhookChargeCompleted, entity.WebhookChargeExpired:
+		charge, err := u.decodeCharge(event.Provider, event.Payload)
+		if err != nil {
+			return err
+		}
+
+		if event.EventType == entity.WebhookChargeCompleted {
+			return u.paymentUsecase.ConfirmPayment(ctx, charge)
+		}
+		return u.paymentUsecase.ExpirePayment(ctx, charge)
+	case entity.WebhookRefundCompleted, entity.WebhookRefundFailed:
+		refund, err := u.decodeRefund(event.Provider, event.Payload)
+		if err != nil {
+			return err
+		}
+
+		return u.paymentUsecase.UpdateRefundStatus(ctx, refund)
+	default:
+		return entity.ErrUnsupportedWebhookEventType
+	}
+}
+
+// decodeCharge reads the charge from the payload with the adapter of the
+// provider that sent it.
+func (u *webhookUsecaseImpl) decodeCharge(provider entity.PaymentProvider, payload []byte) (entity.Charge, error) {
+	gateway, ok := u.gateways[provider]
+	if !ok {
+		return entity.Charge{}, fmt.Errorf("WebhookUsecase.decodeCharge - %s: %w", provider, entity.ErrUnsupportedProvider)
+	}
+
+	return gateway.DecodeChargeEvent(payload)
+}
+
+func (u *webhookUsecaseImpl) decodeRefund(provider entity.PaymentProvider, payload []byte) (entity.Refund, error) {
+	gateway, ok := u.gateways[provider]
+	if !ok {
+		return entity.Refund{}, fmt.Errorf("WebhookUsecase.decodeRefund - %s: %w", provider, entity.ErrUnsupportedProvider)
+	}
+
+	return gateway.DecodeRefundEvent(payload)
+}
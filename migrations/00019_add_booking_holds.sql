@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table bookings add column hold_expires_at timestamptz;
+
+alter table bookings
+drop constraint no_overlapping_bookings;
+
+alter table bookings
+add constraint no_overlapping_bookings
+exclude using gist (
+    court_id with =,
+    tstzrange(start_time, end_time) with &&
+) where (status <> 'cancelled');
+
+create index bookings_pending_hold_idx on bookings (hold_expires_at) where status = 'pending';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index if exists bookings_pending_hold_idx;
+
+alter table bookings
+drop constraint no_overlapping_bookings;
+
+alter table bookings
+add constraint no_overlapping_bookings
+exclude using gist (
+    court_id with =,
+    tstzrange(start_time, end_time) with &&
+);
+
+alter table bookings drop column hold_expires_at;
+-- +goose StatementEnd
@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+create type booking_series_frequency as enum ('weekly', 'biweekly');
+create type booking_series_payment_mode as enum ('per_occurrence', 'upfront');
+create type booking_series_status as enum ('active', 'cancelled');
+
+create table if not exists booking_series (
+    id uuid primary key default gen_random_uuid(),
+    company_id uuid not null references companies(id) on delete cascade,
+    court_id uuid not null references courts(id) on delete cascade,
+    guest_name varchar(100) not null,
+    guest_phone varchar(20) not null,
+    guest_email varchar(100) not null,
+    frequency booking_series_frequency not null,
+    start_time timestamptz not null,
+    end_time timestamptz not null,
+    until timestamptz,
+    occurrences_count integer,
+    payment_mode booking_series_payment_mode not null default 'per_occurrence',
+    status booking_series_status not null default 'active',
+    created_at timestamptz not null default now(),
+    constraint chk_series_time_range check (end_time > start_time),
+    constraint chk_series_end check (until is not null or occurrences_count is not null)
+);
+
+alter table bookings
+    add column series_id uuid references booking_series(id) on delete set null;
+
+create index bookings_series_idx on bookings (series_id) where series_id is not null;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index if exists bookings_series_idx;
+alter table bookings drop column series_id;
+drop table if exists booking_series;
+drop type if exists booking_series_status;
+drop type if exists booking_series_payment_mode;
+drop type if exists booking_series_frequency;
+-- +goose StatementEnd
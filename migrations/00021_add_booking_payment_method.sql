@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+create type booking_payment_method as enum ('pix', 'cash', 'card', 'comp');
+
+alter table bookings
+    add column payment_method booking_payment_method not null default 'pix',
+    add column created_by uuid;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table bookings
+    drop column payment_method,
+    drop column created_by;
+
+drop type if exists booking_payment_method;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+create table if not exists court_blackouts (
+    id uuid primary key default gen_random_uuid(),
+    company_id uuid not null references companies(id) on delete cascade,
+    court_id uuid references courts(id) on delete cascade, -- null applies to every court of the company
+    start_time timestamptz not null,
+    end_time timestamptz not null,
+    all_day boolean not null default false,
+    reason text not null default '',
+    created_at timestamptz not null default now(),
+    constraint chk_blackout_time_range check (end_time > start_time)
+);
+
+create index court_blackouts_court_idx on court_blackouts (court_id);
+create index court_blackouts_company_idx on court_blackouts (company_id) where court_id is null;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists court_blackouts;
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table courts
+    add column min_duration_minutes integer not null default 0,
+    add column max_duration_minutes integer not null default 0,
+    add column slot_granularity_minutes integer not null default 0,
+    add column lead_time_minutes integer not null default 0,
+    add column max_advance_days integer not null default 0,
+    add constraint chk_court_booking_policy check (
+        min_duration_minutes >= 0
+        and max_duration_minutes >= 0
+        and slot_granularity_minutes >= 0
+        and lead_time_minutes >= 0
+        and max_advance_days >= 0
+        and (max_duration_minutes = 0 or max_duration_minutes >= min_duration_minutes)
+    );
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table courts
+    drop constraint if exists chk_court_booking_policy,
+    drop column if exists min_duration_minutes,
+    drop column if exists max_duration_minutes,
+    drop column if exists slot_granularity_minutes,
+    drop column if exists lead_time_minutes,
+    drop column if exists max_advance_days;
+-- +goose StatementEnd
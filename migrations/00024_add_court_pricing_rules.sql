@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+create table if not exists court_pricing_rules (
+    id uuid primary key default gen_random_uuid(),
+    court_id uuid not null references courts(id) on delete cascade,
+    weekday smallint, -- null applies the rule every day
+    start_time time not null,
+    end_time time not null,
+    hourly_price bigint not null,
+    priority integer not null default 0,
+    created_at timestamptz not null default now(),
+    constraint chk_pricing_rule_weekday check (weekday is null or weekday between 0 and 6),
+    constraint chk_pricing_rule_price check (hourly_price >= 0)
+);
+
+create index court_pricing_rules_court_idx on court_pricing_rules (court_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists court_pricing_rules;
+-- +goose StatementEnd
@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+create type webhook_event_status as enum (
+    'pending',
+    'processing',
+    'processed',
+    'failed'
+);
+
+create table if not exists webhook_events (
+    id uuid primary key default gen_random_uuid(),
+    company_id uuid references companies(id) on delete set null,
+    event_key text not null, -- gateway event id, or the charge correlation id when there is none
+    event_type text not null,
+    payload jsonb not null,
+    status webhook_event_status not null default 'pending',
+    attempts integer not null default 0,
+    last_error text not null default '',
+    received_at timestamptz not null default now(),
+    processed_at timestamptz,
+    updated_at timestamptz not null default now(),
+    constraint webhook_events_key_type_unique unique (event_key, event_type)
+);
+
+create index webhook_events_company_idx on webhook_events (company_id, received_at desc);
+create index webhook_events_failed_idx on webhook_events (received_at desc) where status = 'failed';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists webhook_events;
+drop type if exists webhook_event_status;
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+create type outbox_status as enum (
+    'pending',
+    'sent',
+    'dead'
+);
+
+create table if not exists outbox_messages (
+    id uuid primary key default gen_random_uuid(),
+    booking_id uuid not null references bookings(id) on delete cascade,
+    template text not null,
+    subject text not null,
+    recipient text not null,
+    payload jsonb not null,
+    status outbox_status not null default 'pending',
+    attempts integer not null default 0,
+    last_error text not null default '',
+    next_attempt_at timestamptz not null default now(),
+    sent_at timestamptz,
+    created_at timestamptz not null default now()
+);
+
+create index outbox_messages_due_idx on outbox_messages (next_attempt_at) where status = 'pending';
+create index outbox_messages_booking_idx on outbox_messages (booking_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists outbox_messages;
+drop type if exists outbox_status;
+-- +goose StatementEnd
@@ -0,0 +1,57 @@
+-- +goose Up
+-- +goose StatementBegin
+create type payment_provider as enum (
+    'openpix',
+    'card'
+);
+
+alter table companies
+    add column payment_provider payment_provider not null default 'openpix';
+
+create table if not exists payment_accounts (
+    id uuid primary key default gen_random_uuid(),
+    company_id uuid not null references companies(id) on delete cascade,
+    provider payment_provider not null,
+    account_id text not null, -- pix key for openpix, connected account id for card providers
+    created_at timestamptz not null default now(),
+    constraint payment_accounts_company_provider_unique unique (company_id, provider)
+);
+
+insert into payment_accounts (company_id, provider, account_id)
+select company_id, 'openpix', pix_key
+from openpix_subaccounts
+on conflict do nothing;
+
+alter table payments
+    add column provider payment_provider not null default 'openpix',
+    alter column payment_link_id type text using payment_link_id::text;
+
+alter table webhook_events
+    add column provider payment_provider not null default 'openpix',
+    drop constraint webhook_events_key_type_unique,
+    add constraint webhook_events_provider_key_type_unique unique (provider, event_key, event_type);
+
+update webhook_events set event_type = replace(event_type, 'OPENPIX:', '');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+update webhook_events set event_type = 'OPENPIX:' || event_type where provider = 'openpix';
+delete from webhook_events where provider <> 'openpix';
+
+alter table webhook_events
+    drop constraint webhook_events_provider_key_type_unique,
+    add constraint webhook_events_key_type_unique unique (event_key, event_type),
+    drop column provider;
+
+delete from payments where provider <> 'openpix';
+alter table payments
+    drop column provider,
+    alter column payment_link_id type uuid using payment_link_id::uuid;
+
+drop table if exists payment_accounts;
+
+alter table companies drop column payment_provider;
+
+drop type if exists payment_provider;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+create table if not exists cancellation_policies (
+    company_id uuid primary key references companies(id) on delete cascade,
+    tiers jsonb not null default '[]',
+    refund_platform_fee boolean not null default false,
+    updated_at timestamptz not null default now()
+);
+
+alter table payments
+    add column refunded_value bigint;
+
+update payments set refunded_value = value_total where status = 'refunded';
+
+-- The cancel deadline now comes from the company cancellation policy.
+alter table bookings
+    drop column cancel_token_expires_at;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table bookings
+    add column cancel_token_expires_at timestamptz not null GENERATED ALWAYS AS ((start_time AT TIME ZONE 'UTC') - INTERVAL '3 hours') STORED;
+
+alter table payments
+    drop column refunded_value;
+
+drop table if exists cancellation_policies;
+-- +goose StatementEnd
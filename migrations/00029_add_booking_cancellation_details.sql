@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table bookings
+    add column cancelled_at timestamptz,
+    add column cancelled_by text check (cancelled_by in ('guest', 'company')),
+    add column cancellation_reason text;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table bookings
+    drop column cancelled_at,
+    drop column cancelled_by,
+    drop column cancellation_reason;
+-- +goose StatementEnd
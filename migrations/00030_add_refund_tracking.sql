@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+alter type payment_status add value if not exists 'refund_failed';
+
+alter table payments
+    add column refund_correlation_id text,
+    add column refund_failure_reason text;
+
+create unique index if not exists payments_refund_correlation_id_idx
+    on payments (refund_correlation_id)
+    where refund_correlation_id is not null;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index if exists payments_refund_correlation_id_idx;
+
+alter table payments
+    drop column refund_correlation_id,
+    drop column refund_failure_reason;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table payments
+    add column value_received bigint;
+
+create index if not exists payments_mismatch_idx on payments (company_id) where status = 'mismatch';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index if exists payments_mismatch_idx;
+
+alter table payments
+    drop column value_received;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table payments
+    add column value_delta bigint generated always as (value_received - value_total) stored,
+    add column mismatch_resolution text,
+    add column mismatch_resolved_at timestamptz;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table payments
+    drop column value_delta,
+    drop column mismatch_resolution,
+    drop column mismatch_resolved_at;
+-- +goose StatementEnd
@@ -0,0 +1,52 @@
+-- +goose Up
+-- +goose StatementBegin
+create type withdrawal_status as enum (
+    'pending',
+    'completed',
+    'failed'
+);
+
+-- Withdrawals made before tracking were only stored once OpenPix accepted
+-- them, so they are considered completed.
+alter table withdrawals
+    alter column correlation_id type text using correlation_id::text,
+    add column provider payment_provider not null default 'openpix',
+    add column status withdrawal_status not null default 'completed',
+    add column failure_reason text,
+    add column trigger text not null default 'manual',
+    add column completed_at timestamptz,
+    add column updated_at timestamptz not null default now();
+
+alter table withdrawals
+    alter column status set default 'pending';
+
+create index if not exists withdrawals_company_idx on withdrawals (company_id, created_at desc);
+create index if not exists withdrawals_pending_idx on withdrawals (created_at) where status = 'pending';
+
+create table if not exists payout_schedules (
+    company_id uuid primary key references companies(id) on delete cascade,
+    enabled boolean not null default false,
+    weekday smallint not null default 1 check (weekday between 0 and 6),
+    last_payout_at timestamptz,
+    updated_at timestamptz not null default now()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists payout_schedules;
+
+drop index if exists withdrawals_pending_idx;
+drop index if exists withdrawals_company_idx;
+
+alter table withdrawals
+    drop column provider,
+    drop column status,
+    drop column failure_reason,
+    drop column trigger,
+    drop column completed_at,
+    drop column updated_at,
+    alter column correlation_id type uuid using correlation_id::uuid;
+
+drop type if exists withdrawal_status;
+-- +goose StatementEnd
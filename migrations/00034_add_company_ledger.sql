@@ -0,0 +1,47 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table payments
+    add column value_gateway_fee bigint not null default 0;
+
+-- company_ledger derives the double-entry movements of every company from
+-- the payments and withdrawals tables. Paid charges credit the company with
+-- the whole value and debit the platform commission and gateway fee from it.
+create or replace view company_ledger as
+select p.id || ':charge' as id, p.company_id, p.paid_at as occurred_at, 'charge' as entry_type,
+       p.id::text as reference_id, p.booking_id, 'guest' as debit_account, 'company' as credit_account,
+       coalesce(p.value_received, p.value_total) as amount
+from payments p
+where p.paid_at is not null
+union all
+select p.id || ':commission', p.company_id, p.paid_at, 'commission',
+       p.id::text, p.booking_id, 'company', 'platform', p.value_commission
+from payments p
+where p.paid_at is not null
+    and p.value_commission > 0
+union all
+select p.id || ':gateway_fee', p.company_id, p.paid_at, 'gateway_fee',
+       p.id::text, p.booking_id, 'company', 'gateway', p.value_gateway_fee
+from payments p
+where p.paid_at is not null
+    and p.value_gateway_fee > 0
+union all
+select p.id || ':refund', p.company_id, p.refunded_at at time zone 'UTC', 'refund',
+       p.id::text, p.booking_id, 'company', 'guest', p.refunded_value
+from payments p
+where p.status = 'refunded'
+    and p.refunded_at is not null
+    and p.refunded_value > 0
+union all
+select w.id || ':withdrawal', w.company_id, w.created_at, 'withdrawal',
+       w.id::text, null, 'company', 'bank', w.value
+from withdrawals w
+where w.status <> 'failed';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop view if exists company_ledger;
+
+alter table payments
+    drop column value_gateway_fee;
+-- +goose StatementEnd
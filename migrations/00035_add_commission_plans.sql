@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+create table if not exists commission_plans (
+    id uuid primary key default gen_random_uuid(),
+    name text not null unique,
+    percent_bps integer not null check (percent_bps between 0 and 10000),
+    fixed_fee bigint not null default 0 check (fixed_fee >= 0),
+    paid_by text not null default 'guest' check (paid_by in ('guest', 'company')),
+    is_default boolean not null default false,
+    created_at timestamptz not null default now(),
+    updated_at timestamptz not null default now()
+);
+
+create unique index if not exists commission_plans_default_idx
+    on commission_plans (is_default)
+    where is_default;
+
+-- The default plan keeps the fee charged so far: 5% of the court price plus
+-- R$0.85, paid by the guest.
+insert into commission_plans (name, percent_bps, fixed_fee, paid_by, is_default)
+values ('Padrão', 500, 85, 'guest', true)
+on conflict do nothing;
+
+alter table companies
+    add column commission_plan_id uuid references commission_plans(id) on delete set null;
+
+alter table payments
+    alter column value_commission drop default,
+    add column commission_paid_by text not null default 'guest';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table payments
+    drop column commission_paid_by,
+    alter column value_commission set default 300;
+
+alter table companies
+    drop column commission_plan_id;
+
+drop table if exists commission_plans;
+-- +goose StatementEnd
@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+create table if not exists operators (
+    id uuid primary key default gen_random_uuid(),
+    name text not null,
+    email text not null unique,
+    password_hash text not null,
+    created_at timestamptz not null default now()
+);
+
+-- operator_audit_log records every request made by an operator, including
+-- the admin requests made while impersonating a company.
+create table if not exists operator_audit_log (
+    id uuid primary key default gen_random_uuid(),
+    operator_id uuid not null references operators(id),
+    company_id uuid references companies(id) on delete set null,
+    action text not null,
+    target_id text,
+    status_code integer not null,
+    details jsonb,
+    created_at timestamptz not null default now()
+);
+
+create index if not exists operator_audit_log_created_at_idx
+    on operator_audit_log (created_at desc);
+
+alter table companies
+    add column suspended_at timestamptz,
+    add column suspension_reason text;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table companies
+    drop column suspension_reason,
+    drop column suspended_at;
+
+drop table if exists operator_audit_log;
+drop table if exists operators;
+-- +goose StatementEnd
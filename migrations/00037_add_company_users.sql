@@ -0,0 +1,125 @@
+-- +goose Up
+-- +goose StatementBegin
+-- users from migration 00001 become the staff accounts of each company. Every
+-- company gets an owner account with its current login. Users without a
+-- company, or company logins already taken by a user of another company, must
+-- be sorted out by hand first: the migration stops and lists them.
+do $$
+declare
+    orphans text;
+    conflicts text;
+begin
+    select string_agg(u.email, ', ')
+    into orphans
+    from users u
+    where u.company_id is null;
+
+    if orphans is not null then
+        raise exception 'users without a company: %', orphans;
+    end if;
+
+    select string_agg(c.email || ' (company ' || c.id || ')', ', ')
+    into conflicts
+    from companies c
+    join users u on u.email = c.email
+    where c.password_hash is not null
+        and u.company_id <> c.id;
+
+    if conflicts is not null then
+        raise exception 'company logins used by users of another company: %', conflicts;
+    end if;
+end
+$$;
+
+alter table users
+    add column name text not null default '',
+    add column role text not null default 'owner' check (role in ('owner', 'manager', 'front_desk')),
+    add column created_at timestamptz not null default now(),
+    alter column company_id set not null,
+    drop constraint if exists users_company_id_fkey,
+    add constraint users_company_id_fkey foreign key (company_id) references companies(id) on delete cascade;
+
+insert into users (email, password_hash, company_id, name, role)
+select c.email, c.password_hash, c.id, c.name, 'owner'
+from companies c
+where c.password_hash is not null
+    and not exists (select 1 from users u where u.email = c.email);
+
+create index if not exists users_company_idx on users (company_id);
+
+-- Manual bookings recorded the company as their creator.
+update bookings b
+set created_by = u.id
+from companies c
+join users u on u.company_id = c.id and u.email = c.email
+where b.created_by = c.id;
+
+-- The login moved to users; the company password is no longer written.
+alter table companies
+    alter column password_hash drop not null;
+
+create table if not exists user_invitations (
+    id uuid primary key default gen_random_uuid(),
+    company_id uuid not null references companies(id) on delete cascade,
+    email text not null,
+    role text not null check (role in ('owner', 'manager', 'front_desk')),
+    token_hash text not null unique,
+    invited_by uuid references users(id) on delete set null,
+    expires_at timestamptz not null,
+    accepted_at timestamptz,
+    created_at timestamptz not null default now()
+);
+
+create unique index if not exists user_invitations_pending_idx
+    on user_invitations (company_id, email)
+    where accepted_at is null;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists user_invitations;
+
+update bookings b
+set created_by = u.company_id
+from users u
+where b.created_by = u.id;
+
+-- Companies created after staff accounts have no password of their own. They
+-- get the password of the owner with the company email, or of their oldest
+-- owner when that one changed; companies left without users get a hash no
+-- password matches, so the column can be required again.
+update companies c
+set password_hash = u.password_hash
+from users u
+where u.company_id = c.id
+    and u.email = c.email
+    and c.password_hash is null;
+
+update companies c
+set password_hash = (
+    select u.password_hash
+    from users u
+    where u.company_id = c.id
+        and u.role = 'owner'
+    order by u.created_at
+    limit 1
+)
+where c.password_hash is null;
+
+update companies
+set password_hash = '!'
+where password_hash is null;
+
+alter table companies
+    alter column password_hash set not null;
+
+drop index if exists users_company_idx;
+
+alter table users
+    drop constraint users_company_id_fkey,
+    add constraint users_company_id_fkey foreign key (company_id) references companies(id),
+    alter column company_id drop not null,
+    drop column created_at,
+    drop column role,
+    drop column name;
+-- +goose StatementEnd